@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// AdminHandler handles account management and content removal HTTP requests for admins
+type AdminHandler struct {
+	BaseHandler
+	adminService *services.AdminService
+	validator    *utils.Validator
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(adminService *services.AdminService, validator *utils.Validator) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		validator:    validator,
+	}
+}
+
+// setRoleRequest is the body for changing a user's role
+type setRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// suspendRequest is the body for suspending or restricting a user
+type suspendRequest struct {
+	DurationHours int    `json:"duration_hours" validate:"required,min=1,max=8760"`
+	Reason        string `json:"reason" validate:"required,oneof=spam harassment hate_speech violence nudity misinformation other"`
+	Note          string `json:"note" validate:"max=1000"`
+}
+
+// GetUser handles looking up any user's account by ID
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	// Parse user ID from path
+	userID, ok := parseAdminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get user
+	user, err := h.adminService.GetUser(userID)
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return user
+	utils.SendJSONResponse(w, http.StatusOK, user)
+}
+
+// FindUser handles looking up any user's account by ?email= or ?username=
+func (h *AdminHandler) FindUser(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	email := r.URL.Query().Get("email")
+	username := r.URL.Query().Get("username")
+	if email == "" && username == "" {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "email or username is required"})
+		return
+	}
+
+	// Find user
+	user, err := h.adminService.FindUser(email, username)
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return user
+	utils.SendJSONResponse(w, http.StatusOK, user)
+}
+
+// SetRole handles changing a user's role
+func (h *AdminHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	adminID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse user ID from path
+	userID, ok := parseAdminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req setRoleRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Set role
+	user, err := h.adminService.SetRole(adminID, userID, req.Role)
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return updated user
+	utils.SendJSONResponse(w, http.StatusOK, user)
+}
+
+// SuspendUser handles suspending a user for a number of hours, blocking their logins and tokens
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.suspend(w, r, models.SuspensionKindSuspension)
+}
+
+// RestrictUser handles restricting a user for a number of hours, hiding their content from everyone else
+func (h *AdminHandler) RestrictUser(w http.ResponseWriter, r *http.Request) {
+	h.suspend(w, r, models.SuspensionKindRestriction)
+}
+
+// LiftSuspension handles ending a user's suspension early, with an optional ?note=
+func (h *AdminHandler) LiftSuspension(w http.ResponseWriter, r *http.Request) {
+	h.lift(w, r, models.SuspensionKindSuspension)
+}
+
+// LiftRestriction handles ending a user's restriction early, with an optional ?note=
+func (h *AdminHandler) LiftRestriction(w http.ResponseWriter, r *http.Request) {
+	h.lift(w, r, models.SuspensionKindRestriction)
+}
+
+// GetSuspensionHistory handles getting every suspension and restriction of a user
+func (h *AdminHandler) GetSuspensionHistory(w http.ResponseWriter, r *http.Request) {
+	// Parse user ID from path
+	userID, ok := parseAdminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get suspension history
+	suspensions, err := h.adminService.GetSuspensionHistory(userID)
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return suspension history
+	utils.SendJSONResponse(w, http.StatusOK, suspensions)
+}
+
+// suspend suspends or restricts the user in the path depending on kind
+func (h *AdminHandler) suspend(w http.ResponseWriter, r *http.Request, kind string) {
+	// Get user ID from context
+	adminID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse user ID from path
+	userID, ok := parseAdminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req suspendRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Suspend user
+	duration := time.Duration(req.DurationHours) * time.Hour
+	suspension, err := h.adminService.SuspendUser(adminID, userID, kind, req.Reason, req.Note, duration)
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return suspension
+	utils.SendJSONResponse(w, http.StatusCreated, suspension)
+}
+
+// lift ends the suspension or restriction of the user in the path depending on kind
+func (h *AdminHandler) lift(w http.ResponseWriter, r *http.Request, kind string) {
+	// Get user ID from context
+	adminID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse user ID from path
+	userID, ok := parseAdminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// Lift suspension
+	action, err := h.adminService.LiftSuspension(adminID, userID, kind, r.URL.Query().Get("note"))
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return recorded action
+	utils.SendJSONResponse(w, http.StatusOK, action)
+}
+
+// RemovePost handles deleting any post, with an optional ?note=
+func (h *AdminHandler) RemovePost(w http.ResponseWriter, r *http.Request) {
+	h.removeContent(w, r, "postId", "Invalid post ID", h.adminService.RemovePost)
+}
+
+// RemoveComment handles deleting any comment, with an optional ?note=
+func (h *AdminHandler) RemoveComment(w http.ResponseWriter, r *http.Request) {
+	h.removeContent(w, r, "commentId", "Invalid comment ID", h.adminService.RemoveComment)
+}
+
+// removeContent parses the content ID from the path and removes the content with remove
+func (h *AdminHandler) removeContent(w http.ResponseWriter, r *http.Request, param, invalidMessage string,
+	remove func(adminID, resourceID int, note string) (*models.ModerationAction, error)) {
+	// Get user ID from context
+	adminID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse content ID from path
+	resourceID, err := strconv.Atoi(chi.URLParam(r, param))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": invalidMessage})
+		return
+	}
+
+	// Remove content
+	action, err := remove(adminID, resourceID, r.URL.Query().Get("note"))
+	if err != nil {
+		sendAdminError(w, err)
+		return
+	}
+
+	// Return recorded action
+	utils.SendJSONResponse(w, http.StatusOK, action)
+}
+
+// parseAdminUserID parses the user ID from the path
+func parseAdminUserID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// sendAdminError sends the response for an error returned by the admin service
+func sendAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), isResourceNotFound(err):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Not found"})
+	case errors.Is(err, services.ErrNotSuspended):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleTooHigh):
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrCannotChangeOwnRole),
+		errors.Is(err, services.ErrInvalidModerationAction), errors.Is(err, services.ErrInvalidSuspension):
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
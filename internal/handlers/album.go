@@ -65,6 +65,13 @@ func (h *AlbumHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
 
 // GetUserAlbums handles getting a user's albums
 func (h *AlbumHandler) GetUserAlbums(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse user ID from path
 	userIDStr := chi.URLParam(r, "userId")
 	userID, err := strconv.Atoi(userIDStr)
@@ -74,7 +81,7 @@ func (h *AlbumHandler) GetUserAlbums(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user's albums
-	albums, _, err := h.albumService.GetAlbumsWithPhotosByUserID(userID)
+	albums, _, err := h.albumService.GetAlbumsWithPhotosByUserID(userID, viewerID)
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
@@ -86,6 +93,13 @@ func (h *AlbumHandler) GetUserAlbums(w http.ResponseWriter, r *http.Request) {
 
 // GetAlbum handles getting an album
 func (h *AlbumHandler) GetAlbum(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse album ID from path
 	albumIDStr := chi.URLParam(r, "albumId")
 	albumID, err := strconv.Atoi(albumIDStr)
@@ -95,7 +109,7 @@ func (h *AlbumHandler) GetAlbum(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get album
-	album, err := h.albumService.GetAlbumByID(albumID)
+	album, err := h.albumService.GetAlbumByID(albumID, viewerID)
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Album not found"})
 		return
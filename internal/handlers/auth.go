@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -29,6 +30,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name      string    `json:"name" validate:"required"`
 		Email     string    `json:"email" validate:"required,email"`
+		Username  string    `json:"username" validate:"omitempty,username"`
 		Password  string    `json:"password" validate:"required,min=8"`
 		BirthDate time.Time `json:"birth_date" validate:"required"`
 	}
@@ -45,7 +47,11 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Register user
-	user, err := h.authService.Register(req.Name, req.Email, req.Password, req.BirthDate)
+	user, err := h.authService.Register(req.Name, req.Email, req.Username, req.Password, req.BirthDate)
+	if errors.Is(err, services.ErrUsernameTaken) {
+		utils.SendJSONResponse(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -76,6 +82,10 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Authenticate user
 	accessToken, refreshToken, err := h.authService.Login(req.Email, req.Password)
+	if errors.Is(err, services.ErrAccountSuspended) {
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
 		return
@@ -103,6 +113,10 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 
 	// Refresh token
 	accessToken, err := h.authService.RefreshToken(req.RefreshToken)
+	if errors.Is(err, services.ErrAccountSuspended) {
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
 		return
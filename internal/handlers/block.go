@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// BlockHandler handles user blocking HTTP requests
+type BlockHandler struct {
+	BaseHandler
+	blockService *services.BlockService
+	validator    *utils.Validator
+}
+
+// NewBlockHandler creates a new BlockHandler
+func NewBlockHandler(blockService *services.BlockService, validator *utils.Validator) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+		validator:    validator,
+	}
+}
+
+// BlockUser handles blocking a user
+func (h *BlockHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse blocked user ID from path
+	blockedIDStr := chi.URLParam(r, "userId")
+	blockedID, err := strconv.Atoi(blockedIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	// Block user
+	if err := h.blockService.BlockUser(userID, blockedID); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "User blocked"})
+}
+
+// UnblockUser handles unblocking a user
+func (h *BlockHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse blocked user ID from path
+	blockedIDStr := chi.URLParam(r, "userId")
+	blockedID, err := strconv.Atoi(blockedIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	// Unblock user
+	if err := h.blockService.UnblockUser(userID, blockedID); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "User unblocked"})
+}
+
+// GetMyBlockedUsers handles getting the users blocked by the current user
+func (h *BlockHandler) GetMyBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Get blocked users
+	blocked, err := h.blockService.GetBlockedUsers(userID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return blocked users
+	utils.SendJSONResponse(w, http.StatusOK, blocked)
+}
@@ -2,6 +2,8 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -11,6 +13,9 @@ import (
 	"github.com/gocli/social_api/internal/utils"
 )
 
+// maxRepliesLimit caps the page size of comment replies
+const maxRepliesLimit = 100
+
 // CommentHandler handles comment-related HTTP requests
 type CommentHandler struct {
 	BaseHandler
@@ -46,7 +51,8 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req struct {
-		Content string `json:"content" validate:"required"`
+		Content         string `json:"content" validate:"required"`
+		ParentCommentID int    `json:"parent_comment_id" validate:"omitempty,min=1"`
 	}
 
 	if err := h.DecodeJSONBody(w, r, &req); err != nil {
@@ -61,8 +67,20 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create comment
-	comment, err := h.commentService.CreateComment(userID, resourceType, resourceID, req.Content)
+	comment, err := h.commentService.CreateComment(userID, resourceType, resourceID, req.ParentCommentID, req.Content)
 	if err != nil {
+		if isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Parent comment not found"})
+			return
+		}
+		if errors.Is(err, services.ErrCommentsNotAllowed) {
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
 		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
@@ -73,6 +91,13 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 // GetCommentsForResource handles getting comments for a resource
 func (h *CommentHandler) GetCommentsForResource(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse resource type and ID from path
 	resourceType := chi.URLParam(r, "resourceType")
 	resourceIDStr := chi.URLParam(r, "resourceId")
@@ -83,8 +108,12 @@ func (h *CommentHandler) GetCommentsForResource(w http.ResponseWriter, r *http.R
 	}
 
 	// Get comments for the resource
-	comments, err := h.commentService.GetCommentsForResource(resourceType, resourceID)
+	comments, err := h.commentService.GetCommentsForResource(resourceType, resourceID, userID)
 	if err != nil {
+		if isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+			return
+		}
 		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -93,6 +122,132 @@ func (h *CommentHandler) GetCommentsForResource(w http.ResponseWriter, r *http.R
 	utils.SendJSONResponse(w, http.StatusOK, comments)
 }
 
+// GetReplies handles getting a page of replies to a comment
+func (h *CommentHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse comment ID from path
+	commentIDStr := chi.URLParam(r, "commentId")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxRepliesLimit {
+		limit = maxRepliesLimit
+	}
+	offset := (page - 1) * limit
+
+	// Get replies
+	replies, err := h.commentService.GetReplies(commentID, userID, limit, offset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Comment not found"})
+			return
+		}
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return replies
+	utils.SendJSONResponse(w, http.StatusOK, replies)
+}
+
+// UpdateComment handles editing a comment
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse comment ID from path
+	commentIDStr := chi.URLParam(r, "commentId")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Content string `json:"content" validate:"required"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Update comment
+	comment, err := h.commentService.UpdateComment(commentID, userID, req.Content)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Comment not found"})
+			return
+		}
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return comment
+	utils.SendJSONResponse(w, http.StatusOK, comment)
+}
+
+// GetCommentRevisions handles getting the edit history of a comment
+func (h *CommentHandler) GetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse comment ID from path
+	commentIDStr := chi.URLParam(r, "commentId")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Get revisions
+	revisions, err := h.commentService.GetCommentRevisions(commentID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Comment not found"})
+		case errors.Is(err, services.ErrHistoryNotAllowed):
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		default:
+			utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return
+	}
+
+	// Return revisions
+	utils.SendJSONResponse(w, http.StatusOK, revisions)
+}
+
 // DeleteComment handles deleting a comment
 func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
@@ -119,3 +274,62 @@ func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	// Return success response
 	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Comment deleted"})
 }
+
+// HideComment handles hiding a comment on the user's content
+func (h *CommentHandler) HideComment(w http.ResponseWriter, r *http.Request) {
+	h.moderateComment(w, r, "Comment hidden", func(commentID, userID int) error {
+		return h.commentService.HideComment(commentID, userID, true)
+	})
+}
+
+// UnhideComment handles unhiding a comment on the user's content
+func (h *CommentHandler) UnhideComment(w http.ResponseWriter, r *http.Request) {
+	h.moderateComment(w, r, "Comment unhidden", func(commentID, userID int) error {
+		return h.commentService.HideComment(commentID, userID, false)
+	})
+}
+
+// PinComment handles pinning a comment on the user's content
+func (h *CommentHandler) PinComment(w http.ResponseWriter, r *http.Request) {
+	h.moderateComment(w, r, "Comment pinned", h.commentService.PinComment)
+}
+
+// UnpinComment handles unpinning a comment on the user's content
+func (h *CommentHandler) UnpinComment(w http.ResponseWriter, r *http.Request) {
+	h.moderateComment(w, r, "Comment unpinned", h.commentService.UnpinComment)
+}
+
+// moderateComment runs a moderation action on the comment in the path and sends the response
+func (h *CommentHandler) moderateComment(w http.ResponseWriter, r *http.Request, message string, action func(commentID, userID int) error) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse comment ID from path
+	commentIDStr := chi.URLParam(r, "commentId")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Apply the moderation action
+	if err := action(commentID, userID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Comment not found"})
+		case errors.Is(err, services.ErrNotModerator), errors.Is(err, services.ErrContentModerated),
+			errors.Is(err, services.ErrContentHeld):
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		default:
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": message})
+}
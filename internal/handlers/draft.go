@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// DraftHandler handles HTTP requests for drafts and scheduled posts
+type DraftHandler struct {
+	BaseHandler
+	draftService *services.DraftService
+	validator    *utils.Validator
+}
+
+// NewDraftHandler creates a new DraftHandler
+func NewDraftHandler(draftService *services.DraftService, validator *utils.Validator) *DraftHandler {
+	return &DraftHandler{
+		draftService: draftService,
+		validator:    validator,
+	}
+}
+
+// CreateDraft handles saving a draft, which is scheduled if it has a publish_at time
+func (h *DraftHandler) CreateDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse and validate request body; it is a post body with an optional publish_at time
+	var req struct {
+		postRequest
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	isMultipart, ok := decodePostBody(&h.BaseHandler, h.validator, w, r, &req, &req.postRequest)
+	if !ok {
+		return
+	}
+	if isMultipart && r.FormValue("publish_at") != "" {
+		publishAt, err := time.Parse(time.RFC3339, r.FormValue("publish_at"))
+		if err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid publish_at time"})
+			return
+		}
+		req.PublishAt = &publishAt
+	}
+
+	// Save the attached images, if any
+	attachments, ok := postAttachments(w, r, isMultipart, &req.postRequest)
+	if !ok {
+		return
+	}
+
+	// Create draft
+	draft, err := h.draftService.CreateDraft(userID, req.Content, req.Privacy, attachments, req.PublishAt)
+	if err != nil {
+		removeUploadedFiles(attachments.ImageURLs)
+		sendDraftError(w, err)
+		return
+	}
+
+	// Return draft
+	utils.SendJSONResponse(w, http.StatusCreated, draft)
+}
+
+// GetDrafts handles listing the user's drafts, optionally only those with a status
+func (h *DraftHandler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.DraftStatusDraft && status != models.DraftStatusScheduled {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Status must be draft or scheduled"})
+		return
+	}
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	offset := (page - 1) * limit
+
+	// Get drafts
+	drafts, err := h.draftService.GetDrafts(userID, status, limit, offset)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return drafts
+	utils.SendJSONResponse(w, http.StatusOK, drafts)
+}
+
+// GetDraft handles getting one of the user's drafts
+func (h *DraftHandler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse draft ID from path
+	draftID, ok := parseDraftID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get draft
+	draft, err := h.draftService.GetDraft(draftID, userID)
+	if err != nil {
+		sendDraftError(w, err)
+		return
+	}
+
+	// Return draft
+	utils.SendJSONResponse(w, http.StatusOK, draft)
+}
+
+// UpdateDraft handles editing the content, privacy, link and poll of a draft
+func (h *DraftHandler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse draft ID from path
+	draftID, ok := parseDraftID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req postRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Update draft
+	draft, err := h.draftService.UpdateDraft(draftID, userID, req.Content, req.Privacy, req.LinkURL, req.Poll.toPoll())
+	if err != nil {
+		sendDraftError(w, err)
+		return
+	}
+
+	// Return draft
+	utils.SendJSONResponse(w, http.StatusOK, draft)
+}
+
+// ScheduleDraft handles scheduling or rescheduling a draft
+func (h *DraftHandler) ScheduleDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse draft ID from path
+	draftID, ok := parseDraftID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		PublishAt *time.Time `json:"publish_at" validate:"required"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Schedule draft
+	draft, err := h.draftService.ScheduleDraft(draftID, userID, *req.PublishAt)
+	if err != nil {
+		sendDraftError(w, err)
+		return
+	}
+
+	// Return draft
+	utils.SendJSONResponse(w, http.StatusOK, draft)
+}
+
+// UnscheduleDraft handles cancelling the schedule of a draft
+func (h *DraftHandler) UnscheduleDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse draft ID from path
+	draftID, ok := parseDraftID(w, r)
+	if !ok {
+		return
+	}
+
+	// Unschedule draft
+	draft, err := h.draftService.UnscheduleDraft(draftID, userID)
+	if err != nil {
+		sendDraftError(w, err)
+		return
+	}
+
+	// Return draft
+	utils.SendJSONResponse(w, http.StatusOK, draft)
+}
+
+// PublishDraft handles publishing a draft right away
+func (h *DraftHandler) PublishDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse draft ID from path
+	draftID, ok := parseDraftID(w, r)
+	if !ok {
+		return
+	}
+
+	// Publish draft
+	post, err := h.draftService.PublishDraft(draftID, userID)
+	if err != nil {
+		sendDraftError(w, err)
+		return
+	}
+
+	// Return post
+	utils.SendJSONResponse(w, http.StatusCreated, post)
+}
+
+// DeleteDraft handles deleting a draft and its uploaded images
+func (h *DraftHandler) DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse draft ID from path
+	draftID, ok := parseDraftID(w, r)
+	if !ok {
+		return
+	}
+
+	// Delete draft
+	draft, err := h.draftService.DeleteDraft(draftID, userID)
+	if err != nil {
+		sendDraftError(w, err)
+		return
+	}
+	removeUploadedFiles(draft.ImageURLs)
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Draft deleted successfully"})
+}
+
+// parseDraftID parses the draft ID path parameter, sending an error response if it is invalid
+func parseDraftID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	draftID, err := strconv.Atoi(chi.URLParam(r, "draftId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid draft ID"})
+		return 0, false
+	}
+	return draftID, true
+}
+
+// sendDraftError sends the response for an error returned by the draft service
+func sendDraftError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrDraftNotFound):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Draft not found"})
+	case errors.Is(err, services.ErrDraftBusy):
+		utils.SendJSONResponse(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrEmptyPost), errors.Is(err, services.ErrInvalidPoll),
+		errors.Is(err, services.ErrInvalidLink), errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrContentRejected):
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
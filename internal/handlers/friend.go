@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -27,6 +28,13 @@ func NewFriendHandler(friendService *services.FriendService, validator *utils.Va
 
 // GetUserFriends handles getting a user's friends
 func (h *FriendHandler) GetUserFriends(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse user ID from path
 	userIDStr := chi.URLParam(r, "userId")
 	userID, err := strconv.Atoi(userIDStr)
@@ -36,7 +44,11 @@ func (h *FriendHandler) GetUserFriends(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get friends
-	friends, err := h.friendService.GetFriendsForUser(userID)
+	friends, err := h.friendService.GetFriendsForUser(userID, viewerID)
+	if errors.Is(err, services.ErrNotVisible) {
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": "This friend list is private"})
+		return
+	}
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
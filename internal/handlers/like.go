@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/gocli/social_api/internal/utils"
 )
 
+// maxLikesLimit caps the page size of the users who reacted to a resource
+const maxLikesLimit = 100
+
 // LikeHandler handles like-related HTTP requests
 type LikeHandler struct {
 	BaseHandler
@@ -46,6 +50,10 @@ func (h *LikeHandler) LikeResource(w http.ResponseWriter, r *http.Request) {
 
 	// Like the resource
 	if err := h.likeService.LikeResource(userID, resourceType, resourceID); err != nil {
+		if isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+			return
+		}
 		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
@@ -54,6 +62,54 @@ func (h *LikeHandler) LikeResource(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSONResponse(w, http.StatusCreated, map[string]string{"message": "Resource liked"})
 }
 
+// ReactToResource handles setting the user's reaction to a resource
+func (h *LikeHandler) ReactToResource(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse resource type and ID from path
+	resourceType := chi.URLParam(r, "resourceType")
+	resourceIDStr := chi.URLParam(r, "resourceId")
+	resourceID, err := strconv.Atoi(resourceIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid resource ID"})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Type string `json:"type" validate:"required,oneof=like love haha wow sad angry"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// React to the resource
+	if err := h.likeService.ReactToResource(userID, resourceType, resourceID, req.Type); err != nil {
+		if isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+			return
+		}
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"type": req.Type})
+}
+
 // UnlikeResource handles unliking a resource
 func (h *LikeHandler) UnlikeResource(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
@@ -74,6 +130,10 @@ func (h *LikeHandler) UnlikeResource(w http.ResponseWriter, r *http.Request) {
 
 	// Unlike the resource
 	if err := h.likeService.UnlikeResource(userID, resourceType, resourceID); err != nil {
+		if isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+			return
+		}
 		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
@@ -84,6 +144,13 @@ func (h *LikeHandler) UnlikeResource(w http.ResponseWriter, r *http.Request) {
 
 // GetLikesForResource handles getting likes for a resource
 func (h *LikeHandler) GetLikesForResource(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse resource type and ID from path
 	resourceType := chi.URLParam(r, "resourceType")
 	resourceIDStr := chi.URLParam(r, "resourceId")
@@ -93,13 +160,35 @@ func (h *LikeHandler) GetLikesForResource(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Parse query parameters
+	reactionType := r.URL.Query().Get("type")
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxLikesLimit {
+		limit = maxLikesLimit
+	}
+	offset := (page - 1) * limit
+
 	// Get likes for the resource
-	likes, err := h.likeService.GetLikesForResource(resourceType, resourceID)
+	likes, err := h.likeService.GetLikesForResource(resourceType, resourceID, userID, reactionType, limit, offset)
 	if err != nil {
-		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		if isResourceNotFound(err) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+			return
+		}
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
 	// Return likes
 	utils.SendJSONResponse(w, http.StatusOK, likes)
 }
+
+// isResourceNotFound reports whether err means the liked or commented resource does not exist,
+// is not visible to the user, or is of an unknown type
+func isResourceNotFound(err error) bool {
+	return errors.Is(err, services.ErrUnknownResourceType) || errors.Is(err, services.ErrResourceNotFound)
+}
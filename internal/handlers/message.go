@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxMessagesLimit caps the page size of conversations and message history
+const maxMessagesLimit = 100
+
+// MessageHandler handles direct messaging HTTP requests
+type MessageHandler struct {
+	BaseHandler
+	messageService *services.MessageService
+	validator      *utils.Validator
+}
+
+// NewMessageHandler creates a new MessageHandler
+func NewMessageHandler(messageService *services.MessageService, validator *utils.Validator) *MessageHandler {
+	return &MessageHandler{
+		messageService: messageService,
+		validator:      validator,
+	}
+}
+
+// StartConversation handles starting a one-to-one or group conversation
+func (h *MessageHandler) StartConversation(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		UserIDs []int  `json:"user_ids" validate:"required,min=1"`
+		Title   string `json:"title" validate:"max=100"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Start conversation
+	conversation, err := h.messageService.StartConversation(userID, req.UserIDs, req.Title)
+	if err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return conversation
+	utils.SendJSONResponse(w, http.StatusOK, conversation)
+}
+
+// GetConversations handles listing the user's conversations
+func (h *MessageHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxMessagesLimit {
+		limit = maxMessagesLimit
+	}
+	offset := (page - 1) * limit
+
+	// Get conversations
+	conversations, err := h.messageService.GetConversations(userID, limit, offset)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return conversations
+	utils.SendJSONResponse(w, http.StatusOK, conversations)
+}
+
+// GetUnreadCount handles getting the number of unread messages
+func (h *MessageHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Count unread messages
+	count, err := h.messageService.GetUnreadCount(userID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return count
+	utils.SendJSONResponse(w, http.StatusOK, map[string]int{"unread_count": count})
+}
+
+// GetConversation handles getting a single conversation
+func (h *MessageHandler) GetConversation(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get conversation
+	conversation, err := h.messageService.GetConversation(conversationID, userID)
+	if err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return conversation
+	utils.SendJSONResponse(w, http.StatusOK, conversation)
+}
+
+// GetMessages handles getting a conversation's message history
+func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse query parameters
+	before := h.ParseQueryInt(r, "before", 0)
+	limit := h.ParseQueryInt(r, "limit", 50)
+	if limit <= 0 || limit > maxMessagesLimit {
+		limit = maxMessagesLimit
+	}
+
+	// Get messages
+	messages, err := h.messageService.GetMessages(conversationID, userID, before, limit)
+	if err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return messages
+	utils.SendJSONResponse(w, http.StatusOK, messages)
+}
+
+// SendMessage handles sending a message. The body is either JSON with the text
+// or a multipart form with "content" and an optional "image" file.
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Content string `json:"content" validate:"max=5000"`
+	}
+
+	isMultipart := strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+	if isMultipart {
+		// Parse multipart form with max memory of 10MB
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Unable to parse form"})
+			return
+		}
+		req.Content = r.FormValue("content")
+	} else if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Save the attached image, if any
+	attachmentURL := ""
+	if isMultipart && hasUploadedFile(r, "image") {
+		attachmentURL, err = saveUploadedImage(r, "image", "messages")
+		if err != nil {
+			sendUploadError(w, err)
+			return
+		}
+	}
+
+	// Send message
+	message, err := h.messageService.SendMessage(conversationID, userID, req.Content, attachmentURL)
+	if err != nil {
+		if attachmentURL != "" {
+			removeUploadedFile(attachmentURL)
+		}
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return message
+	utils.SendJSONResponse(w, http.StatusCreated, message)
+}
+
+// MarkRead handles marking a conversation as read
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body; without a message ID everything is marked as read
+	var req struct {
+		MessageID int `json:"message_id" validate:"min=0"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := h.DecodeJSONBody(w, r, &req); err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+			return
+		}
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Mark conversation as read
+	if err := h.messageService.MarkRead(conversationID, userID, req.MessageID); err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Conversation marked as read"})
+}
+
+// AddMember handles adding a member to a group conversation
+func (h *MessageHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		UserID int `json:"user_id" validate:"required"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Add member
+	conversation, err := h.messageService.AddMember(conversationID, userID, req.UserID)
+	if err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return conversation
+	utils.SendJSONResponse(w, http.StatusOK, conversation)
+}
+
+// MuteConversation handles muting or unmuting a conversation
+func (h *MessageHandler) MuteConversation(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Muted bool `json:"muted"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Mute conversation
+	if err := h.messageService.SetMuted(conversationID, userID, req.Muted); err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]bool{"muted": req.Muted})
+}
+
+// LeaveConversation handles leaving a conversation
+func (h *MessageHandler) LeaveConversation(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse conversation ID from path
+	conversationID, ok := parseConversationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Leave conversation
+	if err := h.messageService.LeaveConversation(conversationID, userID); err != nil {
+		sendMessagingError(w, err)
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Left conversation"})
+}
+
+// parseConversationID parses the conversation ID from the path, sending an error response if it is invalid
+func parseConversationID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	conversationID, err := strconv.Atoi(chi.URLParam(r, "conversationId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid conversation ID"})
+		return 0, false
+	}
+	return conversationID, true
+}
+
+// sendMessagingError sends the response for an error returned by the MessageService
+func sendMessagingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrConversationNotFound):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Conversation not found"})
+	case errors.Is(err, services.ErrMessagingNotAllowed):
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxModerationPageLimit caps the page size of the report queue and the audit trail
+const maxModerationPageLimit = 100
+
+// ModerationHandler handles abuse report and moderation HTTP requests
+type ModerationHandler struct {
+	BaseHandler
+	moderationService *services.ModerationService
+	validator         *utils.Validator
+}
+
+// NewModerationHandler creates a new ModerationHandler
+func NewModerationHandler(moderationService *services.ModerationService, validator *utils.Validator) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+		validator:         validator,
+	}
+}
+
+// reportRequest is the body for reporting content
+type reportRequest struct {
+	ResourceType string `json:"resource_type" validate:"required,oneof=posts comments photos users"`
+	ResourceID   int    `json:"resource_id" validate:"required,min=1"`
+	Reason       string `json:"reason" validate:"required,oneof=spam harassment hate_speech violence nudity misinformation other"`
+	Details      string `json:"details" validate:"max=1000"`
+}
+
+// moderationActionRequest is the body for acting on reported content
+type moderationActionRequest struct {
+	ResourceType  string `json:"resource_type" validate:"required,oneof=posts comments photos users"`
+	ResourceID    int    `json:"resource_id" validate:"required,min=1"`
+	Action        string `json:"action" validate:"required,oneof=hide_content warn_user suspend_user dismiss"`
+	Reason        string `json:"reason" validate:"omitempty,oneof=spam harassment hate_speech violence nudity misinformation other"` // Reason for a suspension
+	Note          string `json:"note" validate:"max=1000"`
+	DurationHours int    `json:"duration_hours" validate:"min=0,max=8760"` // Required to suspend a user
+}
+
+// ReportContent handles reporting a post, comment, photo or profile
+func (h *ModerationHandler) ReportContent(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse request body
+	var req reportRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Report content
+	report, err := h.moderationService.ReportContent(userID, req.ResourceType, req.ResourceID, req.Reason, req.Details)
+	if err != nil {
+		sendModerationError(w, err)
+		return
+	}
+
+	// Return created report
+	utils.SendJSONResponse(w, http.StatusCreated, report)
+}
+
+// GetQueue handles getting the reported content awaiting review
+func (h *ModerationHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	limit, offset := h.parsePage(r)
+
+	// Get report queue
+	items, err := h.moderationService.GetQueue(limit, offset)
+	if err != nil {
+		sendModerationError(w, err)
+		return
+	}
+
+	// Return report queue
+	utils.SendJSONResponse(w, http.StatusOK, items)
+}
+
+// GetReports handles getting the reports on a piece of content
+func (h *ModerationHandler) GetReports(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	resourceType := r.URL.Query().Get("resource_type")
+	resourceID := h.ParseQueryInt(r, "resource_id", 0)
+	if resourceType == "" || resourceID <= 0 {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "resource_type and resource_id are required"})
+		return
+	}
+
+	// Get reports
+	reports, err := h.moderationService.GetReports(resourceType, resourceID)
+	if err != nil {
+		sendModerationError(w, err)
+		return
+	}
+
+	// Return reports
+	utils.SendJSONResponse(w, http.StatusOK, reports)
+}
+
+// TakeAction handles a moderator's decision on reported content
+func (h *ModerationHandler) TakeAction(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse request body
+	var req moderationActionRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Take action
+	duration := time.Duration(req.DurationHours) * time.Hour
+	action, err := h.moderationService.TakeAction(userID, req.ResourceType, req.ResourceID, req.Action, req.Reason,
+		req.Note, duration)
+	if err != nil {
+		sendModerationError(w, err)
+		return
+	}
+
+	// Return recorded action
+	utils.SendJSONResponse(w, http.StatusCreated, action)
+}
+
+// GetActions handles getting the moderation audit trail, optionally filtered by target_user_id
+func (h *ModerationHandler) GetActions(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	limit, offset := h.parsePage(r)
+	var targetUserID *int
+	if value := r.URL.Query().Get("target_user_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid target user ID"})
+			return
+		}
+		targetUserID = &id
+	}
+
+	// Get moderation actions
+	actions, err := h.moderationService.GetActions(targetUserID, limit, offset)
+	if err != nil {
+		sendModerationError(w, err)
+		return
+	}
+
+	// Return moderation actions
+	utils.SendJSONResponse(w, http.StatusOK, actions)
+}
+
+// GetMyModerationActions handles getting the moderation actions taken against the current user
+func (h *ModerationHandler) GetMyModerationActions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	limit, offset := h.parsePage(r)
+
+	// Get moderation actions
+	actions, err := h.moderationService.GetMyActions(userID, limit, offset)
+	if err != nil {
+		sendModerationError(w, err)
+		return
+	}
+
+	// Return moderation actions
+	utils.SendJSONResponse(w, http.StatusOK, actions)
+}
+
+// parsePage parses the page and limit query parameters into a limit and offset
+func (h *ModerationHandler) parsePage(r *http.Request) (int, int) {
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxModerationPageLimit {
+		limit = maxModerationPageLimit
+	}
+	return limit, (page - 1) * limit
+}
+
+// sendModerationError sends the response for an error returned by the moderation service
+func sendModerationError(w http.ResponseWriter, err error) {
+	switch {
+	case isResourceNotFound(err):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+	case errors.Is(err, repositories.ErrConflict):
+		utils.SendJSONResponse(w, http.StatusConflict, map[string]string{"error": "You have already reported this"})
+	case errors.Is(err, services.ErrRoleTooHigh):
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrCannotReportSelf), errors.Is(err, services.ErrInvalidModerationAction),
+		errors.Is(err, services.ErrInvalidSuspension):
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
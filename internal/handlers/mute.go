@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// MuteHandler handles feed muting and post hiding HTTP requests
+type MuteHandler struct {
+	BaseHandler
+	muteService *services.MuteService
+	validator   *utils.Validator
+}
+
+// NewMuteHandler creates a new MuteHandler
+func NewMuteHandler(muteService *services.MuteService, validator *utils.Validator) *MuteHandler {
+	return &MuteHandler{
+		muteService: muteService,
+		validator:   validator,
+	}
+}
+
+// MuteUser handles leaving a user's posts out of the feed until they are unmuted
+func (h *MuteHandler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	h.mute(w, r, h.muteService.MuteUser)
+}
+
+// SnoozeUser handles leaving a user's posts out of the feed for 30 days
+func (h *MuteHandler) SnoozeUser(w http.ResponseWriter, r *http.Request) {
+	h.mute(w, r, h.muteService.SnoozeUser)
+}
+
+// UnmuteUser handles ending a mute or snooze
+func (h *MuteHandler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse muted user ID from path
+	mutedUserID, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	// Unmute user
+	if err := h.muteService.UnmuteUser(userID, mutedUserID); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "User unmuted"})
+}
+
+// GetMyMutedUsers handles getting the users whose posts are left out of the current user's feed
+func (h *MuteHandler) GetMyMutedUsers(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Get muted users
+	mutes, err := h.muteService.GetMutedUsers(userID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return muted users
+	utils.SendJSONResponse(w, http.StatusOK, mutes)
+}
+
+// HidePost handles leaving a post out of the current user's feed
+func (h *MuteHandler) HidePost(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postID, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Hide post
+	if err := h.muteService.HidePost(userID, postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, services.ErrNotVisible) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Post not found"})
+			return
+		}
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Post hidden"})
+}
+
+// UnhidePost handles showing a hidden post in the current user's feed again
+func (h *MuteHandler) UnhidePost(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postID, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Unhide post
+	if err := h.muteService.UnhidePost(userID, postID); err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Post unhidden"})
+}
+
+// mute handles muting or snoozing the user in the path with the given service method
+func (h *MuteHandler) mute(w http.ResponseWriter, r *http.Request, mute func(userID, mutedUserID int) (*models.FeedMute, error)) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse muted user ID from path
+	mutedUserID, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	// Mute user
+	muted, err := mute(userID, mutedUserID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return mute
+	utils.SendJSONResponse(w, http.StatusOK, muted)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxNotificationsLimit caps the page size of the notification list
+const maxNotificationsLimit = 50
+
+// NotificationHandler handles notification-related HTTP requests
+type NotificationHandler struct {
+	BaseHandler
+	notificationService *services.NotificationService
+	validator           *utils.Validator
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(notificationService *services.NotificationService, validator *utils.Validator) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		validator:           validator,
+	}
+}
+
+// GetNotifications handles getting the user's notifications
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxNotificationsLimit {
+		limit = maxNotificationsLimit
+	}
+	offset := (page - 1) * limit
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	// Get notifications
+	notifications, err := h.notificationService.GetNotifications(userID, unreadOnly, limit, offset)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return notifications
+	utils.SendJSONResponse(w, http.StatusOK, notifications)
+}
+
+// GetUnreadCount handles getting the number of unread notifications
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Count unread notifications
+	count, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return count
+	utils.SendJSONResponse(w, http.StatusOK, map[string]int{"unread_count": count})
+}
+
+// MarkRead handles marking a notification as read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse notification ID from path
+	notificationIDStr := chi.URLParam(r, "notificationId")
+	notificationID, err := strconv.Atoi(notificationIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid notification ID"})
+		return
+	}
+
+	// Mark notification as read
+	err = h.notificationService.MarkRead(notificationID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Notification not found"})
+		return
+	}
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Notification marked as read"})
+}
+
+// MarkAllRead handles marking all of the user's notifications as read
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Mark all notifications as read
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "All notifications marked as read"})
+}
+
+// GetPreferences handles getting the user's notification preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Get preferences
+	preferences, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return preferences
+	utils.SendJSONResponse(w, http.StatusOK, preferences)
+}
+
+// UpdatePreferences handles turning notification types on or off
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse request body, e.g. {"like": false, "comment": true}
+	var req map[string]bool
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Update preferences
+	preferences, err := h.notificationService.UpdatePreferences(userID, req)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return updated preferences
+	utils.SendJSONResponse(w, http.StatusOK, preferences)
+}
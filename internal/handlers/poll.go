@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// pollRequest is the poll of a new post
+type pollRequest struct {
+	Options           []string   `json:"options" validate:"min=2,max=10,dive,required,max=200"`
+	MultipleChoice    bool       `json:"multiple_choice"`
+	ResultsVisibility string     `json:"results_visibility" validate:"omitempty,oneof=always after_vote after_close"`
+	ClosesAt          *time.Time `json:"closes_at"`
+}
+
+// toPoll converts the request to a poll, or nil if no poll was sent
+func (p *pollRequest) toPoll() *models.Poll {
+	if p == nil {
+		return nil
+	}
+
+	poll := &models.Poll{
+		MultipleChoice:    p.MultipleChoice,
+		ResultsVisibility: p.ResultsVisibility,
+		ClosesAt:          p.ClosesAt,
+	}
+	for _, text := range p.Options {
+		poll.Options = append(poll.Options, &models.PollOption{Text: text})
+	}
+
+	return poll
+}
+
+// PollHandler handles poll-related HTTP requests
+type PollHandler struct {
+	BaseHandler
+	pollService *services.PollService
+	validator   *utils.Validator
+}
+
+// NewPollHandler creates a new PollHandler
+func NewPollHandler(pollService *services.PollService, validator *utils.Validator) *PollHandler {
+	return &PollHandler{
+		pollService: pollService,
+		validator:   validator,
+	}
+}
+
+// Vote handles voting in the poll of a post, replacing the user's earlier votes
+func (h *PollHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postID, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		OptionIDs []int `json:"option_ids" validate:"required,min=1,max=10"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Vote
+	poll, err := h.pollService.Vote(postID, userID, req.OptionIDs)
+	if err != nil {
+		sendPollError(w, err)
+		return
+	}
+
+	// Return poll
+	utils.SendJSONResponse(w, http.StatusOK, poll)
+}
+
+// RetractVote handles removing the user's votes from the poll of a post
+func (h *PollHandler) RetractVote(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postID, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Retract vote
+	poll, err := h.pollService.RetractVote(postID, userID)
+	if err != nil {
+		sendPollError(w, err)
+		return
+	}
+
+	// Return poll
+	utils.SendJSONResponse(w, http.StatusOK, poll)
+}
+
+// sendPollError sends the response for an error returned by the poll service
+func sendPollError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, services.ErrNotVisible):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Poll not found"})
+	case errors.Is(err, services.ErrPollClosed):
+		utils.SendJSONResponse(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidVote):
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/gocli/social_api/internal/models"
 	"github.com/gocli/social_api/internal/services"
 	"github.com/gocli/social_api/internal/utils"
 )
@@ -34,26 +39,23 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse request body
-	var req struct {
-		Content string `json:"content" validate:"required"`
-		Privacy string `json:"privacy"`
-	}
-
-	if err := h.DecodeJSONBody(w, r, &req); err != nil {
-		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	// Parse and validate request body
+	var req postRequest
+	isMultipart, ok := decodePostBody(&h.BaseHandler, h.validator, w, r, &req, &req)
+	if !ok {
 		return
 	}
 
-	// Validate request
-	if err := h.validator.Validate(req); err != nil {
-		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+	// Save the attached images, if any
+	attachments, ok := postAttachments(w, r, isMultipart, &req)
+	if !ok {
 		return
 	}
 
 	// Create post
-	post, err := h.postService.CreatePost(userID, req.Content, req.Privacy)
+	post, err := h.postService.CreatePost(userID, req.Content, req.Privacy, attachments)
 	if err != nil {
+		removeUploadedFiles(attachments.ImageURLs)
 		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
@@ -62,6 +64,66 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSONResponse(w, http.StatusCreated, post)
 }
 
+// postRequest is the body of a new post or draft
+type postRequest struct {
+	Content string       `json:"content"`
+	Privacy string       `json:"privacy" validate:"omitempty,oneof=public friends only_me"`
+	LinkURL string       `json:"link_url" validate:"omitempty,max=2048"`
+	Poll    *pollRequest `json:"poll"`
+}
+
+// decodePostBody decodes and validates the body of a new post or draft into dst, which is
+// post or a struct embedding it. The body is either JSON or a multipart form with the same
+// fields, the poll as a JSON string and up to 10 "images" files; other form fields are left
+// to the caller. It sends the error response and returns false if the body is not valid.
+func decodePostBody(h *BaseHandler, validator *utils.Validator, w http.ResponseWriter, r *http.Request,
+	dst interface{}, post *postRequest) (isMultipart bool, ok bool) {
+	isMultipart = strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+	if isMultipart {
+		// Parse multipart form with max memory of 10MB
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Unable to parse form"})
+			return isMultipart, false
+		}
+		post.Content = r.FormValue("content")
+		post.Privacy = r.FormValue("privacy")
+		post.LinkURL = r.FormValue("link_url")
+		if poll := r.FormValue("poll"); poll != "" {
+			if err := json.Unmarshal([]byte(poll), &post.Poll); err != nil {
+				utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid poll JSON"})
+				return isMultipart, false
+			}
+		}
+	} else if err := h.DecodeJSONBody(w, r, dst); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return isMultipart, false
+	}
+
+	// Validate request
+	if err := validator.Validate(dst); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return isMultipart, false
+	}
+
+	return isMultipart, true
+}
+
+// postAttachments returns the attachments of a decoded post body, saving the images uploaded
+// with a multipart form. It sends the error response and returns false if they cannot be saved.
+func postAttachments(w http.ResponseWriter, r *http.Request, isMultipart bool, post *postRequest) (services.PostAttachments, bool) {
+	attachments := services.PostAttachments{LinkURL: post.LinkURL, Poll: post.Poll.toPoll()}
+	if isMultipart {
+		imageURLs, err := saveUploadedImages(r, "images", "posts", services.MaxPostImages)
+		if err != nil {
+			sendUploadError(w, err)
+			return attachments, false
+		}
+		attachments.ImageURLs = imageURLs
+	}
+
+	return attachments, true
+}
+
 // GetFeed handles getting the user's feed
 func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
@@ -75,10 +137,22 @@ func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	page := h.ParseQueryInt(r, "page", 1)
 	limit := h.ParseQueryInt(r, "limit", 20)
 	offset := (page - 1) * limit
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = models.FeedModeLatest
+	}
+	filter := models.FeedFilter{
+		ContentType: r.URL.Query().Get("type"),
+		AuthorID:    h.ParseQueryInt(r, "author_id", 0),
+	}
 
 	// Get feed
-	posts, err := h.postService.GetFeed(userID, limit, offset)
+	posts, err := h.postService.GetFeed(userID, mode, filter, limit, offset)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidFeedMode) || errors.Is(err, services.ErrInvalidFeedFilter) {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
 		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -89,6 +163,13 @@ func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 
 // GetUserPosts handles getting a user's posts
 func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse user ID from path
 	userIDStr := chi.URLParam(r, "userId")
 	userID, err := strconv.Atoi(userIDStr)
@@ -103,7 +184,7 @@ func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	offset := (page - 1) * limit
 
 	// Get user's posts
-	posts, err := h.postService.GetPostsByUserID(userID, limit, offset)
+	posts, err := h.postService.GetPostsByUserID(userID, viewerID, limit, offset)
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
@@ -113,8 +194,69 @@ func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSONResponse(w, http.StatusOK, posts)
 }
 
+// SharePost handles sharing a post to the user's timeline
+func (h *PostHandler) SharePost(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postIDStr := chi.URLParam(r, "postId")
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Parse request body; the commentary is optional
+	var req struct {
+		Content string `json:"content"`
+		Privacy string `json:"privacy" validate:"omitempty,oneof=public friends only_me"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := h.DecodeJSONBody(w, r, &req); err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+			return
+		}
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Share post
+	post, err := h.postService.SharePost(userID, postID, req.Content, req.Privacy)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, services.ErrNotVisible):
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Post not found"})
+		case errors.Is(err, services.ErrShareWidensAudience):
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		default:
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return
+	}
+
+	// Return post
+	utils.SendJSONResponse(w, http.StatusCreated, post)
+}
+
 // GetPost handles getting a single post
 func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse post ID from path
 	postIDStr := chi.URLParam(r, "postId")
 	postID, err := strconv.Atoi(postIDStr)
@@ -124,7 +266,7 @@ func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get post
-	post, err := h.postService.GetPostByID(postID)
+	post, err := h.postService.GetPostByID(postID, viewerID)
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Post not found"})
 		return
@@ -154,7 +296,7 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var req struct {
 		Content string `json:"content" validate:"required"`
-		Privacy string `json:"privacy"`
+		Privacy string `json:"privacy" validate:"omitempty,oneof=public friends only_me"`
 	}
 
 	if err := h.DecodeJSONBody(w, r, &req); err != nil {
@@ -171,6 +313,94 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	// Update post
 	post, err := h.postService.UpdatePost(postID, userID, req.Content, req.Privacy)
 	if err != nil {
+		if errors.Is(err, services.ErrShareWidensAudience) || errors.Is(err, services.ErrContentModerated) ||
+			errors.Is(err, services.ErrContentHeld) {
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return post
+	utils.SendJSONResponse(w, http.StatusOK, post)
+}
+
+// GetPostRevisions handles getting the edit history of a post
+func (h *PostHandler) GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postIDStr := chi.URLParam(r, "postId")
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Get revisions
+	revisions, err := h.postService.GetPostRevisions(postID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Post not found"})
+		case errors.Is(err, services.ErrHistoryNotAllowed):
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		default:
+			utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return
+	}
+
+	// Return revisions
+	utils.SendJSONResponse(w, http.StatusOK, revisions)
+}
+
+// UpdateCommentPermission handles setting who may comment on a post
+func (h *PostHandler) UpdateCommentPermission(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse post ID from path
+	postIDStr := chi.URLParam(r, "postId")
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		CommentPermission string `json:"comment_permission" validate:"required,oneof=everyone friends nobody"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Update comment permission
+	post, err := h.postService.UpdateCommentPermission(postID, userID, req.CommentPermission)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Post not found"})
+			return
+		}
 		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxSavedItemsLimit caps the page size of saved items
+const maxSavedItemsLimit = 100
+
+// SavedHandler handles saved item and collection HTTP requests
+type SavedHandler struct {
+	BaseHandler
+	savedService *services.SavedService
+	validator    *utils.Validator
+}
+
+// NewSavedHandler creates a new SavedHandler
+func NewSavedHandler(savedService *services.SavedService, validator *utils.Validator) *SavedHandler {
+	return &SavedHandler{
+		savedService: savedService,
+		validator:    validator,
+	}
+}
+
+// collectionRequest is the body for creating or renaming a collection
+type collectionRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// CreateCollection handles creating a collection for the current user
+func (h *SavedHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse request body
+	var req collectionRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Create collection
+	collection, err := h.savedService.CreateCollection(userID, req.Name)
+	if err != nil {
+		sendSavedError(w, err)
+		return
+	}
+
+	// Return collection
+	utils.SendJSONResponse(w, http.StatusCreated, collection)
+}
+
+// GetMyCollections handles getting the current user's collections
+func (h *SavedHandler) GetMyCollections(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Get collections
+	collections, err := h.savedService.GetCollections(userID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return collections
+	utils.SendJSONResponse(w, http.StatusOK, collections)
+}
+
+// RenameCollection handles renaming one of the current user's collections
+func (h *SavedHandler) RenameCollection(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse collection ID from path
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req collectionRequest
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Rename collection
+	collection, err := h.savedService.RenameCollection(collectionID, userID, req.Name)
+	if err != nil {
+		sendSavedError(w, err)
+		return
+	}
+
+	// Return collection
+	utils.SendJSONResponse(w, http.StatusOK, collection)
+}
+
+// DeleteCollection handles deleting one of the current user's collections
+func (h *SavedHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse collection ID from path
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+
+	// Delete collection
+	if err := h.savedService.DeleteCollection(collectionID, userID); err != nil {
+		sendSavedError(w, err)
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Collection deleted"})
+}
+
+// GetMySavedItems handles getting all of the current user's saved items
+func (h *SavedHandler) GetMySavedItems(w http.ResponseWriter, r *http.Request) {
+	h.getSavedItems(w, r, nil)
+}
+
+// GetCollectionItems handles getting the saved items in one of the current user's collections
+func (h *SavedHandler) GetCollectionItems(w http.ResponseWriter, r *http.Request) {
+	// Parse collection ID from path
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+
+	h.getSavedItems(w, r, &collectionID)
+}
+
+// SaveItem handles saving a post or photo, optionally into a collection given as collection_id
+func (h *SavedHandler) SaveItem(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse resource type and ID from path
+	resourceType := chi.URLParam(r, "resourceType")
+	resourceID, err := strconv.Atoi(chi.URLParam(r, "resourceId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid resource ID"})
+		return
+	}
+
+	// Parse request body, which may be left out
+	var req struct {
+		CollectionID *int `json:"collection_id"`
+	}
+	if r.ContentLength != 0 {
+		if err := h.DecodeJSONBody(w, r, &req); err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+			return
+		}
+	}
+
+	// Save the resource
+	item, err := h.savedService.SaveItem(userID, resourceType, resourceID, req.CollectionID)
+	if err != nil {
+		sendSavedError(w, err)
+		return
+	}
+
+	// Return saved item
+	utils.SendJSONResponse(w, http.StatusOK, item)
+}
+
+// UnsaveItem handles removing a saved post or photo
+func (h *SavedHandler) UnsaveItem(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse resource type and ID from path
+	resourceType := chi.URLParam(r, "resourceType")
+	resourceID, err := strconv.Atoi(chi.URLParam(r, "resourceId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid resource ID"})
+		return
+	}
+
+	// Unsave the resource
+	if err := h.savedService.UnsaveItem(userID, resourceType, resourceID); err != nil {
+		sendSavedError(w, err)
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Item unsaved"})
+}
+
+// getSavedItems handles getting a page of the current user's saved items, optionally in a collection
+func (h *SavedHandler) getSavedItems(w http.ResponseWriter, r *http.Request, collectionID *int) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxSavedItemsLimit {
+		limit = maxSavedItemsLimit
+	}
+	offset := (page - 1) * limit
+
+	// Get saved items
+	items, err := h.savedService.GetSavedItems(userID, collectionID, limit, offset)
+	if err != nil {
+		sendSavedError(w, err)
+		return
+	}
+
+	// Return saved items
+	utils.SendJSONResponse(w, http.StatusOK, items)
+}
+
+// parseCollectionID parses the collection ID path parameter, sending an error response if it is invalid
+func parseCollectionID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	collectionID, err := strconv.Atoi(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid collection ID"})
+		return 0, false
+	}
+	return collectionID, true
+}
+
+// sendSavedError sends the response for an error returned by the saved service
+func sendSavedError(w http.ResponseWriter, err error) {
+	switch {
+	case isResourceNotFound(err):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Resource not found"})
+	case errors.Is(err, services.ErrCollectionNotFound):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrCollectionExists):
+		utils.SendJSONResponse(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxSearchLimit caps the page size of search results
+const maxSearchLimit = 50
+
+// SearchHandler handles search-related HTTP requests
+type SearchHandler struct {
+	BaseHandler
+	searchService *services.SearchService
+	validator     *utils.Validator
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(searchService *services.SearchService, validator *utils.Validator) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		validator:     validator,
+	}
+}
+
+// Search handles searching users and posts
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	query := r.URL.Query().Get("q")
+	searchType := r.URL.Query().Get("type")
+	limit := h.ParseQueryInt(r, "limit", 20)
+	offset := h.ParseQueryInt(r, "offset", 0)
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Search
+	results, err := h.searchService.Search(query, searchType, userID, limit, offset)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return results
+	utils.SendJSONResponse(w, http.StatusOK, results)
+}
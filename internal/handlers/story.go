@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxStoryViewersLimit caps the page size of the users who have seen a story
+const maxStoryViewersLimit = 100
+
+// StoryHandler handles story-related HTTP requests
+type StoryHandler struct {
+	BaseHandler
+	storyService *services.StoryService
+	validator    *utils.Validator
+}
+
+// NewStoryHandler creates a new StoryHandler
+func NewStoryHandler(storyService *services.StoryService, validator *utils.Validator) *StoryHandler {
+	return &StoryHandler{
+		storyService: storyService,
+		validator:    validator,
+	}
+}
+
+// CreateStory handles posting a story. Text stories are sent as JSON; image stories as a
+// multipart form with the "image" file and optional "content" caption and "privacy".
+func (h *StoryHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		Type    string `json:"type" validate:"required,oneof=image text"`
+		Content string `json:"content" validate:"max=2000"`
+		Privacy string `json:"privacy" validate:"omitempty,oneof=public friends only_me"`
+	}
+
+	isMultipart := strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+	if isMultipart {
+		// Parse multipart form with max memory of 10MB
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Unable to parse form"})
+			return
+		}
+		req.Type = "image"
+		req.Content = r.FormValue("content")
+		req.Privacy = r.FormValue("privacy")
+	} else if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
+	// Save the image of an image story
+	mediaURL := ""
+	if isMultipart {
+		mediaURL, err = saveUploadedImage(r, "image", "stories")
+		if err != nil {
+			sendUploadError(w, err)
+			return
+		}
+	}
+
+	// Create story
+	story, err := h.storyService.CreateStory(userID, req.Type, req.Content, mediaURL, req.Privacy)
+	if err != nil {
+		if mediaURL != "" {
+			removeUploadedFile(mediaURL)
+		}
+		sendStoryError(w, err)
+		return
+	}
+
+	// Return story
+	utils.SendJSONResponse(w, http.StatusCreated, story)
+}
+
+// GetTray handles getting the friends with active stories
+func (h *StoryHandler) GetTray(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	offset := (page - 1) * limit
+
+	// Get stories tray
+	items, err := h.storyService.GetTray(userID, limit, offset)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return stories tray
+	utils.SendJSONResponse(w, http.StatusOK, items)
+}
+
+// GetMyStories handles getting the user's own active stories with their view counts
+func (h *StoryHandler) GetMyStories(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Get stories
+	stories, err := h.storyService.GetUserStories(userID, userID)
+	if err != nil {
+		sendStoryError(w, err)
+		return
+	}
+
+	// Return stories
+	utils.SendJSONResponse(w, http.StatusOK, stories)
+}
+
+// GetUserStories handles getting a user's active stories
+func (h *StoryHandler) GetUserStories(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse user ID from path
+	userID, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	// Get stories
+	stories, err := h.storyService.GetUserStories(userID, viewerID)
+	if err != nil {
+		sendStoryError(w, err)
+		return
+	}
+
+	// Return stories
+	utils.SendJSONResponse(w, http.StatusOK, stories)
+}
+
+// ViewStory handles getting a story, which marks it as seen
+func (h *StoryHandler) ViewStory(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse story ID from path
+	storyID, ok := parseStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	// View story
+	story, err := h.storyService.ViewStory(storyID, userID)
+	if err != nil {
+		sendStoryError(w, err)
+		return
+	}
+
+	// Return story
+	utils.SendJSONResponse(w, http.StatusOK, story)
+}
+
+// GetStoryViewers handles getting the users who have seen one of the user's stories
+func (h *StoryHandler) GetStoryViewers(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse story ID from path
+	storyID, ok := parseStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxStoryViewersLimit {
+		limit = maxStoryViewersLimit
+	}
+	offset := (page - 1) * limit
+
+	// Get story viewers
+	viewers, err := h.storyService.GetViewers(storyID, userID, limit, offset)
+	if err != nil {
+		sendStoryError(w, err)
+		return
+	}
+
+	// Return story viewers
+	utils.SendJSONResponse(w, http.StatusOK, viewers)
+}
+
+// DeleteStory handles deleting one of the user's stories
+func (h *StoryHandler) DeleteStory(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse story ID from path
+	storyID, ok := parseStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	// Delete story
+	if err := h.storyService.DeleteStory(storyID, userID); err != nil {
+		sendStoryError(w, err)
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Story deleted"})
+}
+
+// parseStoryID parses the story ID path parameter, sending an error response if it is invalid
+func parseStoryID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	storyID, err := strconv.Atoi(chi.URLParam(r, "storyId"))
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid story ID"})
+		return 0, false
+	}
+	return storyID, true
+}
+
+// sendStoryError sends the response for an error returned by the story service
+func sendStoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, services.ErrNotVisible):
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "Story not found"})
+	case errors.Is(err, services.ErrNotStoryAuthor):
+		utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStory):
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
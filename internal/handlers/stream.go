@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocli/social_api/internal/realtime"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxWatchedResources caps how many resources a single stream can watch
+const maxWatchedResources = 20
+
+// streamHeartbeatInterval is how often a comment line is sent to keep idle connections open
+const streamHeartbeatInterval = 25 * time.Second
+
+// StreamHandler handles real-time event streams
+type StreamHandler struct {
+	BaseHandler
+	hub               *realtime.Hub
+	visibilityService *services.VisibilityService
+}
+
+// NewStreamHandler creates a new StreamHandler
+func NewStreamHandler(hub *realtime.Hub, visibilityService *services.VisibilityService) *StreamHandler {
+	return &StreamHandler{
+		hub:               hub,
+		visibilityService: visibilityService,
+	}
+}
+
+// Stream handles a Server-Sent Events stream of the user's notifications and
+// friend request events. Clients can also watch resources they are allowed to
+// see for new comments with ?watch=posts:12,photos:3
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Streaming is not supported"})
+		return
+	}
+
+	// Parse watched resources
+	topics := []string{realtime.UserTopic(userID)}
+	if watch := r.URL.Query().Get("watch"); watch != "" {
+		resources := strings.Split(watch, ",")
+		if len(resources) > maxWatchedResources {
+			utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("Cannot watch more than %d resources", maxWatchedResources),
+			})
+			return
+		}
+
+		for _, resource := range resources {
+			resourceType, resourceIDStr, found := strings.Cut(strings.TrimSpace(resource), ":")
+			resourceID, err := strconv.Atoi(resourceIDStr)
+			if !found || err != nil {
+				utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid watch parameter"})
+				return
+			}
+
+			canView, err := h.visibilityService.CanViewResource(resourceType, resourceID, userID)
+			if err != nil || !canView {
+				utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": "Cannot watch " + resource})
+				return
+			}
+			topics = append(topics, realtime.ResourceTopic(resourceType, resourceID))
+		}
+	}
+
+	// Subscribe before sending headers so no event is missed after the client sees the stream open
+	subscription := h.hub.Subscribe(topics...)
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if err := realtime.WriteSSE(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxTrendingLimit caps the number of trending tags returned
+const maxTrendingLimit = 50
+
+// TagHandler handles hashtag-related HTTP requests
+type TagHandler struct {
+	BaseHandler
+	tagService *services.TagService
+	validator  *utils.Validator
+}
+
+// NewTagHandler creates a new TagHandler
+func NewTagHandler(tagService *services.TagService, validator *utils.Validator) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		validator:  validator,
+	}
+}
+
+// GetTagPosts handles getting the posts with a hashtag
+func (h *TagHandler) GetTagPosts(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse tag from path
+	tag := chi.URLParam(r, "tag")
+
+	// Parse query parameters
+	page := h.ParseQueryInt(r, "page", 1)
+	limit := h.ParseQueryInt(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	offset := (page - 1) * limit
+
+	// Get posts
+	posts, err := h.tagService.GetPostsByTag(tag, userID, limit, offset)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return posts
+	utils.SendJSONResponse(w, http.StatusOK, posts)
+}
+
+// GetTrendingTags handles getting the trending hashtags
+func (h *TagHandler) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	hours := h.ParseQueryInt(r, "hours", 24)
+	limit := h.ParseQueryInt(r, "limit", 10)
+	if limit <= 0 || limit > maxTrendingLimit {
+		limit = maxTrendingLimit
+	}
+
+	// Get trending tags
+	tags, err := h.tagService.GetTrendingTags(time.Duration(hours)*time.Hour, limit)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Return tags
+	utils.SendJSONResponse(w, http.StatusOK, tags)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// uploadsDir is the directory uploaded files are stored in; their URLs start with /uploads/
+const uploadsDir = "uploads"
+
+// uploadError is an upload failure with the HTTP status and message to send to the client
+type uploadError struct {
+	status  int
+	message string
+}
+
+// Error implements the error interface
+func (e *uploadError) Error() string {
+	return e.message
+}
+
+// saveUploadedImage saves the image in a field of an already parsed multipart form
+// under uploads/<dir> and returns its public URL. Failures are returned as *uploadError.
+func saveUploadedImage(r *http.Request, field, dir string) (string, error) {
+	// Get the file from the form
+	file, handler, err := r.FormFile(field)
+	if err != nil {
+		label := strings.ReplaceAll(field, "_", " ")
+		return "", &uploadError{http.StatusBadRequest, fmt.Sprintf("Unable to get %s from form", label)}
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			// Log the error or handle it appropriately in a real application
+			_ = closeErr
+		}
+	}()
+
+	return saveImageFile(file, handler, dir)
+}
+
+// saveUploadedImages saves every image in a field of an already parsed multipart form
+// under uploads/<dir> and returns their public URLs. A field without files gives no URLs.
+// If any image cannot be saved, the ones already saved are removed again.
+func saveUploadedImages(r *http.Request, field, dir string, max int) ([]string, error) {
+	urls := []string{}
+	if !hasUploadedFile(r, field) {
+		return urls, nil
+	}
+
+	headers := r.MultipartForm.File[field]
+	if len(headers) > max {
+		return nil, &uploadError{http.StatusBadRequest, fmt.Sprintf("At most %d images are allowed", max)}
+	}
+
+	for _, header := range headers {
+		url, err := saveImageFileHeader(header, dir)
+		if err != nil {
+			removeUploadedFiles(urls)
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+
+	return urls, nil
+}
+
+// saveImageFileHeader opens a file of a multipart form and saves it with saveImageFile
+func saveImageFileHeader(header *multipart.FileHeader, dir string) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", &uploadError{http.StatusBadRequest, "Unable to read uploaded file"}
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			// Log the error or handle it appropriately in a real application
+			_ = closeErr
+		}
+	}()
+
+	return saveImageFile(file, header, dir)
+}
+
+// saveImageFile checks that an uploaded file is an image and saves it under uploads/<dir>
+func saveImageFile(file io.Reader, handler *multipart.FileHeader, dir string) (string, error) {
+	// Validate file type (only allow images)
+	if !isValidImageType(handler.Header.Get("Content-Type")) {
+		return "", &uploadError{http.StatusBadRequest, "Invalid file type. Only images are allowed"}
+	}
+
+	// Generate a unique filename
+	filename := generateUniqueFilename(handler.Filename)
+
+	// Create uploads directory if it doesn't exist
+	err := os.MkdirAll(filepath.Join(uploadsDir, dir), os.ModePerm)
+	if err != nil {
+		return "", &uploadError{http.StatusInternalServerError, "Unable to create uploads directory"}
+	}
+
+	// Create the file
+	dst, err := os.Create(filepath.Join(uploadsDir, dir, filename))
+	if err != nil {
+		return "", &uploadError{http.StatusInternalServerError, "Unable to create file"}
+	}
+	defer func() {
+		if closeErr := dst.Close(); closeErr != nil {
+			// Log the error or handle it appropriately in a real application
+			_ = closeErr
+		}
+	}()
+
+	// Copy the uploaded file to the destination
+	_, err = io.Copy(dst, file)
+	if err != nil {
+		return "", &uploadError{http.StatusInternalServerError, "Unable to save file"}
+	}
+
+	return "/" + uploadsDir + "/" + dir + "/" + filename, nil
+}
+
+// removeUploadedFile deletes a file saved by saveUploadedImage, e.g. when the
+// database could not be updated to reference it
+func removeUploadedFile(url string) {
+	if err := utils.RemoveUploadedFile(url); err != nil {
+		// Log the cleanup error, but don't send it to the client
+		log.Printf("WARN: Failed to remove uploaded file %s during cleanup: %v", url, err)
+	}
+}
+
+// removeUploadedFiles deletes several files saved by saveUploadedImage or saveUploadedImages
+func removeUploadedFiles(urls []string) {
+	for _, url := range urls {
+		removeUploadedFile(url)
+	}
+}
+
+// hasUploadedFile reports whether a field of an already parsed multipart form holds a file
+func hasUploadedFile(r *http.Request, field string) bool {
+	return r.MultipartForm != nil && len(r.MultipartForm.File[field]) > 0
+}
+
+// sendUploadError sends the response for an error returned by saveUploadedImage
+func sendUploadError(w http.ResponseWriter, err error) {
+	var uploadErr *uploadError
+	if errors.As(err, &uploadErr) {
+		utils.SendJSONResponse(w, uploadErr.status, map[string]string{"error": uploadErr.message})
+		return
+	}
+	utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
+
+// isValidImageType checks if the content type is a valid image type
+func isValidImageType(contentType string) bool {
+	validTypes := []string{
+		"image/jpeg",
+		"image/jpg",
+		"image/png",
+		"image/gif",
+		"image/webp",
+	}
+
+	for _, validType := range validTypes {
+		if contentType == validType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateUniqueFilename generates a unique filename by adding a timestamp prefix
+func generateUniqueFilename(originalFilename string) string {
+	// Get file extension
+	ext := filepath.Ext(originalFilename)
+
+	// Generate timestamp
+	timestamp := time.Now().UnixNano()
+
+	// Generate random string
+	randStr := generateRandomString(8)
+
+	// Combine to create unique filename
+	return fmt.Sprintf("%d_%s%s", timestamp, randStr, ext)
+}
+
+// generateRandomString generates a random string of specified length
+func generateRandomString(length int) string {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	b := make([]byte, length)
+	for i := range b {
+		// Generate a random index
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			// Fallback to a simple approach if crypto/rand fails
+			b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
+			continue
+		}
+		b[i] = charset[n.Int64()]
+	}
+
+	return string(b)
+}
@@ -1,19 +1,13 @@
 package handlers
 
 import (
-	"crypto/rand"
-	"fmt"
-	"io"
-	"log"
-	"math/big"
+	"errors"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/gocli/social_api/internal/models"
 	"github.com/gocli/social_api/internal/services"
 	"github.com/gocli/social_api/internal/utils"
 )
@@ -35,6 +29,13 @@ func NewUserHandler(userService *services.UserService, validator *utils.Validato
 
 // GetUserProfile handles getting a user's public profile
 func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
 	// Parse user ID from path
 	userIDStr := chi.URLParam(r, "userId")
 	userID, err := strconv.Atoi(userIDStr)
@@ -43,15 +44,38 @@ func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get user
-	user, err := h.userService.GetUserByID(userID)
+	// Get the profile as seen by the viewer
+	profile, err := h.userService.GetUserProfile(userID, viewerID)
 	if err != nil {
 		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "User not found"})
 		return
 	}
 
-	// Return user data
-	utils.SendJSONResponse(w, http.StatusOK, user)
+	// Return profile data
+	utils.SendJSONResponse(w, http.StatusOK, profile)
+}
+
+// GetUserProfileByUsername handles getting a user's profile by username
+func (h *UserHandler) GetUserProfileByUsername(w http.ResponseWriter, r *http.Request) {
+	// Get viewer ID from context
+	viewerID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse username from path
+	username := chi.URLParam(r, "username")
+
+	// Get the profile as seen by the viewer
+	profile, err := h.userService.GetUserProfileByUsername(username, viewerID)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	// Return profile data
+	utils.SendJSONResponse(w, http.StatusOK, profile)
 }
 
 // SearchUsers handles searching for users
@@ -60,6 +84,12 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	limit := h.ParseQueryInt(r, "limit", 20)
 	offset := h.ParseQueryInt(r, "offset", 0)
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
 
 	// Search users
 	users, err := h.userService.SearchUsers(query, limit, offset)
@@ -103,8 +133,12 @@ func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Bio      string `json:"bio" validate:"max=500"`
+		Location string `json:"location" validate:"max=100"`
+		Website  string `json:"website" validate:"omitempty,website,max=255"`
+		Pronouns string `json:"pronouns" validate:"max=50"`
 	}
 
 	if err := h.DecodeJSONBody(w, r, &req); err != nil {
@@ -112,6 +146,12 @@ func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
 	// Get current user
 	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
@@ -122,6 +162,10 @@ func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 	// Update user fields
 	user.Name = req.Name
 	user.Email = req.Email
+	user.Bio = req.Bio
+	user.Location = req.Location
+	user.Website = req.Website
+	user.Pronouns = req.Pronouns
 
 	// Update user
 	if err := h.userService.UpdateUser(user); err != nil {
@@ -144,8 +188,12 @@ func (h *UserHandler) PartialUpdateMe(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req struct {
-		Name  *string `json:"name"`
-		Email *string `json:"email"`
+		Name     *string `json:"name"`
+		Email    *string `json:"email"`
+		Bio      *string `json:"bio" validate:"omitempty,max=500"`
+		Location *string `json:"location" validate:"omitempty,max=100"`
+		Website  *string `json:"website" validate:"omitempty,website,max=255"`
+		Pronouns *string `json:"pronouns" validate:"omitempty,max=50"`
 	}
 
 	if err := h.DecodeJSONBody(w, r, &req); err != nil {
@@ -153,6 +201,12 @@ func (h *UserHandler) PartialUpdateMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
+
 	// Get current user
 	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
@@ -167,6 +221,18 @@ func (h *UserHandler) PartialUpdateMe(w http.ResponseWriter, r *http.Request) {
 	if req.Email != nil {
 		user.Email = *req.Email
 	}
+	if req.Bio != nil {
+		user.Bio = *req.Bio
+	}
+	if req.Location != nil {
+		user.Location = *req.Location
+	}
+	if req.Website != nil {
+		user.Website = *req.Website
+	}
+	if req.Pronouns != nil {
+		user.Pronouns = *req.Pronouns
+	}
 
 	// Update user
 	if err := h.userService.UpdateUser(user); err != nil {
@@ -178,8 +244,8 @@ func (h *UserHandler) PartialUpdateMe(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSONResponse(w, http.StatusOK, user)
 }
 
-// UploadProfilePicture handles uploading a profile picture
-func (h *UserHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Request) {
+// ChangeMyUsername handles changing the current user's username
+func (h *UserHandler) ChangeMyUsername(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
 	userID, err := h.GetUserIDFromContext(r)
 	if err != nil {
@@ -187,131 +253,155 @@ func (h *UserHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Parse multipart form with max memory of 10MB
-	err = r.ParseMultipartForm(10 << 20)
-	if err != nil {
-		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Unable to parse form"})
+	// Parse request body
+	var req struct {
+		Username string `json:"username" validate:"required,username"`
+	}
+
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
 		return
 	}
 
-	// Get the file from the form
-	file, handler, err := r.FormFile("profile_picture")
-	if err != nil {
-		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Unable to get profile picture from form"})
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
 		return
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			// Log the error or handle it appropriately in a real application
-			_ = closeErr
-		}
-	}()
 
-	// Validate file type (only allow images)
-	if !isValidImageType(handler.Header.Get("Content-Type")) {
-		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid file type. Only images are allowed"})
+	// Change username
+	user, err := h.userService.ChangeUsername(userID, req.Username)
+	if errors.Is(err, services.ErrUsernameTaken) {
+		utils.SendJSONResponse(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Generate a unique filename
-	filename := generateUniqueFilename(handler.Filename)
+	// Return updated user
+	utils.SendJSONResponse(w, http.StatusOK, user)
+}
 
-	// Create uploads directory if it doesn't exist
-	err = os.MkdirAll("uploads/profile-pictures", os.ModePerm)
+// GetMyUsernameHistory handles getting the current user's username changes
+func (h *UserHandler) GetMyUsernameHistory(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
 	if err != nil {
-		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Unable to create uploads directory"})
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 		return
 	}
 
-	// Create the file
-	dst, err := os.Create("uploads/profile-pictures/" + filename)
+	// Get username history
+	changes, err := h.userService.GetUsernameHistory(userID)
 	if err != nil {
-		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Unable to create file"})
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
-	defer func() {
-		if closeErr := dst.Close(); closeErr != nil {
-			// Log the error or handle it appropriately in a real application
-			_ = closeErr
-		}
-	}()
 
-	// Copy the uploaded file to the destination
-	_, err = io.Copy(dst, file)
+	// Return username history
+	utils.SendJSONResponse(w, http.StatusOK, changes)
+}
+
+// GetMyPrivacySettings handles getting the current user's profile privacy settings
+func (h *UserHandler) GetMyPrivacySettings(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
 	if err != nil {
-		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Unable to save file"})
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 		return
 	}
 
-	// Update user's profile picture URL in the database
-	profilePictureURL := "/uploads/profile-pictures/" + filename
-	err = h.userService.UpdateProfilePictureURL(userID, profilePictureURL)
+	// Get privacy settings
+	settings, err := h.userService.GetPrivacySettings(userID)
 	if err != nil {
-		// Try to delete the uploaded file since we couldn't update the database
-		if err := os.Remove("uploads/profile-pictures/" + filename); err != nil {
-			// Log the cleanup error, but don't send it to the client
-			log.Printf("WARN: Failed to remove uploaded file %s during cleanup: %v", filename, err)
-		}
-		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Unable to update profile picture"})
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Return success response
-	utils.SendJSONResponse(w, http.StatusOK, map[string]string{
-		"message":             "Profile picture uploaded successfully",
-		"profile_picture_url": profilePictureURL,
-	})
+	// Return privacy settings
+	utils.SendJSONResponse(w, http.StatusOK, settings)
 }
 
-// isValidImageType checks if the content type is a valid image type
-func isValidImageType(contentType string) bool {
-	validTypes := []string{
-		"image/jpeg",
-		"image/jpg",
-		"image/png",
-		"image/gif",
-		"image/webp",
+// UpdateMyPrivacySettings handles updating the current user's profile privacy settings
+func (h *UserHandler) UpdateMyPrivacySettings(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
 	}
 
-	for _, validType := range validTypes {
-		if contentType == validType {
-			return true
-		}
+	// Parse request body
+	var req struct {
+		EmailVisibility      string `json:"email_visibility" validate:"required,oneof=public friends only_me"`
+		BirthDateVisibility  string `json:"birth_date_visibility" validate:"required,oneof=public friends only_me"`
+		FriendListVisibility string `json:"friend_list_visibility" validate:"required,oneof=public friends only_me"`
 	}
 
-	return false
-}
-
-// generateUniqueFilename generates a unique filename by adding a timestamp prefix
-func generateUniqueFilename(originalFilename string) string {
-	// Get file extension
-	ext := filepath.Ext(originalFilename)
+	if err := h.DecodeJSONBody(w, r, &req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		return
+	}
 
-	// Generate timestamp
-	timestamp := time.Now().UnixNano()
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]interface{}{"error": "Validation failed", "details": err})
+		return
+	}
 
-	// Generate random string
-	randStr := generateRandomString(8)
+	// Update privacy settings
+	settings := &models.PrivacySettings{
+		UserID:               userID,
+		EmailVisibility:      req.EmailVisibility,
+		BirthDateVisibility:  req.BirthDateVisibility,
+		FriendListVisibility: req.FriendListVisibility,
+	}
+	if err := h.userService.UpdatePrivacySettings(settings); err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
 
-	// Combine to create unique filename
-	return fmt.Sprintf("%d_%s%s", timestamp, randStr, ext)
+	// Return updated privacy settings
+	utils.SendJSONResponse(w, http.StatusOK, settings)
 }
 
-// generateRandomString generates a random string of specified length
-func generateRandomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+// UploadProfilePicture handles uploading a profile picture
+func (h *UserHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, err := h.GetUserIDFromContext(r)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse multipart form with max memory of 10MB
+	err = r.ParseMultipartForm(10 << 20)
+	if err != nil {
+		utils.SendJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "Unable to parse form"})
+		return
+	}
 
-	b := make([]byte, length)
-	for i := range b {
-		// Generate a random index
-		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
-			// Fallback to a simple approach if crypto/rand fails
-			b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-			continue
-		}
-		b[i] = charset[n.Int64()]
+	// Save the uploaded image
+	profilePictureURL, err := saveUploadedImage(r, "profile_picture", "profile-pictures")
+	if err != nil {
+		sendUploadError(w, err)
+		return
 	}
 
-	return string(b)
+	// Update user's profile picture URL in the database
+	err = h.userService.UpdateProfilePictureURL(userID, profilePictureURL)
+	if err != nil {
+		// Try to delete the uploaded file since we couldn't update the database
+		removeUploadedFile(profilePictureURL)
+		utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Unable to update profile picture"})
+		return
+	}
+
+	// Return success response
+	utils.SendJSONResponse(w, http.StatusOK, map[string]string{
+		"message":             "Profile picture uploaded successfully",
+		"profile_picture_url": profilePictureURL,
+	})
 }
@@ -19,6 +19,8 @@ import (
 	"github.com/gocli/social_api/internal/database"
 	"github.com/gocli/social_api/internal/handlers"
 	authmiddleware "github.com/gocli/social_api/internal/middleware"
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/realtime"
 	"github.com/gocli/social_api/internal/repositories"
 	"github.com/gocli/social_api/internal/services"
 	"github.com/gocli/social_api/internal/utils"
@@ -64,15 +66,62 @@ func TestUserLifecycle(t *testing.T) {
 	albumRepo := repositories.NewAlbumRepository(db)
 	likeRepo := repositories.NewLikeRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
+	revisionRepo := repositories.NewRevisionRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	pollRepo := repositories.NewPollRepository(db)
+	draftRepo := repositories.NewDraftRepository(db)
+	storyRepo := repositories.NewStoryRepository(db)
+	muteRepo := repositories.NewMuteRepository(db)
+	savedRepo := repositories.NewSavedRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+	suspensionRepo := repositories.NewSuspensionRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	mentionRepo := repositories.NewMentionRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	blockRepo := repositories.NewBlockRepository(db)
+	conversationRepo := repositories.NewConversationRepository(db)
+	resourceRepo := repositories.NewResourceRepository(db)
+
+	// Initialize real-time event delivery
+	hub := realtime.NewHub()
+	broker := realtime.NewLocalBroker(hub)
 
 	// Initialize services
+	visibilityService := services.NewVisibilityService(friendRepo, postRepo, albumRepo, commentRepo, userRepo)
+	resourceRegistry := services.NewResourceRegistry(resourceRepo, visibilityService)
+	notificationService := services.NewNotificationService(notificationRepo, visibilityService, broker)
 	authService := services.NewAuthService(authRepo, userRepo, config.JWTSecret, config.RefreshTokenSecret)
-	userService := services.NewUserService(userRepo)
-	friendService := services.NewFriendService(friendRepo, userRepo)
-	postService := services.NewPostService(postRepo)
-	albumService := services.NewAlbumService(albumRepo)
-	likeService := services.NewLikeService(likeRepo)
-	commentService := services.NewCommentService(commentRepo, userRepo)
+	userService := services.NewUserService(userRepo, visibilityService)
+	friendService := services.NewFriendService(friendRepo, userRepo, blockRepo, visibilityService, notificationService, broker)
+	mentionService := services.NewMentionService(mentionRepo, userRepo, visibilityService, notificationService)
+	pollService := services.NewPollService(pollRepo, postRepo, visibilityService)
+	linkPreviewService := services.NewLinkPreviewService()
+	roleService := services.NewRoleService(userRepo)
+	feedRanker := services.NewWeightedRanker(services.DefaultRankingWeights())
+	contentPipeline, err := services.NewContentPipelineFromRules(services.DefaultContentFilterRules(), map[string]services.DuplicateCounter{
+		models.ResourceTypePosts:    postRepo,
+		models.ResourceTypeComments: commentRepo,
+	})
+	require.NoError(t, err)
+	contentFilterService := services.NewContentFilterService(contentPipeline, userRepo, reportRepo)
+	postService := services.NewPostService(postRepo, tagRepo, revisionRepo, attachmentRepo, visibilityService, mentionService,
+		notificationService, pollService, linkPreviewService, resourceRegistry, roleService, feedRanker, contentFilterService)
+	draftService := services.NewDraftService(draftRepo, postService)
+	storyService := services.NewStoryService(storyRepo, blockRepo, visibilityService)
+	muteService := services.NewMuteService(muteRepo, userRepo, visibilityService)
+	savedService := services.NewSavedService(savedRepo, albumRepo, postService, visibilityService, resourceRegistry)
+	suspensionService := services.NewSuspensionService(suspensionRepo, roleService)
+	moderationService := services.NewModerationService(reportRepo, userRepo, postRepo, commentRepo, albumRepo,
+		visibilityService, resourceRegistry, suspensionService)
+	albumService := services.NewAlbumService(albumRepo, resourceRegistry, visibilityService)
+	likeService := services.NewLikeService(likeRepo, resourceRegistry, notificationService)
+	commentService := services.NewCommentService(commentRepo, userRepo, revisionRepo, resourceRegistry, visibilityService, mentionService, notificationService, roleService, broker, contentFilterService)
+	searchService := services.NewSearchService(userRepo, postRepo)
+	tagService := services.NewTagService(tagRepo)
+	blockService := services.NewBlockService(blockRepo, userRepo)
+	messageService := services.NewMessageService(conversationRepo, friendRepo, blockRepo, broker)
+	adminService := services.NewAdminService(userRepo, moderationService, postService, commentService, visibilityService,
+		suspensionService)
 
 	// Initialize validator
 	validator := utils.NewValidator()
@@ -82,9 +131,22 @@ func TestUserLifecycle(t *testing.T) {
 	userHandler := handlers.NewUserHandler(userService, validator)
 	friendHandler := handlers.NewFriendHandler(friendService, validator)
 	postHandler := handlers.NewPostHandler(postService, validator)
+	pollHandler := handlers.NewPollHandler(pollService, validator)
+	draftHandler := handlers.NewDraftHandler(draftService, validator)
+	storyHandler := handlers.NewStoryHandler(storyService, validator)
+	muteHandler := handlers.NewMuteHandler(muteService, validator)
+	savedHandler := handlers.NewSavedHandler(savedService, validator)
+	moderationHandler := handlers.NewModerationHandler(moderationService, validator)
+	adminHandler := handlers.NewAdminHandler(adminService, validator)
 	albumHandler := handlers.NewAlbumHandler(albumService, validator)
 	likeHandler := handlers.NewLikeHandler(likeService, validator)
 	commentHandler := handlers.NewCommentHandler(commentService, validator)
+	searchHandler := handlers.NewSearchHandler(searchService, validator)
+	tagHandler := handlers.NewTagHandler(tagService, validator)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, validator)
+	streamHandler := handlers.NewStreamHandler(hub, visibilityService)
+	blockHandler := handlers.NewBlockHandler(blockService, validator)
+	messageHandler := handlers.NewMessageHandler(messageService, validator)
 
 	// Setup routes
 	router := chi.NewRouter()
@@ -114,11 +176,16 @@ func TestUserLifecycle(t *testing.T) {
 
 		// User routes
 		r.Get("/api/v1/users/{userId}", userHandler.GetUserProfile)
+		r.Get("/api/v1/users/@{username}", userHandler.GetUserProfileByUsername)
 		r.Get("/api/v1/users/search", userHandler.SearchUsers)
 		r.Get("/api/v1/me", userHandler.GetMe)
 		r.Put("/api/v1/me", userHandler.UpdateMe)
 		r.Patch("/api/v1/me", userHandler.PartialUpdateMe)
 		r.Post("/api/v1/me/profile-picture", userHandler.UploadProfilePicture)
+		r.Put("/api/v1/me/username", userHandler.ChangeMyUsername)
+		r.Get("/api/v1/me/username-history", userHandler.GetMyUsernameHistory)
+		r.Get("/api/v1/me/privacy", userHandler.GetMyPrivacySettings)
+		r.Put("/api/v1/me/privacy", userHandler.UpdateMyPrivacySettings)
 
 		// Friend routes
 		r.Get("/api/v1/users/{userId}/friends", friendHandler.GetUserFriends)
@@ -133,9 +200,90 @@ func TestUserLifecycle(t *testing.T) {
 		r.Get("/api/v1/feed", postHandler.GetFeed)
 		r.Get("/api/v1/users/{userId}/posts", postHandler.GetUserPosts)
 		r.Get("/api/v1/posts/{postId}", postHandler.GetPost)
+		r.Post("/api/v1/posts/{postId}/share", postHandler.SharePost)
+		r.Post("/api/v1/posts/{postId}/poll/votes", pollHandler.Vote)
+		r.Delete("/api/v1/posts/{postId}/poll/votes", pollHandler.RetractVote)
 		r.Put("/api/v1/posts/{postId}", postHandler.UpdatePost)
+		r.Get("/api/v1/posts/{postId}/revisions", postHandler.GetPostRevisions)
+		r.Put("/api/v1/posts/{postId}/comment-permission", postHandler.UpdateCommentPermission)
 		r.Delete("/api/v1/posts/{postId}", postHandler.DeletePost)
 
+		// Draft and scheduled post routes
+		r.Post("/api/v1/drafts", draftHandler.CreateDraft)
+		r.Get("/api/v1/drafts", draftHandler.GetDrafts)
+		r.Get("/api/v1/drafts/{draftId}", draftHandler.GetDraft)
+		r.Put("/api/v1/drafts/{draftId}", draftHandler.UpdateDraft)
+		r.Delete("/api/v1/drafts/{draftId}", draftHandler.DeleteDraft)
+		r.Put("/api/v1/drafts/{draftId}/schedule", draftHandler.ScheduleDraft)
+		r.Delete("/api/v1/drafts/{draftId}/schedule", draftHandler.UnscheduleDraft)
+		r.Post("/api/v1/drafts/{draftId}/publish", draftHandler.PublishDraft)
+
+		// Story routes
+		r.Post("/api/v1/stories", storyHandler.CreateStory)
+		r.Get("/api/v1/stories/tray", storyHandler.GetTray)
+		r.Get("/api/v1/stories/{storyId}", storyHandler.ViewStory)
+		r.Delete("/api/v1/stories/{storyId}", storyHandler.DeleteStory)
+		r.Get("/api/v1/stories/{storyId}/viewers", storyHandler.GetStoryViewers)
+		r.Get("/api/v1/me/stories", storyHandler.GetMyStories)
+		r.Get("/api/v1/users/{userId}/stories", storyHandler.GetUserStories)
+
+		// Feed mute routes
+		r.Get("/api/v1/me/mutes", muteHandler.GetMyMutedUsers)
+		r.Post("/api/v1/users/{userId}/mute", muteHandler.MuteUser)
+		r.Post("/api/v1/users/{userId}/snooze", muteHandler.SnoozeUser)
+		r.Delete("/api/v1/users/{userId}/mute", muteHandler.UnmuteUser)
+		r.Post("/api/v1/posts/{postId}/hide", muteHandler.HidePost)
+		r.Delete("/api/v1/posts/{postId}/hide", muteHandler.UnhidePost)
+
+		// Saved item and collection routes
+		r.Get("/api/v1/me/saved", savedHandler.GetMySavedItems)
+		r.Get("/api/v1/me/collections", savedHandler.GetMyCollections)
+		r.Post("/api/v1/me/collections", savedHandler.CreateCollection)
+		r.Put("/api/v1/collections/{collectionId}", savedHandler.RenameCollection)
+		r.Delete("/api/v1/collections/{collectionId}", savedHandler.DeleteCollection)
+		r.Get("/api/v1/collections/{collectionId}/items", savedHandler.GetCollectionItems)
+		r.Put("/api/v1/{resourceType}/{resourceId}/save", savedHandler.SaveItem)
+		r.Delete("/api/v1/{resourceType}/{resourceId}/save", savedHandler.UnsaveItem)
+
+		// Report routes
+		r.Post("/api/v1/reports", moderationHandler.ReportContent)
+		r.Get("/api/v1/me/moderation-actions", moderationHandler.GetMyModerationActions)
+
+		// Moderation routes
+		r.Group(func(r chi.Router) {
+			r.Use(authmiddleware.RequirePermission(roleService, models.PermissionReviewReports))
+			r.Get("/api/v1/moderation/queue", moderationHandler.GetQueue)
+			r.Get("/api/v1/moderation/reports", moderationHandler.GetReports)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionModerateContent)).
+				Post("/api/v1/moderation/actions", moderationHandler.TakeAction)
+			r.Get("/api/v1/moderation/actions", moderationHandler.GetActions)
+		})
+
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(authmiddleware.RequireRole(models.RoleAdmin))
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionViewUsers)).
+				Get("/api/v1/admin/users", adminHandler.FindUser)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionViewUsers)).
+				Get("/api/v1/admin/users/{userId}", adminHandler.GetUser)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionManageRoles)).
+				Put("/api/v1/admin/users/{userId}/role", adminHandler.SetRole)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionSuspendUsers)).
+				Post("/api/v1/admin/users/{userId}/suspend", adminHandler.SuspendUser)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionSuspendUsers)).
+				Delete("/api/v1/admin/users/{userId}/suspend", adminHandler.LiftSuspension)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionSuspendUsers)).
+				Post("/api/v1/admin/users/{userId}/restrict", adminHandler.RestrictUser)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionSuspendUsers)).
+				Delete("/api/v1/admin/users/{userId}/restrict", adminHandler.LiftRestriction)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionSuspendUsers)).
+				Get("/api/v1/admin/users/{userId}/suspensions", adminHandler.GetSuspensionHistory)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionRemoveContent)).
+				Delete("/api/v1/admin/posts/{postId}", adminHandler.RemovePost)
+			r.With(authmiddleware.RequirePermission(roleService, models.PermissionRemoveContent)).
+				Delete("/api/v1/admin/comments/{commentId}", adminHandler.RemoveComment)
+		})
+
 		// Album routes
 		r.Post("/api/v1/me/albums", albumHandler.CreateAlbum)
 		r.Get("/api/v1/users/{userId}/albums", albumHandler.GetUserAlbums)
@@ -147,11 +295,55 @@ func TestUserLifecycle(t *testing.T) {
 		r.Post("/api/v1/{resourceType}/{resourceId}/like", likeHandler.LikeResource)
 		r.Delete("/api/v1/{resourceType}/{resourceId}/like", likeHandler.UnlikeResource)
 		r.Get("/api/v1/{resourceType}/{resourceId}/likes", likeHandler.GetLikesForResource)
+		r.Put("/api/v1/{resourceType}/{resourceId}/reaction", likeHandler.ReactToResource)
+		r.Delete("/api/v1/{resourceType}/{resourceId}/reaction", likeHandler.UnlikeResource)
 
 		// Comment routes
 		r.Post("/api/v1/{resourceType}/{resourceId}/comments", commentHandler.CreateComment)
 		r.Get("/api/v1/{resourceType}/{resourceId}/comments", commentHandler.GetCommentsForResource)
+		r.Get("/api/v1/comments/{commentId}/replies", commentHandler.GetReplies)
+		r.Get("/api/v1/comments/{commentId}/revisions", commentHandler.GetCommentRevisions)
+		r.Patch("/api/v1/comments/{commentId}", commentHandler.UpdateComment)
+		r.Post("/api/v1/comments/{commentId}/hide", commentHandler.HideComment)
+		r.Delete("/api/v1/comments/{commentId}/hide", commentHandler.UnhideComment)
+		r.Post("/api/v1/comments/{commentId}/pin", commentHandler.PinComment)
+		r.Delete("/api/v1/comments/{commentId}/pin", commentHandler.UnpinComment)
 		r.Delete("/api/v1/comments/{commentId}", commentHandler.DeleteComment)
+
+		// Search routes
+		r.Get("/api/v1/search", searchHandler.Search)
+
+		// Tag routes
+		r.Get("/api/v1/tags/trending", tagHandler.GetTrendingTags)
+		r.Get("/api/v1/tags/{tag}/posts", tagHandler.GetTagPosts)
+
+		// Notification routes
+		r.Get("/api/v1/notifications", notificationHandler.GetNotifications)
+		r.Get("/api/v1/notifications/unread-count", notificationHandler.GetUnreadCount)
+		r.Post("/api/v1/notifications/{notificationId}/read", notificationHandler.MarkRead)
+		r.Post("/api/v1/notifications/read-all", notificationHandler.MarkAllRead)
+		r.Get("/api/v1/me/notification-preferences", notificationHandler.GetPreferences)
+		r.Put("/api/v1/me/notification-preferences", notificationHandler.UpdatePreferences)
+
+		// Real-time routes
+		r.Get("/api/v1/stream", streamHandler.Stream)
+
+		// Block routes
+		r.Get("/api/v1/me/blocks", blockHandler.GetMyBlockedUsers)
+		r.Post("/api/v1/users/{userId}/block", blockHandler.BlockUser)
+		r.Delete("/api/v1/users/{userId}/block", blockHandler.UnblockUser)
+
+		// Messaging routes
+		r.Post("/api/v1/conversations", messageHandler.StartConversation)
+		r.Get("/api/v1/conversations", messageHandler.GetConversations)
+		r.Get("/api/v1/conversations/unread-count", messageHandler.GetUnreadCount)
+		r.Get("/api/v1/conversations/{conversationId}", messageHandler.GetConversation)
+		r.Get("/api/v1/conversations/{conversationId}/messages", messageHandler.GetMessages)
+		r.Post("/api/v1/conversations/{conversationId}/messages", messageHandler.SendMessage)
+		r.Post("/api/v1/conversations/{conversationId}/read", messageHandler.MarkRead)
+		r.Post("/api/v1/conversations/{conversationId}/members", messageHandler.AddMember)
+		r.Put("/api/v1/conversations/{conversationId}/mute", messageHandler.MuteConversation)
+		r.Post("/api/v1/conversations/{conversationId}/leave", messageHandler.LeaveConversation)
 	})
 
 	// Create test server
@@ -3,10 +3,13 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/gocli/social_api/internal/models"
 	"github.com/gocli/social_api/internal/services"
 	"github.com/gocli/social_api/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
@@ -18,6 +21,9 @@ type ContextKey string
 // UserContextKey is the key for storing user ID in context
 const UserContextKey ContextKey = "userID"
 
+// RoleContextKey is the key for storing the role claimed by the token in context
+const RoleContextKey ContextKey = "role"
+
 // AuthMiddleware is a middleware that verifies JWT tokens
 func AuthMiddleware(authService *services.AuthService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -71,8 +77,28 @@ func AuthMiddleware(authService *services.AuthService) func(http.Handler) http.H
 				return
 			}
 
-			// Add user ID to context
+			// Reject tokens of suspended or deleted accounts
+			if err := authService.CheckAccount(int(userID)); err != nil {
+				switch {
+				case errors.Is(err, services.ErrAccountSuspended):
+					utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+				case errors.Is(err, sql.ErrNoRows):
+					utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+				default:
+					utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to check account"})
+				}
+				return
+			}
+
+			// Tokens issued before roles existed carry no role claim
+			role, _ := claims["role"].(string)
+			if role == "" {
+				role = models.RoleUser
+			}
+
+			// Add user ID and role to context
 			ctx := context.WithValue(r.Context(), UserContextKey, int(userID))
+			ctx = context.WithValue(ctx, RoleContextKey, role)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/services"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// RequireRole is a middleware that only lets through users whose token claims one of the given roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Get role from context
+			role, _ := r.Context().Value(RoleContextKey).(string)
+
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": "Forbidden"})
+		})
+	}
+}
+
+// RequirePermission is a middleware that only lets through users whose role grants a permission.
+// It must run after AuthMiddleware. The role claimed by the token is trusted for most permissions,
+// but sensitive ones are checked against the user's current role so a demoted user loses them at once.
+func RequirePermission(roleService *services.RoleService, permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Get user ID and role from context
+			userID, ok := r.Context().Value(UserContextKey).(int)
+			if !ok {
+				utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+				return
+			}
+			role, _ := r.Context().Value(RoleContextKey).(string)
+
+			// Check the role claimed by the token
+			if !models.RoleHasPermission(role, permission) {
+				utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": "Forbidden"})
+				return
+			}
+
+			// Revalidate sensitive permissions against the current role
+			if models.IsSensitivePermission(permission) {
+				allowed, err := roleService.HasPermission(userID, permission)
+				if errors.Is(err, sql.ErrNoRows) {
+					utils.SendJSONResponse(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+					return
+				}
+				if err != nil {
+					utils.SendJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to check permission"})
+					return
+				}
+				if !allowed {
+					utils.SendJSONResponse(w, http.StatusForbidden, map[string]string{"error": "Forbidden"})
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
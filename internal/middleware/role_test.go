@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/services"
+)
+
+// serveAs runs handler for a request from userID whose token claims role
+func serveAs(handler http.Handler, userID int, role string) int {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := context.WithValue(req.Context(), UserContextKey, userID)
+	if role != "" {
+		ctx = context.WithValue(ctx, RoleContextKey, role)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(ctx))
+	return rec.Code
+}
+
+func TestRequireRole(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := RequireRole(models.RoleModerator, models.RoleAdmin)(ok)
+
+	assert.Equal(t, http.StatusOK, serveAs(handler, 1, models.RoleAdmin))
+	assert.Equal(t, http.StatusOK, serveAs(handler, 1, models.RoleModerator))
+	assert.Equal(t, http.StatusForbidden, serveAs(handler, 1, models.RoleUser))
+	assert.Equal(t, http.StatusForbidden, serveAs(handler, 1, ""))
+}
+
+func TestRequirePermissionChecksTokenRole(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	// Reviewing reports is not sensitive, so the role in the token is trusted and no lookup is needed
+	handler := RequirePermission(nil, models.PermissionReviewReports)(ok)
+
+	assert.Equal(t, http.StatusOK, serveAs(handler, 1, models.RoleModerator))
+	assert.Equal(t, http.StatusForbidden, serveAs(handler, 1, models.RoleUser))
+
+	// A sensitive permission the token's role does not grant is refused before any lookup
+	handler = RequirePermission(nil, models.PermissionManageRoles)(ok)
+	assert.Equal(t, http.StatusForbidden, serveAs(handler, 1, models.RoleModerator))
+}
+
+func TestRequirePermissionLookupFailure(t *testing.T) {
+	// A closed database makes the role lookup fail, which is a server fault rather than a bad token
+	db, err := sql.Open("postgres", "sslmode=disable")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	roleService := services.NewRoleService(repositories.NewUserRepository(db))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := RequirePermission(roleService, models.PermissionSuspendUsers)(ok)
+	assert.Equal(t, http.StatusInternalServerError, serveAs(handler, 1, models.RoleModerator))
+}
+
+func TestRolePermissions(t *testing.T) {
+	assert.False(t, models.RoleHasPermission(models.RoleUser, models.PermissionReviewReports))
+	assert.True(t, models.RoleHasPermission(models.RoleModerator, models.PermissionSuspendUsers))
+	assert.False(t, models.RoleHasPermission(models.RoleModerator, models.PermissionManageRoles))
+	assert.True(t, models.RoleHasPermission(models.RoleAdmin, models.PermissionManageRoles))
+	assert.True(t, models.IsSensitivePermission(models.PermissionRemoveContent))
+	assert.False(t, models.IsSensitivePermission(models.PermissionViewUsers))
+	assert.True(t, models.RoleOutranks(models.RoleModerator, models.RoleUser))
+	assert.True(t, models.RoleOutranks(models.RoleAdmin, models.RoleModerator))
+	assert.False(t, models.RoleOutranks(models.RoleModerator, models.RoleModerator))
+	assert.False(t, models.RoleOutranks(models.RoleModerator, models.RoleAdmin))
+	assert.False(t, models.RoleOutranks(models.RoleAdmin, models.RoleAdmin))
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Attachment types of a post
+const (
+	AttachmentTypeImage = "image"
+	AttachmentTypeLink  = "link"
+)
+
+// PostAttachment represents an uploaded image or a link preview attached to a post
+type PostAttachment struct {
+	ID          int       `json:"id" db:"id"`
+	PostID      int       `json:"post_id" db:"post_id"`
+	Type        string    `json:"type" db:"type"` // image, link
+	Position    int       `json:"position" db:"position"`
+	URL         string    `json:"url" db:"url"` // The uploaded image or the linked page
+	Title       string    `json:"title,omitempty" db:"title"`
+	Description string    `json:"description,omitempty" db:"description"`
+	ImageURL    string    `json:"image_url,omitempty" db:"image_url"` // Preview image of a link
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
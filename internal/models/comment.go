@@ -1,12 +1,34 @@
 // Package models provides data structures for the social media API
 package models
 
+import "time"
+
 // Comment represents a comment on a resource (post, photo, etc.)
 type Comment struct {
 	BaseModel
-	UserID       int         `json:"user_id" db:"user_id"`
-	ResourceType string      `json:"resource_type" db:"resource_type"`
-	ResourceID   int         `json:"resource_id" db:"resource_id"`
-	Content      string      `json:"content" db:"content"`
-	User         *UserPublic `json:"user,omitempty"` // Populated when fetching comments
+	UserID          int         `json:"user_id" db:"user_id"`
+	ResourceType    string      `json:"resource_type" db:"resource_type"`
+	ResourceID      int         `json:"resource_id" db:"resource_id"`
+	ParentCommentID *int        `json:"parent_comment_id,omitempty" db:"parent_comment_id"` // Set for replies
+	Content         string      `json:"content" db:"content"`
+	Edited          bool        `json:"edited"`
+	EditedAt        *time.Time  `json:"edited_at,omitempty" db:"edited_at"`
+	DeletedAt       *time.Time  `json:"-" db:"deleted_at"`
+	IsDeleted       bool        `json:"is_deleted,omitempty"` // Tombstone of a deleted comment that still has replies
+	HiddenAt        *time.Time  `json:"-" db:"hidden_at"`
+	IsHidden        bool        `json:"is_hidden,omitempty"`            // Hidden by the owner of the commented content or a site moderator
+	ModeratedAt     *time.Time  `json:"-" db:"moderated_at"`            // Hidden by a site moderator
+	HeldAt          *time.Time  `json:"held_at,omitempty" db:"held_at"` // Held for review by the content filter
+	PinnedAt        *time.Time  `json:"-" db:"pinned_at"`
+	IsPinned        bool        `json:"is_pinned,omitempty"`
+	User            *UserPublic `json:"user,omitempty"`     // Populated when fetching comments
+	Mentions        []*Mention  `json:"mentions,omitempty"` // Users mentioned in the content
+	ReplyCount      int         `json:"reply_count"`        // Populated for top-level comments
+	Replies         []*Comment  `json:"replies,omitempty"`  // First few replies, populated for top-level comments
+}
+
+// CommentReplyPage represents a page of replies to a comment
+type CommentReplyPage struct {
+	Replies    []*Comment `json:"replies"`
+	TotalCount int        `json:"total_count"`
 }
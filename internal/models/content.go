@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ContentSubmission is a new or edited post or comment as seen by the content filter
+type ContentSubmission struct {
+	UserID         int
+	ResourceType   string // posts or comments
+	Content        string
+	LinkURLs       []string  // Links in the content and the post's link attachment
+	AuthorJoinedAt time.Time // When the author's account was created
+	SubmittedAt    time.Time
+}
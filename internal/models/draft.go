@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Statuses of a post draft
+const (
+	DraftStatusDraft     = "draft"
+	DraftStatusScheduled = "scheduled" // Published automatically at PublishAt
+)
+
+// PostDraft represents a post that is saved but not published yet.
+// Its images have already been uploaded; its link preview is fetched when it is published.
+type PostDraft struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	Content     string     `json:"content" db:"content"`
+	Privacy     string     `json:"privacy" db:"privacy"`
+	LinkURL     string     `json:"link_url,omitempty" db:"link_url"`
+	ImageURLs   []string   `json:"image_urls" db:"image_urls"`
+	Poll        *Poll      `json:"poll,omitempty" db:"poll"`
+	Status      string     `json:"status" db:"status"` // draft, scheduled
+	PublishAt   *time.Time `json:"publish_at,omitempty" db:"publish_at"`
+	LockedUntil *time.Time `json:"-" db:"locked_until"`                  // Set while the draft is being published
+	LastError   string     `json:"last_error,omitempty" db:"last_error"` // Why the last attempt to publish failed
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+// Feed modes
+const (
+	FeedModeLatest = "latest" // Newest posts first
+	FeedModeTop    = "top"    // Posts ordered by the feed ranker
+)
+
+// Fan-out states of a post
+const (
+	FanoutStatusPending = "pending" // Not yet copied to timelines
+	FanoutStatusDone    = "done"    // Copied to the timelines of the author and their friends
+	FanoutStatusSkipped = "skipped" // The author has too many friends; read from posts instead
+)
+
+// Content types of a post, as seen by the feed ranker
+const (
+	PostContentText  = "text"
+	PostContentImage = "image"
+	PostContentLink  = "link"
+	PostContentPoll  = "poll"
+	PostContentShare = "share"
+)
+
+// FeedFilter narrows down the posts of a feed
+type FeedFilter struct {
+	ContentType string // Only posts of this content type, if set
+	AuthorID    int    // Only posts by this user, if set; shows them even if they are muted
+}
+
+// FeedCandidate is a post that may appear in a ranked feed, with the signals used to score it
+type FeedCandidate struct {
+	Post          *PostWithUser
+	ContentType   string  // text, image, link, poll, share
+	ReactionCount int     // Reactions on the post
+	CommentCount  int     // Visible comments on the post
+	Affinity      int     // The viewer's recent reactions and comments on the author's posts
+	Score         float64 // Set by the ranker
+}
@@ -5,10 +5,50 @@ import (
 	"time"
 )
 
-// Like represents a like on a resource (post, photo, etc.)
+// Reaction types
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionHaha  = "haha"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+// ReactionTypes lists every reaction type, in the order they are presented
+var ReactionTypes = []string{
+	ReactionLike,
+	ReactionLove,
+	ReactionHaha,
+	ReactionWow,
+	ReactionSad,
+	ReactionAngry,
+}
+
+// IsValidReactionType reports whether t is a known reaction type
+func IsValidReactionType(t string) bool {
+	for _, reactionType := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// Like represents a user's reaction to a resource (post, photo, etc.).
+// A plain like is a reaction of type "like"; a user has at most one reaction per resource.
 type Like struct {
-	UserID       int       `json:"user_id" db:"user_id"`
-	ResourceType string    `json:"resource_type" db:"resource_type"`
-	ResourceID   int       `json:"resource_id" db:"resource_id"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UserID       int         `json:"user_id" db:"user_id"`
+	ResourceType string      `json:"resource_type" db:"resource_type"`
+	ResourceID   int         `json:"resource_id" db:"resource_id"`
+	Type         string      `json:"type" db:"type"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	User         *UserPublic `json:"user,omitempty"` // Populated when listing reactions
+}
+
+// ReactionSummary represents the reactions to a resource: the count of each type and a page of reactors
+type ReactionSummary struct {
+	Total     int            `json:"total"`
+	Counts    map[string]int `json:"counts"`
+	Reactions []*Like        `json:"reactions"`
 }
@@ -0,0 +1,10 @@
+package models
+
+// Mention represents an @username mention of a user in a post or comment.
+// Offset and Length are measured in characters and cover the whole mention, including the '@'.
+type Mention struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+}
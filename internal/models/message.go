@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// Conversation represents a one-to-one or group conversation as seen by one of its members
+type Conversation struct {
+	BaseModel
+	IsGroup       bool                  `json:"is_group" db:"is_group"`
+	Title         string                `json:"title,omitempty" db:"title"`
+	CreatedBy     int                   `json:"created_by" db:"created_by"`
+	LastMessageAt *time.Time            `json:"last_message_at,omitempty" db:"last_message_at"`
+	Members       []*ConversationMember `json:"members,omitempty"`
+	LastMessage   *Message              `json:"last_message,omitempty"`
+	UnreadCount   int                   `json:"unread_count"` // For the member viewing the conversation
+	Muted         bool                  `json:"muted"`        // For the member viewing the conversation
+}
+
+// ConversationMember represents a user's membership in a conversation.
+// LastReadMessageID doubles as the member's read receipt.
+type ConversationMember struct {
+	ConversationID    int         `json:"-" db:"conversation_id"`
+	UserID            int         `json:"user_id" db:"user_id"`
+	User              *UserPublic `json:"user,omitempty"`
+	JoinedAt          time.Time   `json:"joined_at" db:"joined_at"`
+	LeftAt            *time.Time  `json:"-" db:"left_at"`
+	Muted             bool        `json:"-" db:"muted"`
+	LastReadMessageID int         `json:"last_read_message_id" db:"last_read_message_id"`
+}
+
+// Message represents a message in a conversation
+type Message struct {
+	ID             int       `json:"id" db:"id"`
+	ConversationID int       `json:"conversation_id" db:"conversation_id"`
+	SenderID       int       `json:"sender_id" db:"sender_id"`
+	Content        string    `json:"content" db:"content"`
+	AttachmentURL  string    `json:"attachment_url,omitempty" db:"attachment_url"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// MessagePage represents a page of messages, newest first. NextCursor is the
+// "before" value for the next page and is zero on the last page.
+type MessagePage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor int        `json:"next_cursor,omitempty"`
+}
+
+// BlockedUser represents a user blocked by the current user
+type BlockedUser struct {
+	User      *UserPublic `json:"user"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SnoozeDuration is how long a snoozed user's posts are left out of the feed
+const SnoozeDuration = 30 * 24 * time.Hour
+
+// FeedMute represents a user whose posts are left out of the current user's feed
+type FeedMute struct {
+	User      *UserPublic `json:"user"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"` // Set for snoozes
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types
+const (
+	NotificationTypeLike          = "like"
+	NotificationTypeReaction      = "reaction"
+	NotificationTypeComment       = "comment"
+	NotificationTypeReply         = "reply"
+	NotificationTypeMention       = "mention"
+	NotificationTypeShare         = "share"
+	NotificationTypeFriendRequest = "friend_request"
+	NotificationTypeFriendAccept  = "friend_accept"
+)
+
+// NotificationTypes lists every notification type, in the order preferences are presented
+var NotificationTypes = []string{
+	NotificationTypeLike,
+	NotificationTypeReaction,
+	NotificationTypeComment,
+	NotificationTypeReply,
+	NotificationTypeMention,
+	NotificationTypeShare,
+	NotificationTypeFriendRequest,
+	NotificationTypeFriendAccept,
+}
+
+// IsValidNotificationType reports whether t is a known notification type
+func IsValidNotificationType(t string) bool {
+	for _, notificationType := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification represents an in-app notification. Unread notifications of the
+// same type about the same resource are aggregated, so one notification can
+// stand for several actors.
+type Notification struct {
+	BaseModel
+	UserID       int         `json:"user_id" db:"user_id"`
+	Type         string      `json:"type" db:"type"`
+	ResourceType string      `json:"resource_type" db:"resource_type"`
+	ResourceID   int         `json:"resource_id" db:"resource_id"`
+	ActorCount   int         `json:"actor_count" db:"actor_count"`
+	LastActor    *UserPublic `json:"last_actor,omitempty"` // The most recent actor
+	Message      string      `json:"message"`              // e.g. "Ana and 12 others liked your post"
+	ReadAt       *time.Time  `json:"read_at,omitempty" db:"read_at"`
+}
+
+// NotificationList represents a page of notifications with the user's unread count
+type NotificationList struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int             `json:"unread_count"`
+}
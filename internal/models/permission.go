@@ -0,0 +1,68 @@
+package models
+
+// Permissions granted by roles
+const (
+	PermissionReviewReports   = "reports:review"   // See the report queue and the audit trail
+	PermissionModerateContent = "content:moderate" // Hide reported content and moderate any comments
+	PermissionSuspendUsers    = "users:suspend"    // Suspend users and lift suspensions
+	PermissionViewUsers       = "users:view"       // Look up any account, including its email and role
+	PermissionRemoveContent   = "content:remove"   // Delete any post or comment
+	PermissionManageRoles     = "roles:manage"     // Change the roles of other users
+)
+
+// rolePermissions lists the permissions each role grants. Users have none.
+var rolePermissions = map[string][]string{
+	RoleModerator: {
+		PermissionReviewReports,
+		PermissionModerateContent,
+		PermissionSuspendUsers,
+	},
+	RoleAdmin: {
+		PermissionReviewReports,
+		PermissionModerateContent,
+		PermissionSuspendUsers,
+		PermissionViewUsers,
+		PermissionRemoveContent,
+		PermissionManageRoles,
+	},
+}
+
+// sensitivePermissions are re-checked against the user's current role rather than trusting the role in their token
+var sensitivePermissions = map[string]bool{
+	PermissionModerateContent: true,
+	PermissionSuspendUsers:    true,
+	PermissionRemoveContent:   true,
+	PermissionManageRoles:     true,
+}
+
+// roleRanks orders the roles; staff can only suspend or restrict users of a lower rank
+var roleRanks = map[string]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// RoleOutranks reports whether role ranks above other
+func RoleOutranks(role, other string) bool {
+	return roleRanks[role] > roleRanks[other]
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleModerator || role == RoleAdmin
+}
+
+// RoleHasPermission reports whether a role grants a permission
+func RoleHasPermission(role, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitivePermission reports whether a permission must be checked against the user's current role
+func IsSensitivePermission(permission string) bool {
+	return sensitivePermissions[permission]
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Who can see the results of a poll besides the author of the post
+const (
+	PollResultsAlways     = "always"
+	PollResultsAfterVote  = "after_vote"
+	PollResultsAfterClose = "after_close"
+)
+
+// Poll represents a poll attached to a post. Vote counts are only set when
+// the viewer may see the results.
+type Poll struct {
+	ID                int           `json:"id" db:"id"`
+	PostID            int           `json:"post_id" db:"post_id"`
+	MultipleChoice    bool          `json:"multiple_choice" db:"multiple_choice"`
+	ResultsVisibility string        `json:"results_visibility" db:"results_visibility"` // always, after_vote, after_close
+	ClosesAt          *time.Time    `json:"closes_at,omitempty" db:"closes_at"`
+	Closed            bool          `json:"closed"`
+	ResultsVisible    bool          `json:"results_visible"`
+	VoterCount        *int          `json:"voter_count,omitempty"`
+	ViewerVotes       []int         `json:"viewer_votes"` // IDs of the options the viewer voted for
+	Options           []*PollOption `json:"options"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+}
+
+// PollOption represents one of the answers of a poll
+type PollOption struct {
+	ID        int    `json:"id" db:"id"`
+	Position  int    `json:"position" db:"position"`
+	Text      string `json:"text" db:"text"`
+	VoteCount *int   `json:"vote_count,omitempty"`
+}
+
+// IsClosedAt reports whether the poll no longer accepts votes at the given time
+func (p *Poll) IsClosedAt(now time.Time) bool {
+	return p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+}
@@ -1,11 +1,35 @@
 package models
 
+import "time"
+
+// Comment permissions of a post
+const (
+	CommentPermissionEveryone = "everyone"
+	CommentPermissionFriends  = "friends"
+	CommentPermissionNobody   = "nobody" // Only the author of the post can comment
+)
+
 // Post represents a post created by a user
 type Post struct {
 	BaseModel
-	UserID  int    `json:"user_id" db:"user_id"`
-	Content string `json:"content" db:"content"`
-	Privacy string `json:"privacy" db:"privacy"` // public, friends, only_me
+	UserID                int               `json:"user_id" db:"user_id"`
+	Content               string            `json:"content" db:"content"`
+	Privacy               string            `json:"privacy" db:"privacy"`                         // public, friends, only_me
+	CommentPermission     string            `json:"comment_permission" db:"comment_permission"`   // everyone, friends, nobody
+	SharedPostID          *int              `json:"shared_post_id,omitempty" db:"shared_post_id"` // Set when the post shares another post
+	SharedPost            *PostWithUser     `json:"shared_post,omitempty"`                        // The shared post, if the viewer can still see it
+	SharedPostUnavailable bool              `json:"shared_post_unavailable,omitempty"`            // The shared post was deleted or is no longer visible
+	ShareCount            int               `json:"share_count"`
+	DraftID               *int              `json:"-" db:"draft_id"` // The draft the post was published from
+	Edited                bool              `json:"edited"`
+	EditedAt              *time.Time        `json:"edited_at,omitempty" db:"edited_at"`
+	ModeratedAt           *time.Time        `json:"moderated_at,omitempty" db:"moderated_at"` // Hidden by a site moderator
+	HeldAt                *time.Time        `json:"held_at,omitempty" db:"held_at"`           // Held for review by the content filter
+	HeldPrivacy           string            `json:"-" db:"held_privacy"`                      // Privacy a held post gets once released
+	Tags                  []string          `json:"tags,omitempty"`                           // Hashtags extracted from the content
+	Mentions              []*Mention        `json:"mentions,omitempty"`                       // Users mentioned in the content
+	Attachments           []*PostAttachment `json:"attachments,omitempty"`                    // Images and link previews
+	Poll                  *Poll             `json:"poll,omitempty"`
 }
 
 // PostWithUser represents a post with user information
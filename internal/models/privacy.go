@@ -0,0 +1,36 @@
+package models
+
+// Privacy levels shared by posts, albums and profile fields
+const (
+	PrivacyPublic  = "public"
+	PrivacyFriends = "friends"
+	PrivacyOnlyMe  = "only_me"
+)
+
+// IsValidPrivacy reports whether level is one of the known privacy levels
+func IsValidPrivacy(level string) bool {
+	switch level {
+	case PrivacyPublic, PrivacyFriends, PrivacyOnlyMe:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsWiderPrivacy reports whether level reaches a wider audience than other.
+// An empty level is treated as public.
+func IsWiderPrivacy(level, other string) bool {
+	return privacyRank(level) > privacyRank(other)
+}
+
+// privacyRank orders privacy levels from the narrowest to the widest audience
+func privacyRank(level string) int {
+	switch level {
+	case PrivacyOnlyMe:
+		return 0
+	case PrivacyFriends:
+		return 1
+	default:
+		return 2
+	}
+}
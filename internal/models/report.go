@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// ResourceTypeUsers identifies user profiles, which can be reported
+const ResourceTypeUsers = "users"
+
+// Report reasons
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHateSpeech     = "hate_speech"
+	ReportReasonViolence       = "violence"
+	ReportReasonNudity         = "nudity"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonOther          = "other"
+)
+
+// IsValidReportReason reports whether reason is one of the report reasons
+func IsValidReportReason(reason string) bool {
+	switch reason {
+	case ReportReasonSpam, ReportReasonHarassment, ReportReasonHateSpeech, ReportReasonViolence,
+		ReportReasonNudity, ReportReasonMisinformation, ReportReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusActioned  = "actioned"  // A moderator acted on the reported content
+	ReportStatusDismissed = "dismissed" // A moderator found nothing wrong
+)
+
+// Moderation actions
+const (
+	ModerationActionHideContent = "hide_content"
+	ModerationActionWarnUser    = "warn_user"
+	ModerationActionSuspendUser = "suspend_user"
+	ModerationActionDismiss     = "dismiss"
+
+	// Taken by admins outside the report queue
+	ModerationActionRemoveContent   = "remove_content"
+	ModerationActionRestrictUser    = "restrict_user"
+	ModerationActionLiftSuspension  = "lift_suspension"
+	ModerationActionLiftRestriction = "lift_restriction"
+	ModerationActionChangeRole      = "change_role"
+
+	// Taken by the content filter, without a moderator
+	ModerationActionHoldContent = "hold_content"
+)
+
+// Report represents a user's report of a post, comment, photo or profile, or the content filter's
+// report of a post or comment it held for review
+type Report struct {
+	ID           int        `json:"id" db:"id"`
+	ReporterID   *int       `json:"reporter_id,omitempty" db:"reporter_id"` // Not set for reports by the content filter
+	ResourceType string     `json:"resource_type" db:"resource_type"`       // posts, comments, photos, users
+	ResourceID   int        `json:"resource_id" db:"resource_id"`
+	Reason       string     `json:"reason" db:"reason"`
+	Details      string     `json:"details,omitempty" db:"details"`
+	Status       string     `json:"status" db:"status"` // open, actioned, dismissed
+	ReviewedBy   *int       `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ReportQueueItem represents reported content awaiting review, with its open reports summarized
+type ReportQueueItem struct {
+	ResourceType     string         `json:"resource_type"`
+	ResourceID       int            `json:"resource_id"`
+	ReportCount      int            `json:"report_count"`
+	Reasons          map[string]int `json:"reasons"` // Number of open reports per reason
+	FirstReportedAt  time.Time      `json:"first_reported_at"`
+	LatestReportedAt time.Time      `json:"latest_reported_at"`
+}
+
+// ModerationAction records a moderator's decision on reported content
+type ModerationAction struct {
+	ID              int        `json:"id" db:"id"`
+	ModeratorID     *int       `json:"moderator_id,omitempty" db:"moderator_id"` // Left out when shown to the target user
+	Action          string     `json:"action" db:"action"`                       // hide_content, warn_user, suspend_user, dismiss, ...
+	ResourceType    string     `json:"resource_type" db:"resource_type"`
+	ResourceID      int        `json:"resource_id" db:"resource_id"`
+	TargetUserID    *int       `json:"target_user_id,omitempty" db:"target_user_id"` // The author of the content
+	Note            string     `json:"note,omitempty" db:"note"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty" db:"expires_at"` // End of a suspension
+	ReportsResolved int        `json:"reports_resolved" db:"reports_resolved"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Resource types used in routes and in the resource_type columns of likes, comments,
+// mentions and notifications
+const (
+	ResourceTypePosts          = "posts"
+	ResourceTypePhotos         = "photos"
+	ResourceTypeAlbums         = "albums"
+	ResourceTypeComments       = "comments"
+	ResourceTypeFriendRequests = "friend_requests"
+)
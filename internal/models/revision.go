@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ContentRevision represents the content a post or comment had before it was edited
+type ContentRevision struct {
+	ID           int       `json:"id" db:"id"`
+	ResourceType string    `json:"resource_type" db:"resource_type"`
+	ResourceID   int       `json:"resource_id" db:"resource_id"`
+	Content      string    `json:"content" db:"content"`
+	EditedBy     *int      `json:"edited_by,omitempty" db:"edited_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"` // When the content was replaced
+}
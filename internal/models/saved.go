@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Collection represents a named group of a user's saved items
+type Collection struct {
+	BaseModel
+	UserID    int    `json:"user_id" db:"user_id"`
+	Name      string `json:"name" db:"name"`
+	ItemCount int    `json:"item_count"`
+}
+
+// SavedItem represents a post or photo a user saved for later
+type SavedItem struct {
+	ID           int           `json:"id" db:"id"`
+	UserID       int           `json:"user_id" db:"user_id"`
+	ResourceType string        `json:"resource_type" db:"resource_type"` // posts, photos
+	ResourceID   int           `json:"resource_id" db:"resource_id"`
+	CollectionID *int          `json:"collection_id,omitempty" db:"collection_id"`
+	Post         *PostWithUser `json:"post,omitempty"`
+	Photo        *Photo        `json:"photo,omitempty"`
+	Unavailable  bool          `json:"unavailable,omitempty"` // The item was deleted or is no longer visible
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+}
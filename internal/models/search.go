@@ -0,0 +1,15 @@
+package models
+
+// Search result types accepted by the unified search endpoint
+const (
+	SearchTypeAll   = "all"
+	SearchTypeUsers = "users"
+	SearchTypePosts = "posts"
+)
+
+// SearchResults holds the results of a unified search
+type SearchResults struct {
+	Query string          `json:"query"`
+	Users []*UserPublic   `json:"users,omitempty"`
+	Posts []*PostWithUser `json:"posts,omitempty"`
+}
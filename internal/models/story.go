@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Story types
+const (
+	StoryTypeImage = "image"
+	StoryTypeText  = "text"
+)
+
+// StoryLifetime is how long a story is visible after it is posted
+const StoryLifetime = 24 * time.Hour
+
+// Story represents an image or text story that expires after StoryLifetime
+type Story struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Type      string    `json:"type" db:"type"`       // image, text
+	Content   string    `json:"content" db:"content"` // The text, or the caption of an image
+	MediaURL  string    `json:"media_url,omitempty" db:"media_url"`
+	Privacy   string    `json:"privacy" db:"privacy"` // public, friends, only_me
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Seen      bool      `json:"seen"`                 // Whether the viewer has seen the story
+	ViewCount *int      `json:"view_count,omitempty"` // Only shown to the author
+}
+
+// StoryTrayItem represents a user with active stories in the stories tray
+type StoryTrayItem struct {
+	User          *UserPublic `json:"user"`
+	StoryCount    int         `json:"story_count"`
+	LatestStoryAt time.Time   `json:"latest_story_at"`
+	HasUnseen     bool        `json:"has_unseen"`
+}
+
+// StoryView represents a user who has seen a story
+type StoryView struct {
+	Viewer   *UserPublic `json:"viewer"`
+	ViewedAt time.Time   `json:"viewed_at"`
+}
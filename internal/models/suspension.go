@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Suspension kinds
+const (
+	SuspensionKindSuspension  = "suspension"  // The user cannot log in or use their access tokens
+	SuspensionKindRestriction = "restriction" // The user's content is visible only to themselves
+)
+
+// Suspension statuses
+const (
+	SuspensionStatusActive  = "active"
+	SuspensionStatusExpired = "expired"
+	SuspensionStatusLifted  = "lifted"
+)
+
+// Suspension represents a suspension or restriction of a user's account for a period
+type Suspension struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Kind      string     `json:"kind" db:"kind"`     // suspension, restriction
+	Reason    string     `json:"reason" db:"reason"` // One of the report reasons
+	Note      string     `json:"note,omitempty" db:"note"`
+	CreatedBy *int       `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	LiftedAt  *time.Time `json:"lifted_at,omitempty" db:"lifted_at"`
+	LiftedBy  *int       `json:"lifted_by,omitempty" db:"lifted_by"`
+	Status    string     `json:"status" db:"-"` // active, expired, lifted
+}
+
+// StatusAt returns whether the suspension is active, expired or lifted at the given time
+func (s *Suspension) StatusAt(now time.Time) string {
+	switch {
+	case s.LiftedAt != nil:
+		return SuspensionStatusLifted
+	case !s.ExpiresAt.After(now):
+		return SuspensionStatusExpired
+	default:
+		return SuspensionStatusActive
+	}
+}
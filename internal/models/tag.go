@@ -0,0 +1,8 @@
+package models
+
+// TrendingTag represents a hashtag and how much it was used in a time window
+type TrendingTag struct {
+	Name        string `json:"name"`
+	PostCount   int    `json:"post_count"`
+	AuthorCount int    `json:"author_count"`
+}
@@ -4,22 +4,113 @@ import (
 	"time"
 )
 
+// User roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator" // Reviews reports and moderates content
+	RoleAdmin     = "admin"     // Manages users and roles on top of what a moderator does
+)
+
 // User represents a user in the system
 type User struct {
 	BaseModel
-	Name              string    `json:"name" db:"name"`
-	Email             string    `json:"email" db:"email"`
-	Password          string    `json:"-" db:"password"` // Never serialize password to JSON
-	BirthDate         time.Time `json:"birth_date" db:"birth_date"`
-	ProfilePictureURL string    `json:"profile_picture_url,omitempty" db:"profile_picture_url"`
-	CoverPhotoURL     string    `json:"cover_photo_url,omitempty" db:"cover_photo_url"`
+	Name              string     `json:"name" db:"name"`
+	Email             string     `json:"email" db:"email"`
+	Username          string     `json:"username,omitempty" db:"username"`
+	Password          string     `json:"-" db:"password"` // Never serialize password to JSON
+	BirthDate         time.Time  `json:"birth_date" db:"birth_date"`
+	ProfilePictureURL string     `json:"profile_picture_url,omitempty" db:"profile_picture_url"`
+	CoverPhotoURL     string     `json:"cover_photo_url,omitempty" db:"cover_photo_url"`
+	Bio               string     `json:"bio,omitempty" db:"bio"`
+	Location          string     `json:"location,omitempty" db:"location"`
+	Website           string     `json:"website,omitempty" db:"website"`
+	Pronouns          string     `json:"pronouns,omitempty" db:"pronouns"`
+	Role              string     `json:"role" db:"role"` // user, moderator, admin
+	SuspendedUntil    *time.Time `json:"-" db:"suspended_until"`
+	RestrictedUntil   *time.Time `json:"-" db:"restricted_until"`
+}
+
+// IsSuspendedAt reports whether the user is suspended at the given time
+func (u *User) IsSuspendedAt(now time.Time) bool {
+	return u.SuspendedUntil != nil && u.SuspendedUntil.After(now)
+}
+
+// IsRestrictedAt reports whether the user's content is visible only to themselves at the given time
+func (u *User) IsRestrictedAt(now time.Time) bool {
+	return u.RestrictedUntil != nil && u.RestrictedUntil.After(now)
+}
+
+// AdminUser is a user's account as seen by an admin, including their suspension and restriction
+type AdminUser struct {
+	*User
+	SuspendedUntil  *time.Time `json:"suspended_until,omitempty"`
+	RestrictedUntil *time.Time `json:"restricted_until,omitempty"`
+}
+
+// Public returns the public projection of the user
+func (u *User) Public() *UserPublic {
+	return &UserPublic{
+		ID:                u.ID,
+		Name:              u.Name,
+		Username:          u.Username,
+		ProfilePictureURL: u.ProfilePictureURL,
+		CoverPhotoURL:     u.CoverPhotoURL,
+		Bio:               u.Bio,
+		Location:          u.Location,
+		Website:           u.Website,
+		Pronouns:          u.Pronouns,
+		CreatedAt:         u.CreatedAt,
+	}
 }
 
 // UserPublic represents a user's public profile
 type UserPublic struct {
 	ID                int       `json:"id"`
 	Name              string    `json:"name"`
+	Username          string    `json:"username,omitempty"`
 	ProfilePictureURL string    `json:"profile_picture_url,omitempty"`
 	CoverPhotoURL     string    `json:"cover_photo_url,omitempty"`
+	Bio               string    `json:"bio,omitempty"`
+	Location          string    `json:"location,omitempty"`
+	Website           string    `json:"website,omitempty"`
+	Pronouns          string    `json:"pronouns,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
 }
+
+// UserProfile represents a user's profile as seen by another user.
+// Fields hidden by the owner's privacy settings are left empty.
+type UserProfile struct {
+	UserPublic
+	Email          string     `json:"email,omitempty"`
+	BirthDate      *time.Time `json:"birth_date,omitempty"`
+	IsFriend       bool       `json:"is_friend"`
+	CanViewFriends bool       `json:"can_view_friends"`
+}
+
+// PrivacySettings controls who can see each of a user's profile fields
+type PrivacySettings struct {
+	UserID               int       `json:"user_id" db:"user_id"`
+	EmailVisibility      string    `json:"email_visibility" db:"email_visibility"`             // public, friends, only_me
+	BirthDateVisibility  string    `json:"birth_date_visibility" db:"birth_date_visibility"`   // public, friends, only_me
+	FriendListVisibility string    `json:"friend_list_visibility" db:"friend_list_visibility"` // public, friends, only_me
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultPrivacySettings returns the settings applied to users who never changed them
+func DefaultPrivacySettings(userID int) *PrivacySettings {
+	return &PrivacySettings{
+		UserID:               userID,
+		EmailVisibility:      PrivacyOnlyMe,
+		BirthDateVisibility:  PrivacyFriends,
+		FriendListVisibility: PrivacyPublic,
+	}
+}
+
+// UsernameChange records a change of a user's username
+type UsernameChange struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	OldUsername string    `json:"old_username,omitempty" db:"old_username"`
+	NewUsername string    `json:"new_username" db:"new_username"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
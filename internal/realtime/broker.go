@@ -0,0 +1,142 @@
+package realtime
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Broker types selectable through configuration
+const (
+	BrokerLocal    = "local"
+	BrokerPostgres = "postgres"
+)
+
+// notifyChannel is the PostgreSQL channel events are published on
+const notifyChannel = "realtime_events"
+
+// maxNotifyPayload is the largest event PostgreSQL accepts as a NOTIFY payload
+const maxNotifyPayload = 8000
+
+// Publisher publishes events to every subscriber of their topic
+type Publisher interface {
+	Publish(event Event) error
+}
+
+// Broker publishes events and delivers them to a Hub
+type Broker interface {
+	Publisher
+	Close() error
+}
+
+// LocalBroker delivers events to subscribers in this process only
+type LocalBroker struct {
+	hub *Hub
+}
+
+// NewLocalBroker creates a new LocalBroker
+func NewLocalBroker(hub *Hub) *LocalBroker {
+	return &LocalBroker{hub: hub}
+}
+
+// Publish delivers an event to the local hub
+func (b *LocalBroker) Publish(event Event) error {
+	b.hub.Deliver(event)
+	return nil
+}
+
+// Close does nothing; a LocalBroker holds no resources
+func (b *LocalBroker) Close() error {
+	return nil
+}
+
+// PostgresBroker publishes events with PostgreSQL NOTIFY and delivers the events
+// it LISTENs to, so subscribers connected to any replica receive every event
+type PostgresBroker struct {
+	db       *sql.DB
+	hub      *Hub
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+// NewPostgresBroker creates a new PostgresBroker. The listener needs a dedicated
+// connection, which is opened with databaseURL.
+func NewPostgresBroker(db *sql.DB, databaseURL string, hub *Hub) (*PostgresBroker, error) {
+	listener := pq.NewListener(databaseURL, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("WARN: Realtime listener error: %v", err)
+		}
+	})
+
+	if err := listener.Listen(notifyChannel); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to listen for realtime events: %w", err)
+	}
+
+	broker := &PostgresBroker{
+		db:       db,
+		hub:      hub,
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	go broker.listen()
+
+	return broker, nil
+}
+
+// Publish sends an event to every replica, including this one
+func (b *PostgresBroker) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("event is too large to publish: %d bytes", len(payload))
+	}
+
+	if _, err := b.db.Exec(`SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// Close stops listening for events
+func (b *PostgresBroker) Close() error {
+	close(b.done)
+	return b.listener.Close()
+}
+
+// listen delivers the events received from PostgreSQL to the local hub
+func (b *PostgresBroker) listen() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case notification := <-b.listener.Notify:
+			if notification == nil {
+				// The connection was re-established; events sent meanwhile are lost
+				log.Printf("WARN: Realtime listener reconnected, some events may have been missed")
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				log.Printf("WARN: Failed to decode realtime event: %v", err)
+				continue
+			}
+			b.hub.Deliver(event)
+		case <-time.After(90 * time.Second):
+			// Check the connection is still alive when the channel is quiet
+			go func() {
+				if err := b.listener.Ping(); err != nil {
+					log.Printf("WARN: Realtime listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package realtime provides real-time event delivery to connected clients
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Event types pushed to clients
+const (
+	EventNotification          = "notification"
+	EventCommentCreated        = "comment.created"
+	EventFriendRequestReceived = "friend_request.received"
+	EventFriendRequestAccepted = "friend_request.accepted"
+	EventMessageCreated        = "message.created"
+	EventConversationRead      = "conversation.read"
+)
+
+// Event is a message delivered to every subscriber of its topic
+type Event struct {
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// NewEvent creates an event for a topic with data encoded as JSON
+func NewEvent(topic, eventType string, data interface{}) (Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode event data: %w", err)
+	}
+	return Event{Topic: topic, Type: eventType, Data: encoded}, nil
+}
+
+// UserTopic returns the topic of events addressed to a single user
+func UserTopic(userID int) string {
+	return fmt.Sprintf("users:%d", userID)
+}
+
+// ResourceTopic returns the topic of events about a resource, such as new comments on a post
+func ResourceTopic(resourceType string, resourceID int) string {
+	return fmt.Sprintf("%s:%d", resourceType, resourceID)
+}
+
+// WriteSSE writes an event in the Server-Sent Events wire format
+func WriteSSE(w io.Writer, event Event) error {
+	// Data is compact JSON, which never contains a raw newline
+	data := strings.ReplaceAll(string(event.Data), "\n", "")
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
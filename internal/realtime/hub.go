@@ -0,0 +1,111 @@
+package realtime
+
+import (
+	"sync"
+)
+
+// subscriptionBuffer is how many events a subscriber can fall behind before events are dropped
+const subscriptionBuffer = 32
+
+// Hub fans events out to the subscribers in this process
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewHub creates a new Hub
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: map[string]map[*Subscription]struct{}{},
+	}
+}
+
+// Subscription receives the events published to its topics until it is closed
+type Subscription struct {
+	hub    *Hub
+	topics []string
+	events chan Event
+	once   sync.Once
+}
+
+// Subscribe creates a subscription to one or more topics
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	subscription := &Subscription{
+		hub:    h,
+		topics: topics,
+		events: make(chan Event, subscriptionBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// A closed hub hands out subscriptions that are already closed
+	if h.closed {
+		subscription.once.Do(func() { close(subscription.events) })
+		return subscription
+	}
+
+	for _, topic := range topics {
+		if h.subscribers[topic] == nil {
+			h.subscribers[topic] = map[*Subscription]struct{}{}
+		}
+		h.subscribers[topic][subscription] = struct{}{}
+	}
+
+	return subscription
+}
+
+// Deliver hands an event to the local subscribers of its topic. It never blocks:
+// a subscriber that is too far behind misses the event.
+func (h *Hub) Deliver(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for subscription := range h.subscribers[event.Topic] {
+		select {
+		case subscription.events <- event:
+		default:
+		}
+	}
+}
+
+// Close closes every subscription, ending the streams that read from them. Subscriptions made
+// afterwards are closed straight away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	subscriptions := map[*Subscription]struct{}{}
+	for _, topicSubscribers := range h.subscribers {
+		for subscription := range topicSubscribers {
+			subscriptions[subscription] = struct{}{}
+		}
+	}
+	h.subscribers = map[string]map[*Subscription]struct{}{}
+	h.closed = true
+	h.mu.Unlock()
+
+	for subscription := range subscriptions {
+		subscription.once.Do(func() { close(subscription.events) })
+	}
+}
+
+// Events returns the channel events are received on. It is closed when the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close removes the subscription from the hub
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+
+		for _, topic := range s.topics {
+			delete(s.hub.subscribers[topic], s)
+			if len(s.hub.subscribers[topic]) == 0 {
+				delete(s.hub.subscribers, topic)
+			}
+		}
+		close(s.events)
+	})
+}
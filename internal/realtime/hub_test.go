@@ -0,0 +1,81 @@
+package realtime
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubDeliversToTopicSubscribers(t *testing.T) {
+	hub := NewHub()
+	alice := hub.Subscribe(UserTopic(1), ResourceTopic("posts", 7))
+	bob := hub.Subscribe(UserTopic(2))
+	defer alice.Close()
+	defer bob.Close()
+
+	event, err := NewEvent(ResourceTopic("posts", 7), EventCommentCreated, map[string]int{"id": 3})
+	require.NoError(t, err)
+	hub.Deliver(event)
+
+	assert.Equal(t, event, <-alice.Events())
+	assert.Empty(t, bob.Events())
+}
+
+func TestHubDropsEventsForSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe(UserTopic(1))
+	defer subscription.Close()
+
+	event, err := NewEvent(UserTopic(1), EventNotification, nil)
+	require.NoError(t, err)
+	for i := 0; i < subscriptionBuffer+5; i++ {
+		hub.Deliver(event)
+	}
+
+	assert.Len(t, subscription.Events(), subscriptionBuffer)
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe(UserTopic(1))
+	subscription.Close()
+	subscription.Close()
+
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+	assert.Empty(t, hub.subscribers)
+
+	// Delivering after the last subscriber left must not panic
+	event, err := NewEvent(UserTopic(1), EventNotification, nil)
+	require.NoError(t, err)
+	hub.Deliver(event)
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe(UserTopic(1), UserTopic(2))
+	hub.Close()
+
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+	assert.Empty(t, hub.subscribers)
+
+	// Closing the subscription afterwards is a no-op, and new subscriptions are already closed
+	subscription.Close()
+	late := hub.Subscribe(UserTopic(1))
+	_, open = <-late.Events()
+	assert.False(t, open)
+	late.Close()
+	assert.Empty(t, hub.subscribers)
+}
+
+func TestWriteSSE(t *testing.T) {
+	event, err := NewEvent(UserTopic(1), EventFriendRequestReceived, map[string]int{"id": 5})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSSE(&buf, event))
+	assert.Equal(t, "event: friend_request.received\ndata: {\"id\":5}\n\n", buf.String())
+}
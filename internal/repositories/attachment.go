@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// AttachmentRepository provides methods for accessing post attachment data
+type AttachmentRepository struct {
+	*BaseRepository
+}
+
+// NewAttachmentRepository creates a new AttachmentRepository
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// CreateAttachments inserts the attachments of a post in the given order
+func (r *AttachmentRepository) CreateAttachments(postID int, attachments []*models.PostAttachment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	query := `
+		INSERT INTO post_attachments (post_id, type, position, url, title, description, image_url, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	now := time.Now()
+	for i, attachment := range attachments {
+		attachment.PostID = postID
+		attachment.Position = i
+		attachment.CreatedAt = now
+		err = tx.QueryRow(query, postID, attachment.Type, attachment.Position, attachment.URL,
+			attachment.Title, attachment.Description, attachment.ImageURL, now).Scan(&attachment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create attachment: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetAttachmentsForPosts retrieves the attachments of several posts, keyed by post ID and in order
+func (r *AttachmentRepository) GetAttachmentsForPosts(postIDs []int) (map[int][]*models.PostAttachment, error) {
+	attachments := map[int][]*models.PostAttachment{}
+	if len(postIDs) == 0 {
+		return attachments, nil
+	}
+
+	query := `
+		SELECT id, post_id, type, position, url, title, description, image_url, created_at
+		FROM post_attachments
+		WHERE post_id = ANY($1)
+		ORDER BY post_id, position`
+
+	rows, err := r.db.Query(query, pq.Array(postIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		attachment := &models.PostAttachment{}
+		if err := rows.Scan(&attachment.ID, &attachment.PostID, &attachment.Type, &attachment.Position,
+			&attachment.URL, &attachment.Title, &attachment.Description, &attachment.ImageURL,
+			&attachment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments[attachment.PostID] = append(attachments[attachment.PostID], attachment)
+	}
+
+	return attachments, nil
+}
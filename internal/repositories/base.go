@@ -3,8 +3,14 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
 )
 
+// ErrConflict is returned when a write violates a uniqueness constraint
+var ErrConflict = errors.New("resource already exists")
+
 // BaseRepository provides common functionality for all repositories
 type BaseRepository struct {
 	db *sql.DB
@@ -14,3 +20,18 @@ type BaseRepository struct {
 func NewBaseRepository(db *sql.DB) *BaseRepository {
 	return &BaseRepository{db: db}
 }
+
+// isUniqueViolation reports whether err was caused by a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// violatedConstraint returns the name of the constraint or index err violated, if any
+func violatedConstraint(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// BlockRepository provides methods for accessing user block data
+type BlockRepository struct {
+	*BaseRepository
+}
+
+// NewBlockRepository creates a new BlockRepository
+func NewBlockRepository(db *sql.DB) *BlockRepository {
+	return &BlockRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Block blocks a user. Blocking also ends any friendship between the two users
+// and removes pending friend requests in either direction.
+func (r *BlockRepository) Block(blockerID, blockedID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	query := `
+		INSERT INTO user_blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING`
+
+	_, err = tx.Exec(query, blockerID, blockedID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM friends WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`,
+		blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to delete friendship: %w", err)
+	}
+
+	if err := removeFromTimelines(tx, blockerID, blockedID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM friend_requests
+		WHERE status = 'pending'
+		AND ((from_user_id = $1 AND to_user_id = $2) OR (from_user_id = $2 AND to_user_id = $1))`,
+		blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to delete friend requests: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Unblock removes a block
+func (r *BlockRepository) Unblock(blockerID, blockedID int) error {
+	_, err := r.db.Exec(`DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
+
+// IsBlocked reports whether either user has blocked the other
+func (r *BlockRepository) IsBlocked(userID, otherUserID int) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)`
+
+	if err := r.db.QueryRow(query, userID, otherUserID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check block: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetBlockedUsers retrieves the users blocked by a user, most recent first
+func (r *BlockRepository) GetBlockedUsers(userID int) ([]*models.BlockedUser, error) {
+	blocked := []*models.BlockedUser{}
+	query := `
+		SELECT u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at,
+		       b.created_at
+		FROM user_blocks b
+		JOIN users u ON b.blocked_id = u.id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked users: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		user := &models.UserPublic{}
+		block := &models.BlockedUser{User: user}
+		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt,
+			&block.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blocked user: %w", err)
+		}
+		blocked = append(blocked, block)
+	}
+
+	return blocked, nil
+}
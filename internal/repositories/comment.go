@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/gocli/social_api/internal/models"
 )
 
@@ -22,13 +24,14 @@ func NewCommentRepository(db *sql.DB) *CommentRepository {
 // CreateComment inserts a new comment into the database
 func (r *CommentRepository) CreateComment(comment *models.Comment) error {
 	query := `
-		INSERT INTO comments (user_id, resource_type, resource_id, content, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO comments (user_id, resource_type, resource_id, parent_comment_id, content, hidden_at, held_at,
+		                      created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at`
 
 	now := time.Now()
 	err := r.db.QueryRow(query, comment.UserID, comment.ResourceType, comment.ResourceID,
-		comment.Content, now, now).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt)
+		comment.ParentCommentID, comment.Content, comment.HiddenAt, comment.HeldAt, now, now).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create comment: %w", err)
@@ -41,12 +44,14 @@ func (r *CommentRepository) CreateComment(comment *models.Comment) error {
 func (r *CommentRepository) GetCommentByID(id int) (*models.Comment, error) {
 	comment := &models.Comment{}
 	query := `
-		SELECT id, user_id, resource_type, resource_id, content, created_at, updated_at
+		SELECT id, user_id, resource_type, resource_id, parent_comment_id, content, edited_at, deleted_at,
+		       hidden_at, pinned_at, moderated_at, held_at, created_at, updated_at
 		FROM comments
 		WHERE id = $1`
 
 	err := r.db.QueryRow(query, id).Scan(&comment.ID, &comment.UserID, &comment.ResourceType,
-		&comment.ResourceID, &comment.Content, &comment.CreatedAt, &comment.UpdatedAt)
+		&comment.ResourceID, &comment.ParentCommentID, &comment.Content, &comment.EditedAt, &comment.DeletedAt,
+		&comment.HiddenAt, &comment.PinnedAt, &comment.ModeratedAt, &comment.HeldAt, &comment.CreatedAt, &comment.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -54,22 +59,41 @@ func (r *CommentRepository) GetCommentByID(id int) (*models.Comment, error) {
 		}
 		return nil, fmt.Errorf("failed to get comment: %w", err)
 	}
+	comment.Edited = comment.EditedAt != nil
+	comment.IsDeleted = comment.DeletedAt != nil
+	comment.IsHidden = comment.HiddenAt != nil
+	comment.IsPinned = comment.PinnedAt != nil
 
 	return comment, nil
 }
 
-// GetCommentsForResource retrieves all comments for a specific resource
-func (r *CommentRepository) GetCommentsForResource(resourceType string, resourceID int) ([]*models.Comment, error) {
-	comments := []*models.Comment{}
+// commentWithUserColumns selects a comment joined with its author as "c" and "u"
+const commentWithUserColumns = `
+		c.id, c.user_id, c.resource_type, c.resource_id, c.parent_comment_id, c.content, c.edited_at, c.deleted_at,
+		c.hidden_at, c.pinned_at, c.created_at, c.updated_at,
+		u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at`
+
+// visibleCommentCondition returns a SQL condition on the comments table aliased as alias that
+// hides comments hidden by the content owner, except from their author and from moderators,
+// and comments by restricted users, except from their author.
+// viewerParam and moderatorParam are the placeholders holding the viewer's ID and whether they moderate the content.
+func visibleCommentCondition(alias, viewerParam, moderatorParam string) string {
+	return fmt.Sprintf(`(%[1]s.user_id = %[2]s OR (%[4]s AND (%[1]s.hidden_at IS NULL OR %[3]s)))`,
+		alias, viewerParam, moderatorParam, unrestrictedUserCondition(alias+".user_id"))
+}
+
+// GetCommentsForResource retrieves the top-level comments for a specific resource that the viewer may see,
+// the pinned comment first. Moderators also see hidden comments.
+func (r *CommentRepository) GetCommentsForResource(resourceType string, resourceID, viewerID int, moderator bool) ([]*models.Comment, error) {
 	query := `
-		SELECT c.id, c.user_id, c.resource_type, c.resource_id, c.content, c.created_at, c.updated_at,
-		       u.id, u.name, u.profile_picture_url, u.cover_photo_url, u.created_at
+		SELECT` + commentWithUserColumns + `
 		FROM comments c
 		JOIN users u ON c.user_id = u.id
-		WHERE c.resource_type = $1 AND c.resource_id = $2
-		ORDER BY c.created_at DESC`
+		WHERE c.resource_type = $1 AND c.resource_id = $2 AND c.parent_comment_id IS NULL
+		AND ` + visibleCommentCondition("c", "$3", "$4") + `
+		ORDER BY c.pinned_at IS NOT NULL DESC, c.created_at DESC`
 
-	rows, err := r.db.Query(query, resourceType, resourceID)
+	rows, err := r.db.Query(query, resourceType, resourceID, viewerID, moderator)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
@@ -81,40 +105,323 @@ func (r *CommentRepository) GetCommentsForResource(resourceType string, resource
 		}
 	}()
 
+	comments := []*models.Comment{}
 	for rows.Next() {
-		comment := &models.Comment{}
-		user := &models.UserPublic{}
-		err := rows.Scan(&comment.ID, &comment.UserID, &comment.ResourceType, &comment.ResourceID,
-			&comment.Content, &comment.CreatedAt, &comment.UpdatedAt,
-			&user.ID, &user.Name, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt)
+		comment, err := scanCommentWithUser(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan comment: %w", err)
+			return nil, err
 		}
-		comment.User = user
 		comments = append(comments, comment)
 	}
 
 	return comments, nil
 }
 
-// UpdateComment updates a comment
-func (r *CommentRepository) UpdateComment(comment *models.Comment) error {
+// GetReplies retrieves a page of the replies to a comment that the viewer may see, oldest first
+func (r *CommentRepository) GetReplies(parentID, viewerID int, moderator bool, limit, offset int) ([]*models.Comment, error) {
 	query := `
-		UPDATE comments
-		SET content = $1, updated_at = $2
-		WHERE id = $3`
+		SELECT` + commentWithUserColumns + `
+		FROM comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.parent_comment_id = $1
+		AND ` + visibleCommentCondition("c", "$2", "$3") + `
+		ORDER BY c.created_at ASC, c.id ASC
+		LIMIT $4 OFFSET $5`
 
-	now := time.Now()
-	_, err := r.db.Exec(query, comment.Content, now, comment.ID)
+	rows, err := r.db.Query(query, parentID, viewerID, moderator, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replies: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	replies := []*models.Comment{}
+	for rows.Next() {
+		reply, err := scanCommentWithUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+
+	return replies, nil
+}
+
+// GetReplyPreviews retrieves the first perParent replies the viewer may see to each of the given comments,
+// keyed by parent ID
+func (r *CommentRepository) GetReplyPreviews(parentIDs []int, viewerID int, moderator bool, perParent int) (map[int][]*models.Comment, error) {
+	previews := make(map[int][]*models.Comment)
+	if len(parentIDs) == 0 {
+		return previews, nil
+	}
+
+	query := `
+		SELECT` + commentWithUserColumns + `
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY parent_comment_id ORDER BY created_at ASC, id ASC) AS position
+			FROM comments
+			WHERE parent_comment_id = ANY($1)
+			AND ` + visibleCommentCondition("comments", "$2", "$3") + `
+		) c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.position <= $4
+		ORDER BY c.parent_comment_id, c.position`
+
+	rows, err := r.db.Query(query, pq.Array(parentIDs), viewerID, moderator, perParent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reply previews: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		reply, err := scanCommentWithUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		previews[*reply.ParentCommentID] = append(previews[*reply.ParentCommentID], reply)
+	}
+
+	return previews, nil
+}
+
+// GetReplyCounts counts the replies the viewer may see to each of the given comments, keyed by parent ID.
+// Pass moderator to count every reply.
+func (r *CommentRepository) GetReplyCounts(parentIDs []int, viewerID int, moderator bool) (map[int]int, error) {
+	counts := make(map[int]int)
+	if len(parentIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT parent_comment_id, COUNT(*)
+		FROM comments
+		WHERE parent_comment_id = ANY($1)
+		AND ` + visibleCommentCondition("comments", "$2", "$3") + `
+		GROUP BY parent_comment_id`
+
+	rows, err := r.db.Query(query, pq.Array(parentIDs), viewerID, moderator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count replies: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var parentID, count int
+		if err := rows.Scan(&parentID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reply count: %w", err)
+		}
+		counts[parentID] = count
+	}
+
+	return counts, nil
+}
+
+// scanCommentWithUser scans a row selected with commentWithUserColumns.
+// The content and author of a tombstoned comment are not exposed.
+func scanCommentWithUser(rows *sql.Rows) (*models.Comment, error) {
+	comment := &models.Comment{}
+	user := &models.UserPublic{}
+	err := rows.Scan(&comment.ID, &comment.UserID, &comment.ResourceType, &comment.ResourceID,
+		&comment.ParentCommentID, &comment.Content, &comment.EditedAt, &comment.DeletedAt,
+		&comment.HiddenAt, &comment.PinnedAt, &comment.CreatedAt, &comment.UpdatedAt,
+		&user.ID, &user.Name, &user.Username, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan comment: %w", err)
+	}
+	comment.IsHidden = comment.HiddenAt != nil
+	comment.IsPinned = comment.PinnedAt != nil
+
+	if comment.DeletedAt != nil {
+		comment.IsDeleted = true
+		comment.UserID = 0
+		comment.Content = ""
+		comment.EditedAt = nil
+		return comment, nil
+	}
+	comment.Edited = comment.EditedAt != nil
+
+	comment.User = user
+	return comment, nil
+}
 
+// UpdateComment updates a comment. A content change keeps the previous content as a revision by editorID.
+func (r *CommentRepository) UpdateComment(comment *models.Comment, editorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	now := time.Now()
+	editedAt, err := updateContentWithRevision(tx, models.ResourceTypeComments, comment.ID, editorID, comment.Content, now)
 	if err != nil {
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
 
+	query := `UPDATE comments SET updated_at = $1 WHERE id = $2`
+	if _, err := tx.Exec(query, now, comment.ID); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if editedAt != nil {
+		comment.EditedAt = editedAt
+		comment.Edited = true
+	}
 	comment.UpdatedAt = now
 	return nil
 }
 
+// TombstoneComment clears a comment's content and marks it deleted, keeping its replies in place
+func (r *CommentRepository) TombstoneComment(id int) error {
+	query := `
+		UPDATE comments
+		SET content = '', deleted_at = $1, pinned_at = NULL, updated_at = $1
+		WHERE id = $2`
+
+	_, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to tombstone comment: %w", err)
+	}
+	return nil
+}
+
+// SetHidden hides or unhides a comment. Hiding a pinned comment unpins it.
+func (r *CommentRepository) SetHidden(id int, hidden bool) error {
+	query := `
+		UPDATE comments
+		SET hidden_at = CASE WHEN $1 THEN COALESCE(hidden_at, $2) END,
+		    pinned_at = CASE WHEN $1 THEN NULL ELSE pinned_at END
+		WHERE id = $3`
+
+	_, err := r.db.Exec(query, hidden, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to set comment hidden: %w", err)
+	}
+	return nil
+}
+
+// Moderate hides a comment on behalf of a site moderator, unpinning it
+func (r *CommentRepository) Moderate(id int) error {
+	query := `
+		UPDATE comments
+		SET hidden_at = COALESCE(hidden_at, $1), moderated_at = COALESCE(moderated_at, $1), pinned_at = NULL
+		WHERE id = $2`
+
+	if _, err := r.db.Exec(query, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to moderate comment: %w", err)
+	}
+	return nil
+}
+
+// Hold hides a comment until it is reviewed, unpinning it
+func (r *CommentRepository) Hold(id int) error {
+	query := `
+		UPDATE comments
+		SET hidden_at = COALESCE(hidden_at, $1), held_at = COALESCE(held_at, $1), pinned_at = NULL
+		WHERE id = $2`
+
+	if _, err := r.db.Exec(query, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to hold comment: %w", err)
+	}
+	return nil
+}
+
+// Release unhides a held comment. A comment a moderator hid in the meantime stays hidden.
+func (r *CommentRepository) Release(id int) error {
+	query := `
+		UPDATE comments
+		SET hidden_at = CASE WHEN moderated_at IS NULL THEN NULL ELSE hidden_at END, held_at = NULL
+		WHERE id = $1 AND held_at IS NOT NULL`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to release comment: %w", err)
+	}
+	return nil
+}
+
+// CountRecentDuplicates counts the user's comments with exactly this content created since the given time
+func (r *CommentRepository) CountRecentDuplicates(userID int, content string, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comments WHERE user_id = $1 AND content = $2 AND created_at >= $3`
+	if err := r.db.QueryRow(query, userID, content, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count duplicate comments: %w", err)
+	}
+	return count, nil
+}
+
+// PinComment pins a comment, unpinning any other comment on the same resource
+func (r *CommentRepository) PinComment(comment *models.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	query := `
+		UPDATE comments
+		SET pinned_at = NULL
+		WHERE resource_type = $1 AND resource_id = $2 AND pinned_at IS NOT NULL AND id <> $3`
+
+	if _, err := tx.Exec(query, comment.ResourceType, comment.ResourceID, comment.ID); err != nil {
+		return fmt.Errorf("failed to unpin comment: %w", err)
+	}
+
+	now := time.Now()
+	query = `UPDATE comments SET pinned_at = COALESCE(pinned_at, $1) WHERE id = $2`
+	if _, err := tx.Exec(query, now, comment.ID); err != nil {
+		return fmt.Errorf("failed to pin comment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UnpinComment unpins a comment
+func (r *CommentRepository) UnpinComment(id int) error {
+	query := `UPDATE comments SET pinned_at = NULL WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to unpin comment: %w", err)
+	}
+	return nil
+}
+
 // DeleteComment removes a comment from the database
 func (r *CommentRepository) DeleteComment(id int) error {
 	query := `DELETE FROM comments WHERE id = $1`
@@ -131,7 +438,7 @@ func (r *CommentRepository) GetUserComments(userID int) ([]*models.Comment, erro
 	query := `
 		SELECT id, user_id, resource_type, resource_id, content, created_at, updated_at
 		FROM comments
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query, userID)
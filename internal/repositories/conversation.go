@@ -0,0 +1,416 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// ConversationRepository provides methods for accessing conversation and message data
+type ConversationRepository struct {
+	*BaseRepository
+}
+
+// NewConversationRepository creates a new ConversationRepository
+func NewConversationRepository(db *sql.DB) *ConversationRepository {
+	return &ConversationRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// CreateConversation inserts a new conversation with its members. directKey
+// identifies one-to-one conversations and must be empty for groups; creating a
+// second one-to-one conversation with the same key returns ErrConflict.
+func (r *ConversationRepository) CreateConversation(conversation *models.Conversation, directKey string, memberIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	query := `
+		INSERT INTO conversations (is_group, title, created_by, direct_key, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	now := time.Now()
+	err = tx.QueryRow(query, conversation.IsGroup, conversation.Title, conversation.CreatedBy, directKey, now, now).
+		Scan(&conversation.ID, &conversation.CreatedAt, &conversation.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to create conversation: %w", ErrConflict)
+		}
+		return fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	for _, memberID := range memberIDs {
+		_, err = tx.Exec(`INSERT INTO conversation_members (conversation_id, user_id, joined_at) VALUES ($1, $2, $3)`,
+			conversation.ID, memberID, now)
+		if err != nil {
+			return fmt.Errorf("failed to add conversation member: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetDirectConversationID retrieves the ID of the one-to-one conversation with a direct key
+func (r *ConversationRepository) GetDirectConversationID(directKey string) (int, error) {
+	var id int
+	err := r.db.QueryRow(`SELECT id FROM conversations WHERE direct_key = $1`, directKey).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("conversation not found: %w", err)
+		}
+		return 0, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	return id, nil
+}
+
+// conversationQuery selects the conversations of the member bound to $1 with
+// their unread count and the last message the member can see
+const conversationQuery = `
+	SELECT c.id, c.is_group, c.title, COALESCE(c.created_by, 0), c.last_message_at, c.created_at, c.updated_at,
+	       m.muted,
+	       (SELECT COUNT(*) FROM messages um
+	        WHERE um.conversation_id = c.id AND um.id > m.last_read_message_id
+	        AND um.created_at >= m.joined_at AND um.sender_id IS DISTINCT FROM m.user_id),
+	       lm.id, lm.sender_id, lm.content, lm.attachment_url, lm.created_at
+	FROM conversation_members m
+	JOIN conversations c ON m.conversation_id = c.id
+	LEFT JOIN LATERAL (
+	    SELECT id, COALESCE(sender_id, 0) AS sender_id, content, attachment_url, created_at
+	    FROM messages
+	    WHERE conversation_id = c.id AND created_at >= m.joined_at
+	    ORDER BY id DESC
+	    LIMIT 1
+	) lm ON TRUE
+	WHERE m.user_id = $1 AND m.left_at IS NULL`
+
+// GetConversationForMember retrieves a conversation as seen by one of its current members
+func (r *ConversationRepository) GetConversationForMember(conversationID, userID int) (*models.Conversation, error) {
+	conversations, err := r.queryConversations(conversationQuery+` AND c.id = $2`, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conversations) == 0 {
+		return nil, fmt.Errorf("conversation not found: %w", sql.ErrNoRows)
+	}
+	return conversations[0], nil
+}
+
+// GetConversationsForUser retrieves a user's conversations, most recently active first
+func (r *ConversationRepository) GetConversationsForUser(userID, limit, offset int) ([]*models.Conversation, error) {
+	query := conversationQuery + `
+		ORDER BY COALESCE(c.last_message_at, c.created_at) DESC
+		LIMIT $2 OFFSET $3`
+	return r.queryConversations(query, userID, limit, offset)
+}
+
+// queryConversations runs a query built from conversationQuery and scans the results
+func (r *ConversationRepository) queryConversations(query string, args ...interface{}) ([]*models.Conversation, error) {
+	conversations := []*models.Conversation{}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		conversation := &models.Conversation{}
+		var messageID, senderID sql.NullInt64
+		var content, attachmentURL sql.NullString
+		var sentAt sql.NullTime
+		err := rows.Scan(&conversation.ID, &conversation.IsGroup, &conversation.Title, &conversation.CreatedBy,
+			&conversation.LastMessageAt, &conversation.CreatedAt, &conversation.UpdatedAt,
+			&conversation.Muted, &conversation.UnreadCount,
+			&messageID, &senderID, &content, &attachmentURL, &sentAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if messageID.Valid {
+			conversation.LastMessage = &models.Message{
+				ID:             int(messageID.Int64),
+				ConversationID: conversation.ID,
+				SenderID:       int(senderID.Int64),
+				Content:        content.String,
+				AttachmentURL:  attachmentURL.String,
+				CreatedAt:      sentAt.Time,
+			}
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, nil
+}
+
+// GetMember retrieves a user's membership in a conversation, including past memberships
+func (r *ConversationRepository) GetMember(conversationID, userID int) (*models.ConversationMember, error) {
+	member := &models.ConversationMember{}
+	query := `
+		SELECT conversation_id, user_id, joined_at, left_at, muted, last_read_message_id
+		FROM conversation_members
+		WHERE conversation_id = $1 AND user_id = $2`
+
+	err := r.db.QueryRow(query, conversationID, userID).Scan(&member.ConversationID, &member.UserID,
+		&member.JoinedAt, &member.LeftAt, &member.Muted, &member.LastReadMessageID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conversation member not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get conversation member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetMemberIDs retrieves the IDs of everyone who is or was a member of a conversation
+func (r *ConversationRepository) GetMemberIDs(conversationID int) ([]int, error) {
+	ids := []int{}
+
+	rows, err := r.db.Query(`SELECT user_id FROM conversation_members WHERE conversation_id = $1`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation members: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// GetActiveMembers retrieves the current members of several conversations, keyed by conversation ID
+func (r *ConversationRepository) GetActiveMembers(conversationIDs []int) (map[int][]*models.ConversationMember, error) {
+	members := map[int][]*models.ConversationMember{}
+	if len(conversationIDs) == 0 {
+		return members, nil
+	}
+
+	query := `
+		SELECT m.conversation_id, m.user_id, m.joined_at, m.muted, m.last_read_message_id,
+		       u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at
+		FROM conversation_members m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.conversation_id = ANY($1) AND m.left_at IS NULL
+		ORDER BY m.conversation_id, m.joined_at, m.user_id`
+
+	rows, err := r.db.Query(query, pq.Array(conversationIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation members: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		user := &models.UserPublic{}
+		member := &models.ConversationMember{User: user}
+		err := rows.Scan(&member.ConversationID, &member.UserID, &member.JoinedAt, &member.Muted, &member.LastReadMessageID,
+			&user.ID, &user.Name, &user.Username, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation member: %w", err)
+		}
+		members[member.ConversationID] = append(members[member.ConversationID], member)
+	}
+
+	return members, nil
+}
+
+// AddMember adds a user to a conversation. A user who left is added back and
+// only sees the messages sent from now on.
+func (r *ConversationRepository) AddMember(conversationID, userID int) error {
+	query := `
+		INSERT INTO conversation_members (conversation_id, user_id, joined_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (conversation_id, user_id) DO UPDATE
+		SET joined_at = EXCLUDED.joined_at, left_at = NULL, muted = FALSE, last_read_message_id = 0
+		WHERE conversation_members.left_at IS NOT NULL`
+
+	if _, err := r.db.Exec(query, conversationID, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to add conversation member: %w", err)
+	}
+
+	return nil
+}
+
+// LeaveConversation removes a user from a conversation
+func (r *ConversationRepository) LeaveConversation(conversationID, userID int) error {
+	query := `
+		UPDATE conversation_members
+		SET left_at = $1
+		WHERE conversation_id = $2 AND user_id = $3 AND left_at IS NULL`
+
+	if _, err := r.db.Exec(query, time.Now(), conversationID, userID); err != nil {
+		return fmt.Errorf("failed to leave conversation: %w", err)
+	}
+
+	return nil
+}
+
+// SetMuted mutes or unmutes a conversation for a member
+func (r *ConversationRepository) SetMuted(conversationID, userID int, muted bool) error {
+	query := `UPDATE conversation_members SET muted = $1 WHERE conversation_id = $2 AND user_id = $3`
+
+	if _, err := r.db.Exec(query, muted, conversationID, userID); err != nil {
+		return fmt.Errorf("failed to mute conversation: %w", err)
+	}
+
+	return nil
+}
+
+// MarkRead records that a member has read a conversation up to a message. Read
+// receipts only move forward.
+func (r *ConversationRepository) MarkRead(conversationID, userID, messageID int) error {
+	query := `
+		UPDATE conversation_members
+		SET last_read_message_id = GREATEST(last_read_message_id, $1)
+		WHERE conversation_id = $2 AND user_id = $3`
+
+	if _, err := r.db.Exec(query, messageID, conversationID, userID); err != nil {
+		return fmt.Errorf("failed to mark conversation as read: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMessage inserts a new message and bumps the conversation's activity time
+func (r *ConversationRepository) CreateMessage(message *models.Message) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	query := `
+		INSERT INTO messages (conversation_id, sender_id, content, attachment_url, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	now := time.Now()
+	err = tx.QueryRow(query, message.ConversationID, message.SenderID, message.Content, message.AttachmentURL, now).
+		Scan(&message.ID, &message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	_, err = tx.Exec(`UPDATE conversations SET last_message_at = $1, updated_at = $1 WHERE id = $2`, now, message.ConversationID)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessages retrieves up to limit messages of a conversation sent since a time,
+// newest first. When before is not zero only messages older than it are returned.
+func (r *ConversationRepository) GetMessages(conversationID int, since time.Time, before, limit int) ([]*models.Message, error) {
+	messages := []*models.Message{}
+	query := `
+		SELECT id, conversation_id, COALESCE(sender_id, 0), content, attachment_url, created_at
+		FROM messages
+		WHERE conversation_id = $1 AND created_at >= $2 AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`
+
+	rows, err := r.db.Query(query, conversationID, since, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		message := &models.Message{}
+		err := rows.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Content,
+			&message.AttachmentURL, &message.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// GetLatestMessageID retrieves the ID of the newest message in a conversation, or zero if it has none
+func (r *ConversationRepository) GetLatestMessageID(conversationID int) (int, error) {
+	var id int
+	query := `SELECT COALESCE(MAX(id), 0) FROM messages WHERE conversation_id = $1`
+
+	if err := r.db.QueryRow(query, conversationID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get latest message: %w", err)
+	}
+
+	return id, nil
+}
+
+// CountUnread counts the unread messages in a user's conversations, ignoring muted ones
+func (r *ConversationRepository) CountUnread(userID int) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM conversation_members m
+		JOIN messages msg ON msg.conversation_id = m.conversation_id
+		WHERE m.user_id = $1 AND m.left_at IS NULL AND NOT m.muted
+		AND msg.id > m.last_read_message_id
+		AND msg.created_at >= m.joined_at
+		AND msg.sender_id IS DISTINCT FROM m.user_id`
+
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	return count, nil
+}
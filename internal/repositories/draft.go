@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// draftColumns are the columns scanned by scanDraft
+const draftColumns = `id, user_id, content, privacy, link_url, image_urls, poll, status, publish_at,
+		locked_until, last_error, created_at, updated_at`
+
+// DraftRepository provides methods for accessing post draft data
+type DraftRepository struct {
+	*BaseRepository
+}
+
+// NewDraftRepository creates a new DraftRepository
+func NewDraftRepository(db *sql.DB) *DraftRepository {
+	return &DraftRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Create inserts a new draft into the database
+func (r *DraftRepository) Create(draft *models.PostDraft) error {
+	poll, err := encodeDraftPoll(draft.Poll)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO post_drafts (user_id, content, privacy, link_url, image_urls, poll, status, publish_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	now := time.Now()
+	err = r.db.QueryRow(query, draft.UserID, draft.Content, draft.Privacy, draft.LinkURL, pq.Array(draft.ImageURLs),
+		poll, draft.Status, draft.PublishAt, now, now).
+		Scan(&draft.ID, &draft.CreatedAt, &draft.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create draft: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a draft by ID
+func (r *DraftRepository) GetByID(id int) (*models.PostDraft, error) {
+	query := `SELECT ` + draftColumns + ` FROM post_drafts WHERE id = $1`
+
+	draft, err := scanDraft(r.db.QueryRow(query, id).Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("draft not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// GetDraftsByUserID retrieves a user's drafts, optionally only those with the given status.
+// Scheduled drafts are ordered by when they will be published, others by when they were last changed.
+func (r *DraftRepository) GetDraftsByUserID(userID int, status string, limit, offset int) ([]*models.PostDraft, error) {
+	order := "updated_at DESC"
+	if status == models.DraftStatusScheduled {
+		order = "publish_at, id"
+	}
+
+	query := `
+		SELECT ` + draftColumns + `
+		FROM post_drafts
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY ` + order + `
+		LIMIT $3 OFFSET $4`
+
+	return r.queryDrafts(query, userID, status, limit, offset)
+}
+
+// Update saves the content, settings and status of a draft. It returns sql.ErrNoRows
+// if the draft does not exist or is being published.
+func (r *DraftRepository) Update(draft *models.PostDraft) error {
+	poll, err := encodeDraftPoll(draft.Poll)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE post_drafts
+		SET content = $1, privacy = $2, link_url = $3, image_urls = $4, poll = $5, status = $6,
+		    publish_at = $7, last_error = $8, updated_at = $9
+		WHERE id = $10 AND (locked_until IS NULL OR locked_until < $9)`
+
+	now := time.Now()
+	result, err := r.db.Exec(query, draft.Content, draft.Privacy, draft.LinkURL, pq.Array(draft.ImageURLs), poll,
+		draft.Status, draft.PublishAt, draft.LastError, now, draft.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update draft: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("draft not available: %w", sql.ErrNoRows)
+	}
+
+	draft.UpdatedAt = now
+	return nil
+}
+
+// Claim locks a draft for publishing until the given time. It returns sql.ErrNoRows
+// if the draft does not exist or is already being published.
+func (r *DraftRepository) Claim(id int, now, until time.Time) (*models.PostDraft, error) {
+	query := `
+		UPDATE post_drafts
+		SET locked_until = $1
+		WHERE id = $2 AND (locked_until IS NULL OR locked_until < $3)
+		RETURNING ` + draftColumns
+
+	draft, err := scanDraft(r.db.QueryRow(query, until, id, now).Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("draft not available: %w", err)
+		}
+		return nil, fmt.Errorf("failed to claim draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// ClaimDue locks up to limit scheduled drafts that are due for publishing until the given time.
+// Rows another server is claiming at the same moment are skipped, and a claimed draft is not
+// returned again until its lock expires, so every due draft goes to a single server.
+func (r *DraftRepository) ClaimDue(now, until time.Time, limit int) ([]*models.PostDraft, error) {
+	query := `
+		UPDATE post_drafts
+		SET locked_until = $1
+		WHERE id IN (
+		    SELECT id FROM post_drafts
+		    WHERE status = 'scheduled' AND publish_at <= $2
+		    AND (locked_until IS NULL OR locked_until < $2)
+		    ORDER BY publish_at
+		    LIMIT $3
+		    FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + draftColumns
+
+	return r.queryDrafts(query, until, now, limit)
+}
+
+// Release unlocks a claimed draft so it can be published again
+func (r *DraftRepository) Release(id int) error {
+	_, err := r.db.Exec(`UPDATE post_drafts SET locked_until = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to release draft: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed unlocks a draft that could not be published, records why and turns it back into an unscheduled draft
+func (r *DraftRepository) MarkFailed(id int, message string) error {
+	query := `
+		UPDATE post_drafts
+		SET status = 'draft', locked_until = NULL, last_error = $1, updated_at = $2
+		WHERE id = $3`
+
+	_, err := r.db.Exec(query, message, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark draft as failed: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a draft, even one that is being published
+func (r *DraftRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM post_drafts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// DeleteUnlocked deletes a draft that is not being published. It returns sql.ErrNoRows
+// if the draft does not exist or is being published.
+func (r *DraftRepository) DeleteUnlocked(id int) error {
+	query := `DELETE FROM post_drafts WHERE id = $1 AND (locked_until IS NULL OR locked_until < $2)`
+	result, err := r.db.Exec(query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("draft not available: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// queryDrafts runs a query that selects draftColumns
+func (r *DraftRepository) queryDrafts(query string, args ...interface{}) ([]*models.PostDraft, error) {
+	drafts := []*models.PostDraft{}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		draft, err := scanDraft(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return drafts, nil
+}
+
+// scanDraft scans a row selected with draftColumns using the given Scan function
+func scanDraft(scan func(dest ...interface{}) error) (*models.PostDraft, error) {
+	draft := &models.PostDraft{}
+	var poll []byte
+	err := scan(&draft.ID, &draft.UserID, &draft.Content, &draft.Privacy, &draft.LinkURL,
+		pq.Array(&draft.ImageURLs), &poll, &draft.Status, &draft.PublishAt, &draft.LockedUntil,
+		&draft.LastError, &draft.CreatedAt, &draft.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll != nil {
+		if err := json.Unmarshal(poll, &draft.Poll); err != nil {
+			return nil, fmt.Errorf("failed to decode draft poll: %w", err)
+		}
+	}
+	if draft.ImageURLs == nil {
+		draft.ImageURLs = []string{}
+	}
+
+	return draft, nil
+}
+
+// encodeDraftPoll encodes the poll of a draft for the poll column
+func encodeDraftPoll(poll *models.Poll) (interface{}, error) {
+	if poll == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(poll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode draft poll: %w", err)
+	}
+	return string(encoded), nil
+}
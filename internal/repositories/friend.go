@@ -142,6 +142,11 @@ func (r *FriendRepository) CreateFriend(friend *models.Friend) error {
 		return fmt.Errorf("failed to create reverse friendship: %w", err)
 	}
 
+	// Show each friend's recent posts in the other's feed
+	if err := backfillTimelines(tx, friend.UserID, friend.FriendID); err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -154,7 +159,7 @@ func (r *FriendRepository) CreateFriend(friend *models.Friend) error {
 func (r *FriendRepository) GetFriendsForUser(userID int) ([]*models.UserPublic, error) {
 	friends := []*models.UserPublic{}
 	query := `
-		SELECT u.id, u.name, u.profile_picture_url, u.cover_photo_url, u.created_at
+		SELECT u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at
 		FROM friends f
 		JOIN users u ON f.friend_id = u.id
 		WHERE f.user_id = $1
@@ -174,7 +179,7 @@ func (r *FriendRepository) GetFriendsForUser(userID int) ([]*models.UserPublic,
 
 	for rows.Next() {
 		friend := &models.UserPublic{}
-		err := rows.Scan(&friend.ID, &friend.Name, &friend.ProfilePictureURL, &friend.CoverPhotoURL, &friend.CreatedAt)
+		err := rows.Scan(&friend.ID, &friend.Name, &friend.Username, &friend.ProfilePictureURL, &friend.CoverPhotoURL, &friend.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan friend: %w", err)
 		}
@@ -184,6 +189,18 @@ func (r *FriendRepository) GetFriendsForUser(userID int) ([]*models.UserPublic,
 	return friends, nil
 }
 
+// AreFriends reports whether two users are friends
+func (r *FriendRepository) AreFriends(userID, otherUserID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM friends WHERE user_id = $1 AND friend_id = $2)`
+
+	if err := r.db.QueryRow(query, userID, otherUserID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check friendship: %w", err)
+	}
+
+	return exists, nil
+}
+
 // DeleteFriend deletes a friendship
 func (r *FriendRepository) DeleteFriend(userID, friendID int) error {
 	// Delete friendship in both directions
@@ -205,6 +222,10 @@ func (r *FriendRepository) DeleteFriend(userID, friendID int) error {
 		return fmt.Errorf("failed to delete friendship: %w", err)
 	}
 
+	if err := removeFromTimelines(tx, userID, friendID); err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -18,29 +18,29 @@ func NewLikeRepository(db *sql.DB) *LikeRepository {
 	return &LikeRepository{BaseRepository: NewBaseRepository(db)}
 }
 
-// CreateLike inserts a new like into the database
-func (r *LikeRepository) CreateLike(like *models.Like) error {
+// SetReaction stores a user's reaction to a resource, replacing any earlier reaction.
+// It reports whether the user had not reacted to the resource before.
+func (r *LikeRepository) SetReaction(like *models.Like) (bool, error) {
 	query := `
-		INSERT INTO likes (user_id, resource_type, resource_id, created_at)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (user_id, resource_type, resource_id) DO NOTHING
-		RETURNING created_at`
+		INSERT INTO reactions (user_id, resource_type, resource_id, type, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, resource_type, resource_id) DO UPDATE SET type = EXCLUDED.type
+		RETURNING created_at, xmax = 0`
 
-	var createdAt interface{}
-	err := r.db.QueryRow(query, like.UserID, like.ResourceType, like.ResourceID, like.CreatedAt).
-		Scan(&createdAt)
+	var created bool
+	err := r.db.QueryRow(query, like.UserID, like.ResourceType, like.ResourceID, like.Type, like.CreatedAt).
+		Scan(&like.CreatedAt, &created)
 
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to create like: %w", err)
+	if err != nil {
+		return false, fmt.Errorf("failed to save reaction: %w", err)
 	}
 
-	// If ErrNoRows is returned, it means the like already existed (conflict), which is fine
-	return nil
+	return created, nil
 }
 
 // DeleteLike removes a like from the database
 func (r *LikeRepository) DeleteLike(userID int, resourceType string, resourceID int) error {
-	query := `DELETE FROM likes WHERE user_id = $1 AND resource_type = $2 AND resource_id = $3`
+	query := `DELETE FROM reactions WHERE user_id = $1 AND resource_type = $2 AND resource_id = $3`
 	_, err := r.db.Exec(query, userID, resourceType, resourceID)
 	if err != nil {
 		return fmt.Errorf("failed to delete like: %w", err)
@@ -48,16 +48,20 @@ func (r *LikeRepository) DeleteLike(userID int, resourceType string, resourceID
 	return nil
 }
 
-// GetLikesForResource retrieves all likes for a specific resource
-func (r *LikeRepository) GetLikesForResource(resourceType string, resourceID int) ([]*models.Like, error) {
+// GetLikesForResource retrieves a page of the reactions to a specific resource with the reacting users,
+// newest first. A non-empty reactionType only returns reactions of that type.
+func (r *LikeRepository) GetLikesForResource(resourceType string, resourceID int, reactionType string, limit, offset int) ([]*models.Like, error) {
 	likes := []*models.Like{}
 	query := `
-		SELECT user_id, resource_type, resource_id, created_at
-		FROM likes
-		WHERE resource_type = $1 AND resource_id = $2
-		ORDER BY created_at DESC`
-
-	rows, err := r.db.Query(query, resourceType, resourceID)
+		SELECT l.user_id, l.resource_type, l.resource_id, l.type, l.created_at,
+		       u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at
+		FROM reactions l
+		JOIN users u ON l.user_id = u.id
+		WHERE l.resource_type = $1 AND l.resource_id = $2 AND ($3 = '' OR l.type = $3)
+		ORDER BY l.created_at DESC
+		LIMIT $4 OFFSET $5`
+
+	rows, err := r.db.Query(query, resourceType, resourceID, reactionType, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get likes: %w", err)
 	}
@@ -71,22 +75,58 @@ func (r *LikeRepository) GetLikesForResource(resourceType string, resourceID int
 
 	for rows.Next() {
 		like := &models.Like{}
-		err := rows.Scan(&like.UserID, &like.ResourceType, &like.ResourceID, &like.CreatedAt)
+		user := &models.UserPublic{}
+		err := rows.Scan(&like.UserID, &like.ResourceType, &like.ResourceID, &like.Type, &like.CreatedAt,
+			&user.ID, &user.Name, &user.Username, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan like: %w", err)
 		}
+		like.User = user
 		likes = append(likes, like)
 	}
 
 	return likes, nil
 }
 
+// GetReactionCounts counts the reactions to a resource by type
+func (r *LikeRepository) GetReactionCounts(resourceType string, resourceID int) (map[string]int, error) {
+	counts := make(map[string]int)
+	query := `
+		SELECT type, COUNT(*)
+		FROM reactions
+		WHERE resource_type = $1 AND resource_id = $2
+		GROUP BY type`
+
+	rows, err := r.db.Query(query, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var reactionType string
+		var count int
+		if err := rows.Scan(&reactionType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		counts[reactionType] = count
+	}
+
+	return counts, nil
+}
+
 // GetUserLikedResources retrieves all resources liked by a user of a specific type
 func (r *LikeRepository) GetUserLikedResources(userID int, resourceType string) ([]int, error) {
 	resourceIDs := []int{}
 	query := `
 		SELECT resource_id
-		FROM likes
+		FROM reactions
 		WHERE user_id = $1 AND resource_type = $2`
 
 	rows, err := r.db.Query(query, userID, resourceType)
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// MentionRepository provides methods for accessing @mention data
+type MentionRepository struct {
+	*BaseRepository
+}
+
+// NewMentionRepository creates a new MentionRepository
+func NewMentionRepository(db *sql.DB) *MentionRepository {
+	return &MentionRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// ReplaceMentions replaces the mentions stored for a resource
+func (r *MentionRepository) ReplaceMentions(resourceType string, resourceID int, mentions []*models.Mention) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	_, err = tx.Exec(`DELETE FROM mentions WHERE resource_type = $1 AND resource_id = $2`, resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to clear mentions: %w", err)
+	}
+
+	query := `
+		INSERT INTO mentions (resource_type, resource_id, mentioned_user_id, username, start_offset, length, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	now := time.Now()
+	for _, mention := range mentions {
+		_, err = tx.Exec(query, resourceType, resourceID, mention.UserID, mention.Username,
+			mention.Offset, mention.Length, now)
+		if err != nil {
+			return fmt.Errorf("failed to save mention: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetMentionsForResources retrieves the mentions of several resources of the same type,
+// keyed by resource ID and ordered by their position in the content
+func (r *MentionRepository) GetMentionsForResources(resourceType string, resourceIDs []int) (map[int][]*models.Mention, error) {
+	mentions := map[int][]*models.Mention{}
+	if len(resourceIDs) == 0 {
+		return mentions, nil
+	}
+
+	query := `
+		SELECT resource_id, mentioned_user_id, username, start_offset, length
+		FROM mentions
+		WHERE resource_type = $1 AND resource_id = ANY($2)
+		ORDER BY resource_id, start_offset`
+
+	rows, err := r.db.Query(query, resourceType, pq.Array(resourceIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var resourceID int
+		mention := &models.Mention{}
+		if err := rows.Scan(&resourceID, &mention.UserID, &mention.Username, &mention.Offset, &mention.Length); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		mentions[resourceID] = append(mentions[resourceID], mention)
+	}
+
+	return mentions, nil
+}
+
+// DeleteMentionsForResource removes the mentions stored for a resource
+func (r *MentionRepository) DeleteMentionsForResource(resourceType string, resourceID int) error {
+	_, err := r.db.Exec(`DELETE FROM mentions WHERE resource_type = $1 AND resource_id = $2`, resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete mentions: %w", err)
+	}
+	return nil
+}
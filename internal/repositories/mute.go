@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// MuteRepository provides methods for accessing feed mutes and hidden posts
+type MuteRepository struct {
+	*BaseRepository
+}
+
+// NewMuteRepository creates a new MuteRepository
+func NewMuteRepository(db *sql.DB) *MuteRepository {
+	return &MuteRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Mute leaves a user's posts out of another user's feed until expiresAt, or for good if it is nil.
+// Muting a user again replaces the earlier mute or snooze.
+func (r *MuteRepository) Mute(userID, mutedUserID int, expiresAt *time.Time) error {
+	query := `
+		INSERT INTO feed_mutes (user_id, muted_user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, muted_user_id) DO UPDATE SET expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at`
+
+	_, err := r.db.Exec(query, userID, mutedUserID, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mute user: %w", err)
+	}
+
+	return nil
+}
+
+// Unmute removes a mute or snooze
+func (r *MuteRepository) Unmute(userID, mutedUserID int) error {
+	_, err := r.db.Exec(`DELETE FROM feed_mutes WHERE user_id = $1 AND muted_user_id = $2`, userID, mutedUserID)
+	if err != nil {
+		return fmt.Errorf("failed to unmute user: %w", err)
+	}
+	return nil
+}
+
+// GetMutes retrieves the users a user has muted or snoozed and whose snooze has not ended, most recent first
+func (r *MuteRepository) GetMutes(userID int, now time.Time) ([]*models.FeedMute, error) {
+	mutes := []*models.FeedMute{}
+	query := `
+		SELECT u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at,
+		       m.expires_at, m.created_at
+		FROM feed_mutes m
+		JOIN users u ON m.muted_user_id = u.id
+		WHERE m.user_id = $1 AND (m.expires_at IS NULL OR m.expires_at > $2)
+		ORDER BY m.created_at DESC`
+
+	rows, err := r.db.Query(query, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get muted users: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		user := &models.UserPublic{}
+		mute := &models.FeedMute{User: user}
+		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt,
+			&mute.ExpiresAt, &mute.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan muted user: %w", err)
+		}
+		mutes = append(mutes, mute)
+	}
+
+	return mutes, nil
+}
+
+// HidePost leaves a post out of a user's feed
+func (r *MuteRepository) HidePost(userID, postID int) error {
+	query := `
+		INSERT INTO hidden_posts (user_id, post_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, post_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, userID, postID, time.Now()); err != nil {
+		return fmt.Errorf("failed to hide post: %w", err)
+	}
+	return nil
+}
+
+// UnhidePost shows a hidden post in a user's feed again
+func (r *MuteRepository) UnhidePost(userID, postID int) error {
+	_, err := r.db.Exec(`DELETE FROM hidden_posts WHERE user_id = $1 AND post_id = $2`, userID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to unhide post: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// NotificationRepository provides methods for accessing notification data
+type NotificationRepository struct {
+	*BaseRepository
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Upsert records that actorID did something the user should be notified about.
+// If the user already has an unread notification of the same type about the
+// same resource, the actor is added to it instead of creating a new one.
+func (r *NotificationRepository) Upsert(userID, actorID int, notificationType, resourceType string, resourceID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	query := `
+		INSERT INTO notifications (user_id, type, resource_type, resource_id, last_actor_id, actor_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 1, $6, $7)
+		ON CONFLICT (user_id, type, resource_type, resource_id) WHERE read_at IS NULL
+		DO UPDATE SET last_actor_id = EXCLUDED.last_actor_id, updated_at = EXCLUDED.updated_at
+		RETURNING id`
+
+	now := time.Now()
+	var notificationID int
+	err = tx.QueryRow(query, userID, notificationType, resourceType, resourceID, actorID, now, now).Scan(&notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO notification_actors (notification_id, actor_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (notification_id, actor_id) DO UPDATE SET created_at = EXCLUDED.created_at`,
+		notificationID, actorID, now)
+	if err != nil {
+		return fmt.Errorf("failed to save notification actor: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE notifications
+		SET actor_count = (SELECT COUNT(*) FROM notification_actors WHERE notification_id = $1)
+		WHERE id = $1`, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to update notification actor count: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetForUser retrieves a user's notifications, most recently updated first
+func (r *NotificationRepository) GetForUser(userID int, unreadOnly bool, limit, offset int) ([]*models.Notification, error) {
+	notifications := []*models.Notification{}
+	query := `
+		SELECT n.id, n.user_id, n.type, n.resource_type, n.resource_id, n.actor_count, n.read_at,
+		       n.created_at, n.updated_at,
+		       u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.created_at
+		FROM notifications n
+		LEFT JOIN users u ON n.last_actor_id = u.id
+		WHERE n.user_id = $1
+		AND (NOT $2 OR n.read_at IS NULL)
+		ORDER BY n.updated_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		notification := &models.Notification{}
+		var actorID sql.NullInt64
+		var actorName, actorUsername, actorPicture sql.NullString
+		var actorCreatedAt sql.NullTime
+		err := rows.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.ResourceType,
+			&notification.ResourceID, &notification.ActorCount, &notification.ReadAt,
+			&notification.CreatedAt, &notification.UpdatedAt,
+			&actorID, &actorName, &actorUsername, &actorPicture, &actorCreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if actorID.Valid {
+			notification.LastActor = &models.UserPublic{
+				ID:                int(actorID.Int64),
+				Name:              actorName.String,
+				Username:          actorUsername.String,
+				ProfilePictureURL: actorPicture.String,
+				CreatedAt:         actorCreatedAt.Time,
+			}
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+// CountUnread counts a user's unread notifications
+func (r *NotificationRepository) CountUnread(userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read
+func (r *NotificationRepository) MarkRead(notificationID, userID int) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $1)
+		WHERE id = $2 AND user_id = $3`
+
+	result, err := r.db.Exec(query, time.Now(), notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("notification not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all of a user's unread notifications as read
+func (r *NotificationRepository) MarkAllRead(userID int) error {
+	query := `UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`
+
+	if _, err := r.db.Exec(query, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+
+	return nil
+}
+
+// GetPreferences retrieves the notification preferences a user has set, keyed by type.
+// Types the user never changed are not included.
+func (r *NotificationRepository) GetPreferences(userID int) (map[string]bool, error) {
+	preferences := map[string]bool{}
+	query := `SELECT type, enabled FROM notification_preferences WHERE user_id = $1`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var notificationType string
+		var enabled bool
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		preferences[notificationType] = enabled
+	}
+
+	return preferences, nil
+}
+
+// IsEnabled reports whether a user wants notifications of a type. Types the
+// user never changed are enabled.
+func (r *NotificationRepository) IsEnabled(userID int, notificationType string) (bool, error) {
+	var enabled bool
+	query := `SELECT enabled FROM notification_preferences WHERE user_id = $1 AND type = $2`
+
+	err := r.db.QueryRow(query, userID, notificationType).Scan(&enabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// UpsertPreferences creates or updates a user's notification preferences
+func (r *NotificationRepository) UpsertPreferences(userID int, preferences map[string]bool) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, type, enabled, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, type) DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at`
+
+	now := time.Now()
+	for notificationType, enabled := range preferences {
+		if _, err := r.db.Exec(query, userID, notificationType, enabled, now); err != nil {
+			return fmt.Errorf("failed to save notification preference: %w", err)
+		}
+	}
+
+	return nil
+}
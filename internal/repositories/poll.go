@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// PollRepository provides methods for accessing poll data
+type PollRepository struct {
+	*BaseRepository
+}
+
+// NewPollRepository creates a new PollRepository
+func NewPollRepository(db *sql.DB) *PollRepository {
+	return &PollRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Create inserts a poll together with its options
+func (r *PollRepository) Create(poll *models.Poll) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	err = tx.QueryRow(`
+		INSERT INTO polls (post_id, multiple_choice, results_visibility, closes_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		poll.PostID, poll.MultipleChoice, poll.ResultsVisibility, poll.ClosesAt, time.Now()).
+		Scan(&poll.ID, &poll.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create poll: %w", err)
+	}
+
+	for i, option := range poll.Options {
+		option.Position = i
+		err = tx.QueryRow(`INSERT INTO poll_options (poll_id, position, text) VALUES ($1, $2, $3) RETURNING id`,
+			poll.ID, option.Position, option.Text).Scan(&option.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create poll option: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByPostID retrieves the poll of a post with its options
+func (r *PollRepository) GetByPostID(postID int) (*models.Poll, error) {
+	polls, err := r.GetPollsForPosts([]int{postID})
+	if err != nil {
+		return nil, err
+	}
+
+	poll, ok := polls[postID]
+	if !ok {
+		return nil, fmt.Errorf("poll not found: %w", sql.ErrNoRows)
+	}
+
+	return poll, nil
+}
+
+// GetPollsForPosts retrieves the polls of several posts with their options, keyed by post ID
+func (r *PollRepository) GetPollsForPosts(postIDs []int) (map[int]*models.Poll, error) {
+	polls := map[int]*models.Poll{}
+	if len(postIDs) == 0 {
+		return polls, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, post_id, multiple_choice, results_visibility, closes_at, created_at
+		FROM polls
+		WHERE post_id = ANY($1)`, pq.Array(postIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polls: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	byID := map[int]*models.Poll{}
+	pollIDs := []int{}
+	for rows.Next() {
+		poll := &models.Poll{Options: []*models.PollOption{}}
+		if err := rows.Scan(&poll.ID, &poll.PostID, &poll.MultipleChoice, &poll.ResultsVisibility,
+			&poll.ClosesAt, &poll.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan poll: %w", err)
+		}
+		polls[poll.PostID] = poll
+		byID[poll.ID] = poll
+		pollIDs = append(pollIDs, poll.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get polls: %w", err)
+	}
+	if len(pollIDs) == 0 {
+		return polls, nil
+	}
+
+	optionRows, err := r.db.Query(`
+		SELECT id, poll_id, position, text
+		FROM poll_options
+		WHERE poll_id = ANY($1)
+		ORDER BY poll_id, position`, pq.Array(pollIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll options: %w", err)
+	}
+	defer func() {
+		if closeErr := optionRows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for optionRows.Next() {
+		var pollID int
+		option := &models.PollOption{}
+		if err := optionRows.Scan(&option.ID, &pollID, &option.Position, &option.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan poll option: %w", err)
+		}
+		byID[pollID].Options = append(byID[pollID].Options, option)
+	}
+
+	return polls, nil
+}
+
+// GetVoteCounts retrieves the number of votes of every option of several polls, keyed by option ID
+func (r *PollRepository) GetVoteCounts(pollIDs []int) (map[int]int, error) {
+	return r.queryCounts(`
+		SELECT option_id, COUNT(*)
+		FROM poll_votes
+		WHERE poll_id = ANY($1)
+		GROUP BY option_id`, pollIDs)
+}
+
+// GetVoterCounts retrieves the number of users who voted in several polls, keyed by poll ID
+func (r *PollRepository) GetVoterCounts(pollIDs []int) (map[int]int, error) {
+	return r.queryCounts(`
+		SELECT poll_id, COUNT(DISTINCT user_id)
+		FROM poll_votes
+		WHERE poll_id = ANY($1)
+		GROUP BY poll_id`, pollIDs)
+}
+
+// GetUserVotes retrieves the options a user voted for in several polls, keyed by poll ID
+func (r *PollRepository) GetUserVotes(pollIDs []int, userID int) (map[int][]int, error) {
+	votes := map[int][]int{}
+	if len(pollIDs) == 0 {
+		return votes, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT v.poll_id, v.option_id
+		FROM poll_votes v
+		JOIN poll_options o ON o.id = v.option_id
+		WHERE v.poll_id = ANY($1) AND v.user_id = $2
+		ORDER BY v.poll_id, o.position`, pq.Array(pollIDs), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll votes: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var pollID, optionID int
+		if err := rows.Scan(&pollID, &optionID); err != nil {
+			return nil, fmt.Errorf("failed to scan poll vote: %w", err)
+		}
+		votes[pollID] = append(votes[pollID], optionID)
+	}
+
+	return votes, nil
+}
+
+// ReplaceVotes replaces the votes of a user in a poll with votes for the given options
+func (r *PollRepository) ReplaceVotes(pollID, userID int, optionIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	_, err = tx.Exec(`DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2`, pollID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear poll votes: %w", err)
+	}
+
+	now := time.Now()
+	for _, optionID := range optionIDs {
+		_, err = tx.Exec(`INSERT INTO poll_votes (poll_id, option_id, user_id, created_at) VALUES ($1, $2, $3, $4)`,
+			pollID, optionID, userID, now)
+		if err != nil {
+			return fmt.Errorf("failed to save poll vote: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteVotes removes the votes of a user in a poll
+func (r *PollRepository) DeleteVotes(pollID, userID int) error {
+	_, err := r.db.Exec(`DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2`, pollID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete poll votes: %w", err)
+	}
+	return nil
+}
+
+// queryCounts runs a query over poll IDs that returns (key, count) rows
+func (r *PollRepository) queryCounts(query string, pollIDs []int) (map[int]int, error) {
+	counts := map[int]int{}
+	if len(pollIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(query, pq.Array(pollIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll results: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var key, count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan poll results: %w", err)
+		}
+		counts[key] = count
+	}
+
+	return counts, nil
+}
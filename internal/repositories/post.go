@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/gocli/social_api/internal/models"
 )
 
@@ -18,18 +20,24 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 	return &PostRepository{BaseRepository: NewBaseRepository(db)}
 }
 
-// Create inserts a new post into the database
+// Create inserts a new post into the database. Publishing the same draft twice returns ErrConflict.
 func (r *PostRepository) Create(post *models.Post) error {
 	query := `
-		INSERT INTO posts (user_id, content, privacy, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO posts (user_id, content, privacy, comment_permission, shared_post_id, draft_id, held_at, held_privacy,
+		                   created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10)
 		RETURNING id, created_at, updated_at`
 
 	now := time.Now()
-	err := r.db.QueryRow(query, post.UserID, post.Content, post.Privacy, now, now).
+	err := r.db.QueryRow(query, post.UserID, post.Content, post.Privacy, post.CommentPermission, post.SharedPostID,
+		post.DraftID, post.HeldAt, post.HeldPrivacy, now, now).
 		Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			// The draft was already published
+			return fmt.Errorf("failed to create post: %w", ErrConflict)
+		}
 		return fmt.Errorf("failed to create post: %w", err)
 	}
 
@@ -40,12 +48,13 @@ func (r *PostRepository) Create(post *models.Post) error {
 func (r *PostRepository) GetByID(id int) (*models.Post, error) {
 	post := &models.Post{}
 	query := `
-		SELECT id, user_id, content, privacy, created_at, updated_at
+		SELECT id, user_id, content, privacy, comment_permission, shared_post_id, edited_at, moderated_at, held_at,
+		       created_at, updated_at
 		FROM posts
 		WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(&post.ID, &post.UserID, &post.Content,
-		&post.Privacy, &post.CreatedAt, &post.UpdatedAt)
+	err := r.db.QueryRow(query, id).Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission,
+		&post.SharedPostID, &post.EditedAt, &post.ModeratedAt, &post.HeldAt, &post.CreatedAt, &post.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -53,21 +62,72 @@ func (r *PostRepository) GetByID(id int) (*models.Post, error) {
 		}
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
+	post.Edited = post.EditedAt != nil
 
 	return post, nil
 }
 
-// GetPostsByUserID retrieves posts for a specific user
-func (r *PostRepository) GetPostsByUserID(userID int, limit, offset int) ([]*models.Post, error) {
+// Moderate hides a post from everyone but its author on behalf of a site moderator
+func (r *PostRepository) Moderate(id int) error {
+	query := `UPDATE posts SET privacy = $1, moderated_at = COALESCE(moderated_at, $2) WHERE id = $3`
+	if _, err := r.db.Exec(query, models.PrivacyOnlyMe, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to moderate post: %w", err)
+	}
+	return nil
+}
+
+// Hold hides a post from everyone but its author until it is reviewed, remembering its privacy
+func (r *PostRepository) Hold(id int) error {
+	query := `
+		UPDATE posts
+		SET held_privacy = COALESCE(held_privacy, privacy), privacy = $1, held_at = COALESCE(held_at, $2)
+		WHERE id = $3`
+
+	if _, err := r.db.Exec(query, models.PrivacyOnlyMe, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to hold post: %w", err)
+	}
+	return nil
+}
+
+// Release publishes a held post with the privacy it had when it was held.
+// A post a moderator hid in the meantime stays hidden.
+func (r *PostRepository) Release(id int) error {
+	query := `
+		UPDATE posts
+		SET privacy = CASE WHEN moderated_at IS NULL THEN COALESCE(held_privacy, privacy) ELSE privacy END,
+		    held_privacy = NULL, held_at = NULL
+		WHERE id = $1 AND held_at IS NOT NULL`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to release post: %w", err)
+	}
+	return nil
+}
+
+// CountRecentDuplicates counts the user's posts with exactly this content created since the given time
+func (r *PostRepository) CountRecentDuplicates(userID int, content string, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM posts WHERE user_id = $1 AND content = $2 AND created_at >= $3`
+	if err := r.db.QueryRow(query, userID, content, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count duplicate posts: %w", err)
+	}
+	return count, nil
+}
+
+// GetPostsByUserID retrieves the posts of a specific user that viewerID can see. Posts held for review
+// are only listed for their author.
+func (r *PostRepository) GetPostsByUserID(userID, viewerID int, limit, offset int) ([]*models.Post, error) {
 	posts := []*models.Post{}
 	query := `
-		SELECT id, user_id, content, privacy, created_at, updated_at
-		FROM posts
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+		SELECT p.id, p.user_id, p.content, p.privacy, p.comment_permission, p.shared_post_id, p.edited_at, p.created_at, p.updated_at
+		FROM posts p
+		WHERE p.user_id = $1
+		AND ` + visiblePostCondition("p", "$2") + `
+		AND (p.held_at IS NULL OR p.user_id = $2)
+		ORDER BY p.created_at DESC
+		LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.Query(query, userID, viewerID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts: %w", err)
 	}
@@ -81,34 +141,82 @@ func (r *PostRepository) GetPostsByUserID(userID int, limit, offset int) ([]*mod
 
 	for rows.Next() {
 		post := &models.Post{}
-		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CreatedAt, &post.UpdatedAt)
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission, &post.SharedPostID, &post.EditedAt, &post.CreatedAt, &post.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
+		post.Edited = post.EditedAt != nil
 		posts = append(posts, post)
 	}
 
 	return posts, nil
 }
 
+// postContentTypeQuery is the content type of post p as seen by the feed: text, image, link, poll or share
+const postContentTypeQuery = `
+	CASE
+	    WHEN p.shared_post_id IS NOT NULL THEN 'share'
+	    WHEN EXISTS (SELECT 1 FROM polls WHERE post_id = p.id) THEN 'poll'
+	    WHEN EXISTS (SELECT 1 FROM post_attachments WHERE post_id = p.id AND type = 'image') THEN 'image'
+	    WHEN EXISTS (SELECT 1 FROM post_attachments WHERE post_id = p.id AND type = 'link') THEN 'link'
+	    ELSE 'text'
+	END`
+
+// feedFilterQuery keeps the posts p that user $1 may see in their feed and that match the content
+// type $4 and author $5 when those are set. Posts the user hid are left out, as are posts by users they
+// muted or snoozed as of $6, unless the feed is filtered to that author, and posts by restricted users.
+const feedFilterQuery = `
+	(p.privacy = 'public' OR p.privacy = 'friends' OR (p.privacy = 'only_me' AND p.user_id = $1))
+	AND (p.user_id = $1 OR NOT EXISTS (SELECT 1 FROM users ru WHERE ru.id = p.user_id AND ru.restricted_until IS NOT NULL))
+	AND ($4 = '' OR ` + postContentTypeQuery + ` = $4)
+	AND ($5 = 0 OR p.user_id = $5)
+	AND NOT EXISTS (SELECT 1 FROM hidden_posts h WHERE h.user_id = $1 AND h.post_id = p.id)
+	AND ($5 <> 0 OR NOT EXISTS (
+	    SELECT 1 FROM feed_mutes m
+	    WHERE m.user_id = $1 AND m.muted_user_id = p.user_id AND (m.expires_at IS NULL OR m.expires_at > $6)
+	))`
+
+// feedPostsQuery selects the IDs of the posts in the feed of user $1 created since $2 and matching
+// feedFilterQuery, at most $3 of each kind. Most posts come from the user's timeline; posts that have not
+// been fanned out yet, or whose author has too many friends to fan out, are read from posts. Timeline
+// entries are checked against the current friendships in case an unfriending raced with a fan-out.
+const feedPostsQuery = `
+	(
+	    SELECT p.id, p.created_at
+	    FROM timelines t
+	    JOIN posts p ON p.id = t.post_id
+	    WHERE t.user_id = $1 AND t.created_at >= $2
+	    AND (t.author_id = $1 OR EXISTS (SELECT 1 FROM friends WHERE user_id = $1 AND friend_id = t.author_id))
+	    AND ` + feedFilterQuery + `
+	    ORDER BY t.created_at DESC
+	    LIMIT $3
+	)
+	UNION
+	(
+	    SELECT p.id, p.created_at
+	    FROM posts p
+	    WHERE p.fanout_status <> 'done' AND p.created_at >= $2
+	    AND (p.user_id = $1 OR p.user_id IN (SELECT friend_id FROM friends WHERE user_id = $1))
+	    AND ` + feedFilterQuery + `
+	    ORDER BY p.created_at DESC
+	    LIMIT $3
+	)`
+
 // GetFeed retrieves posts for a user's feed
-func (r *PostRepository) GetFeed(userID int, limit, offset int) ([]*models.PostWithUser, error) {
+func (r *PostRepository) GetFeed(userID int, filter models.FeedFilter, limit, offset int) ([]*models.PostWithUser, error) {
 	posts := []*models.PostWithUser{}
 	query := `
-		SELECT p.id, p.user_id, p.content, p.privacy, p.created_at, p.updated_at,
+		SELECT p.id, p.user_id, p.content, p.privacy, p.comment_permission, p.shared_post_id, p.edited_at, p.created_at, p.updated_at,
 		       u.name, u.profile_picture_url
-		FROM posts p
+		FROM (` + feedPostsQuery + `) feed
+		JOIN posts p ON p.id = feed.id
 		JOIN users u ON p.user_id = u.id
-		WHERE p.user_id IN (
-		    SELECT friend_id FROM friends WHERE user_id = $1
-		    UNION
-		    SELECT $1
-		)
-		AND (p.privacy = 'public' OR p.privacy = 'friends' OR (p.privacy = 'only_me' AND p.user_id = $1))
-		ORDER BY p.created_at DESC
-		LIMIT $2 OFFSET $3`
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $7 OFFSET $8`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	// Each source needs at most the posts up to the end of the page
+	rows, err := r.db.Query(query, userID, time.Time{}, offset+limit, filter.ContentType, filter.AuthorID, time.Now(),
+		limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed: %w", err)
 	}
@@ -122,35 +230,247 @@ func (r *PostRepository) GetFeed(userID int, limit, offset int) ([]*models.PostW
 
 	for rows.Next() {
 		post := &models.PostWithUser{}
-		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy,
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission, &post.SharedPostID, &post.EditedAt,
 			&post.CreatedAt, &post.UpdatedAt, &post.UserName, &post.ProfilePictureURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
+		post.Edited = post.EditedAt != nil
 		posts = append(posts, post)
 	}
 
 	return posts, nil
 }
 
-// Update updates a post
-func (r *PostRepository) Update(post *models.Post) error {
+// GetFeedCandidates retrieves up to limit of the newest posts for a user's feed created since the given time,
+// with their engagement and the user's affinity with each author over the same period
+func (r *PostRepository) GetFeedCandidates(userID int, filter models.FeedFilter, since time.Time, limit int) ([]*models.FeedCandidate, error) {
+	candidates := []*models.FeedCandidate{}
 	query := `
-		UPDATE posts
-		SET content = $1, privacy = $2, updated_at = $3
-		WHERE id = $4`
+		WITH affinity AS (
+		    SELECT p.user_id AS author_id, COUNT(*) AS interactions
+		    FROM (
+		        SELECT resource_id FROM reactions WHERE user_id = $1 AND resource_type = 'posts' AND created_at >= $2
+		        UNION ALL
+		        SELECT resource_id FROM comments WHERE user_id = $1 AND resource_type = 'posts' AND created_at >= $2
+		    ) i
+		    JOIN posts p ON p.id = i.resource_id
+		    GROUP BY p.user_id
+		)
+		SELECT p.id, p.user_id, p.content, p.privacy, p.comment_permission, p.shared_post_id, p.edited_at, p.created_at, p.updated_at,
+		       u.name, u.profile_picture_url,
+		       ` + postContentTypeQuery + `,
+		       (SELECT COUNT(*) FROM reactions WHERE resource_type = 'posts' AND resource_id = p.id),
+		       (SELECT COUNT(*) FROM comments WHERE resource_type = 'posts' AND resource_id = p.id AND hidden_at IS NULL),
+		       COALESCE(a.interactions, 0)
+		FROM (` + feedPostsQuery + `) feed
+		JOIN posts p ON p.id = feed.id
+		JOIN users u ON p.user_id = u.id
+		LEFT JOIN affinity a ON a.author_id = p.user_id
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $3`
 
-	now := time.Now()
-	_, err := r.db.Exec(query, post.Content, post.Privacy, now, post.ID)
+	rows, err := r.db.Query(query, userID, since, limit, filter.ContentType, filter.AuthorID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed candidates: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		post := &models.PostWithUser{}
+		candidate := &models.FeedCandidate{Post: post}
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission, &post.SharedPostID, &post.EditedAt,
+			&post.CreatedAt, &post.UpdatedAt, &post.UserName, &post.ProfilePictureURL,
+			&candidate.ContentType, &candidate.ReactionCount, &candidate.CommentCount, &candidate.Affinity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feed candidate: %w", err)
+		}
+		post.Edited = post.EditedAt != nil
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// GetPostsWithUserByIDs retrieves the given posts with their authors, keyed by post ID.
+// Posts that no longer exist are left out.
+func (r *PostRepository) GetPostsWithUserByIDs(ids []int) (map[int]*models.PostWithUser, error) {
+	posts := make(map[int]*models.PostWithUser)
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	query := `
+		SELECT p.id, p.user_id, p.content, p.privacy, p.comment_permission, p.shared_post_id, p.edited_at,
+		       p.created_at, p.updated_at, u.name, u.profile_picture_url
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.id = ANY($1)`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		post := &models.PostWithUser{}
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission, &post.SharedPostID,
+			&post.EditedAt, &post.CreatedAt, &post.UpdatedAt, &post.UserName, &post.ProfilePictureURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		post.Edited = post.EditedAt != nil
+		posts[post.ID] = post
+	}
+
+	return posts, nil
+}
+
+// GetShareCounts counts the shares of each of the given posts, keyed by post ID
+func (r *PostRepository) GetShareCounts(ids []int) (map[int]int, error) {
+	counts := make(map[int]int)
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT shared_post_id, COUNT(*)
+		FROM posts
+		WHERE shared_post_id = ANY($1)
+		GROUP BY shared_post_id`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shares: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var postID, count int
+		if err := rows.Scan(&postID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan share count: %w", err)
+		}
+		counts[postID] = count
+	}
+
+	return counts, nil
+}
+
+// Search searches the posts visible to the viewer using a prefix tsquery,
+// ranking by text relevance and then by recency
+func (r *PostRepository) Search(tsQuery string, viewerID int, limit, offset int) ([]*models.PostWithUser, error) {
+	posts := []*models.PostWithUser{}
+	query := `
+		SELECT p.id, p.user_id, p.content, p.privacy, p.comment_permission, p.shared_post_id, p.edited_at, p.created_at, p.updated_at,
+		       u.name, u.profile_picture_url
+		FROM posts p
+		JOIN users u ON p.user_id = u.id,
+		     to_tsquery('simple', $1) query
+		WHERE p.search_vector @@ query
+		AND ` + visiblePostCondition("p", "$2") + `
+		ORDER BY ts_rank(p.search_vector, query) DESC, p.created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(query, tsQuery, viewerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		post := &models.PostWithUser{}
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission, &post.SharedPostID, &post.EditedAt,
+			&post.CreatedAt, &post.UpdatedAt, &post.UserName, &post.ProfilePictureURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		post.Edited = post.EditedAt != nil
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// Update updates a post. A content change keeps the previous content as a revision by editorID.
+func (r *PostRepository) Update(post *models.Post, editorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
 
+	now := time.Now()
+	editedAt, err := updateContentWithRevision(tx, models.ResourceTypePosts, post.ID, editorID, post.Content, now)
 	if err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
 
+	query := `
+		UPDATE posts
+		SET privacy = $1, updated_at = $2
+		WHERE id = $3`
+
+	if _, err := tx.Exec(query, post.Privacy, now, post.ID); err != nil {
+		return fmt.Errorf("failed to update post: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if editedAt != nil {
+		post.EditedAt = editedAt
+		post.Edited = true
+	}
 	post.UpdatedAt = now
 	return nil
 }
 
+// SetCommentPermission sets who may comment on a post
+func (r *PostRepository) SetCommentPermission(postID int, permission string) error {
+	query := `
+		UPDATE posts
+		SET comment_permission = $1, updated_at = $2
+		WHERE id = $3`
+
+	_, err := r.db.Exec(query, permission, time.Now(), postID)
+	if err != nil {
+		return fmt.Errorf("failed to set comment permission: %w", err)
+	}
+	return nil
+}
+
 // Delete deletes a post
 func (r *PostRepository) Delete(id int) error {
 	query := `DELETE FROM posts WHERE id = $1`
@@ -162,3 +482,14 @@ func (r *PostRepository) Delete(id int) error {
 
 	return nil
 }
+
+// visiblePostCondition returns a SQL condition that matches the posts (aliased as alias)
+// the viewer bound to viewerParam is allowed to see. Posts by restricted users are only visible to them.
+func visiblePostCondition(alias, viewerParam string) string {
+	return fmt.Sprintf(`(%[1]s.user_id = %[2]s
+		OR (%[3]s AND (
+		    %[1]s.privacy IN ('public', '')
+		    OR (%[1]s.privacy = 'friends' AND EXISTS (
+		        SELECT 1 FROM friends vf WHERE vf.user_id = %[2]s AND vf.friend_id = %[1]s.user_id)))))`,
+		alias, viewerParam, unrestrictedUserCondition(alias+".user_id"))
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// ReportRepository provides methods for accessing reports and the moderation audit trail
+type ReportRepository struct {
+	*BaseRepository
+}
+
+// NewReportRepository creates a new ReportRepository
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Create inserts a new report. A user who already has an open report on the same content gets ErrConflict.
+func (r *ReportRepository) Create(report *models.Report) error {
+	query := `
+		INSERT INTO reports (reporter_id, resource_type, resource_id, reason, details, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	report.Status = models.ReportStatusOpen
+	err := r.db.QueryRow(query, report.ReporterID, report.ResourceType, report.ResourceID, report.Reason,
+		report.Details, report.Status, time.Now()).Scan(&report.ID, &report.CreatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to create report: %w", ErrConflict)
+		}
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	return nil
+}
+
+// GetQueue retrieves the content with open reports, the most reported first and then the longest waiting
+func (r *ReportRepository) GetQueue(limit, offset int) ([]*models.ReportQueueItem, error) {
+	items := []*models.ReportQueueItem{}
+	query := `
+		SELECT resource_type, resource_id, COUNT(*), MIN(created_at), MAX(created_at),
+		       JSON_OBJECT_AGG(reason, reason_count)
+		FROM (
+		    SELECT resource_type, resource_id, reason, COUNT(*) AS reason_count, MIN(created_at) AS created_at
+		    FROM reports
+		    WHERE status = $1
+		    GROUP BY resource_type, resource_id, reason
+		) r
+		GROUP BY resource_type, resource_id
+		ORDER BY SUM(reason_count) DESC, MIN(created_at)
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(query, models.ReportStatusOpen, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report queue: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		item := &models.ReportQueueItem{}
+		var reasons []byte
+		err := rows.Scan(&item.ResourceType, &item.ResourceID, &item.ReportCount, &item.FirstReportedAt,
+			&item.LatestReportedAt, &reasons)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report queue item: %w", err)
+		}
+		if err := decodeReasonCounts(reasons, &item.Reasons); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// GetReportsForResource retrieves the reports on a post, comment, photo or profile, newest first
+func (r *ReportRepository) GetReportsForResource(resourceType string, resourceID int) ([]*models.Report, error) {
+	reports := []*models.Report{}
+	query := `
+		SELECT id, reporter_id, resource_type, resource_id, reason, details, status, reviewed_by, reviewed_at, created_at
+		FROM reports
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		report := &models.Report{}
+		err := rows.Scan(&report.ID, &report.ReporterID, &report.ResourceType, &report.ResourceID, &report.Reason,
+			&report.Details, &report.Status, &report.ReviewedBy, &report.ReviewedAt, &report.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// RecordAction records a moderation action and closes the open reports on its content with the given status.
+// An empty status leaves the reports open.
+func (r *ReportRepository) RecordAction(action *models.ModerationAction, reportStatus string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	now := time.Now()
+	if reportStatus != "" {
+		result, err := tx.Exec(`
+			UPDATE reports
+			SET status = $1, reviewed_by = $2, reviewed_at = $3
+			WHERE resource_type = $4 AND resource_id = $5 AND status = $6`,
+			reportStatus, action.ModeratorID, now, action.ResourceType, action.ResourceID, models.ReportStatusOpen)
+		if err != nil {
+			return fmt.Errorf("failed to resolve reports: %w", err)
+		}
+		resolved, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to resolve reports: %w", err)
+		}
+		action.ReportsResolved = int(resolved)
+	}
+
+	query := `
+		INSERT INTO moderation_actions (moderator_id, action, resource_type, resource_id, target_user_id, note,
+		                                expires_at, reports_resolved, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(query, action.ModeratorID, action.Action, action.ResourceType, action.ResourceID,
+		action.TargetUserID, action.Note, action.ExpiresAt, action.ReportsResolved, now).
+		Scan(&action.ID, &action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetActions retrieves moderation actions newest first, optionally only those against one user
+func (r *ReportRepository) GetActions(targetUserID *int, limit, offset int) ([]*models.ModerationAction, error) {
+	actions := []*models.ModerationAction{}
+	query := `
+		SELECT id, moderator_id, action, resource_type, resource_id, target_user_id, note, expires_at,
+		       reports_resolved, created_at
+		FROM moderation_actions
+		WHERE $1::INTEGER IS NULL OR target_user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(query, targetUserID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation actions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		action := &models.ModerationAction{}
+		err := rows.Scan(&action.ID, &action.ModeratorID, &action.Action, &action.ResourceType, &action.ResourceID,
+			&action.TargetUserID, &action.Note, &action.ExpiresAt, &action.ReportsResolved, &action.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// decodeReasonCounts unpacks the reason -> count object aggregated by GetQueue
+func decodeReasonCounts(raw []byte, reasons *map[string]int) error {
+	if err := json.Unmarshal(raw, reasons); err != nil {
+		return fmt.Errorf("failed to decode report reasons: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// ResourceRepository provides methods for data attached to any likeable or commentable resource
+type ResourceRepository struct {
+	*BaseRepository
+}
+
+// NewResourceRepository creates a new ResourceRepository
+func NewResourceRepository(db *sql.DB) *ResourceRepository {
+	return &ResourceRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// DeleteInteractions removes the reactions, comments, notifications and saves attached to deleted resources,
+// together with the reactions, mentions, edit history and notifications of those comments
+func (r *ResourceRepository) DeleteInteractions(resourceType string, resourceIDs []int) error {
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	ids := pq.Array(resourceIDs)
+	commentIDs := `SELECT id FROM comments WHERE resource_type = $1 AND resource_id = ANY($2)`
+
+	// Everything that points at the comments goes first, then the comments themselves
+	for _, table := range []string{"reactions", "mentions", "content_revisions", "notifications"} {
+		query := `DELETE FROM ` + table + ` WHERE resource_type = $3 AND resource_id IN (` + commentIDs + `)`
+		if _, err := tx.Exec(query, resourceType, ids, models.ResourceTypeComments); err != nil {
+			return fmt.Errorf("failed to delete comment %s: %w", table, err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM comments WHERE resource_type = $1 AND resource_id = ANY($2)`, resourceType, ids); err != nil {
+		return fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	for _, table := range []string{"reactions", "notifications", "saved_items"} {
+		query := `DELETE FROM ` + table + ` WHERE resource_type = $1 AND resource_id = ANY($2)`
+		if _, err := tx.Exec(query, resourceType, ids); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
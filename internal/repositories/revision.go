@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// RevisionRepository provides methods for accessing the edit history of posts and comments
+type RevisionRepository struct {
+	*BaseRepository
+}
+
+// NewRevisionRepository creates a new RevisionRepository
+func NewRevisionRepository(db *sql.DB) *RevisionRepository {
+	return &RevisionRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// GetRevisions retrieves the previous versions of a resource's content, newest first
+func (r *RevisionRepository) GetRevisions(resourceType string, resourceID int) ([]*models.ContentRevision, error) {
+	revisions := []*models.ContentRevision{}
+	query := `
+		SELECT id, resource_type, resource_id, content, edited_by, created_at
+		FROM content_revisions
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		revision := &models.ContentRevision{}
+		err := rows.Scan(&revision.ID, &revision.ResourceType, &revision.ResourceID, &revision.Content,
+			&revision.EditedBy, &revision.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+// DeleteRevisions removes the edit history of a resource
+func (r *RevisionRepository) DeleteRevisions(resourceType string, resourceID int) error {
+	query := `DELETE FROM content_revisions WHERE resource_type = $1 AND resource_id = $2`
+	_, err := r.db.Exec(query, resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete revisions: %w", err)
+	}
+	return nil
+}
+
+// updateContentWithRevision replaces the content of a post or comment inside tx.
+// If the content changes, the previous version is stored as a revision and edited_at is set.
+// The table is named after the resource type. It returns the edit time, or nil if the content was unchanged.
+func updateContentWithRevision(tx *sql.Tx, resourceType string, resourceID, editorID int, content string, now time.Time) (*time.Time, error) {
+	query := `
+		INSERT INTO content_revisions (resource_type, resource_id, content, edited_by, created_at)
+		SELECT $1, id, content, $3, $4
+		FROM ` + resourceType + `
+		WHERE id = $2 AND content <> $5`
+
+	result, err := tx.Exec(query, resourceType, resourceID, editorID, now, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save revision: %w", err)
+	}
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save revision: %w", err)
+	}
+	if saved == 0 {
+		return nil, nil
+	}
+
+	query = `UPDATE ` + resourceType + ` SET content = $1, edited_at = $2 WHERE id = $3`
+	if _, err := tx.Exec(query, content, now, resourceID); err != nil {
+		return nil, fmt.Errorf("failed to update content: %w", err)
+	}
+
+	return &now, nil
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// SavedRepository provides methods for accessing saved items and their collections
+type SavedRepository struct {
+	*BaseRepository
+}
+
+// NewSavedRepository creates a new SavedRepository
+func NewSavedRepository(db *sql.DB) *SavedRepository {
+	return &SavedRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// CreateCollection inserts a new collection. A user already having a collection with the same name returns ErrConflict.
+func (r *SavedRepository) CreateCollection(collection *models.Collection) error {
+	query := `
+		INSERT INTO collections (user_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+
+	now := time.Now()
+	err := r.db.QueryRow(query, collection.UserID, collection.Name, now, now).
+		Scan(&collection.ID, &collection.CreatedAt, &collection.UpdatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to create collection: %w", ErrConflict)
+		}
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return nil
+}
+
+// GetCollectionByID retrieves a collection by ID with its item count
+func (r *SavedRepository) GetCollectionByID(id int) (*models.Collection, error) {
+	collection := &models.Collection{}
+	query := `
+		SELECT c.id, c.user_id, c.name, c.created_at, c.updated_at,
+		       (SELECT COUNT(*) FROM saved_items WHERE collection_id = c.id)
+		FROM collections c
+		WHERE c.id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(&collection.ID, &collection.UserID, &collection.Name,
+		&collection.CreatedAt, &collection.UpdatedAt, &collection.ItemCount)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("collection not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// GetCollectionsByUserID retrieves a user's collections with their item counts, by name
+func (r *SavedRepository) GetCollectionsByUserID(userID int) ([]*models.Collection, error) {
+	collections := []*models.Collection{}
+	query := `
+		SELECT c.id, c.user_id, c.name, c.created_at, c.updated_at,
+		       (SELECT COUNT(*) FROM saved_items WHERE collection_id = c.id)
+		FROM collections c
+		WHERE c.user_id = $1
+		ORDER BY LOWER(c.name)`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		collection := &models.Collection{}
+		err := rows.Scan(&collection.ID, &collection.UserID, &collection.Name,
+			&collection.CreatedAt, &collection.UpdatedAt, &collection.ItemCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, nil
+}
+
+// RenameCollection updates a collection's name. A user already having a collection with the new name returns ErrConflict.
+func (r *SavedRepository) RenameCollection(collection *models.Collection) error {
+	query := `
+		UPDATE collections
+		SET name = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(query, collection.Name, time.Now(), collection.ID).Scan(&collection.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to rename collection: %w", ErrConflict)
+		}
+		return fmt.Errorf("failed to rename collection: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCollection deletes a collection. Its items stay saved outside any collection.
+func (r *SavedRepository) DeleteCollection(id int) error {
+	if _, err := r.db.Exec(`DELETE FROM collections WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// SaveItem saves a post or photo for a user. Saving an item again moves it to the item's collection.
+func (r *SavedRepository) SaveItem(item *models.SavedItem) error {
+	query := `
+		INSERT INTO saved_items (user_id, resource_type, resource_id, collection_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, resource_type, resource_id) DO UPDATE SET collection_id = EXCLUDED.collection_id
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(query, item.UserID, item.ResourceType, item.ResourceID, item.CollectionID, time.Now()).
+		Scan(&item.ID, &item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save item: %w", err)
+	}
+
+	return nil
+}
+
+// UnsaveItem removes a saved post or photo
+func (r *SavedRepository) UnsaveItem(userID int, resourceType string, resourceID int) error {
+	query := `DELETE FROM saved_items WHERE user_id = $1 AND resource_type = $2 AND resource_id = $3`
+	if _, err := r.db.Exec(query, userID, resourceType, resourceID); err != nil {
+		return fmt.Errorf("failed to unsave item: %w", err)
+	}
+	return nil
+}
+
+// GetSavedItems retrieves a user's saved items, most recently saved first.
+// With a collection ID, only the items in that collection are returned.
+func (r *SavedRepository) GetSavedItems(userID int, collectionID *int, limit, offset int) ([]*models.SavedItem, error) {
+	items := []*models.SavedItem{}
+	query := `
+		SELECT id, user_id, resource_type, resource_id, collection_id, created_at
+		FROM saved_items
+		WHERE user_id = $1 AND ($2::INTEGER IS NULL OR collection_id = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(query, userID, collectionID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved items: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		item := &models.SavedItem{}
+		err := rows.Scan(&item.ID, &item.UserID, &item.ResourceType, &item.ResourceID, &item.CollectionID, &item.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
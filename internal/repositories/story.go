@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// StoryRepository provides methods for accessing story data
+type StoryRepository struct {
+	*BaseRepository
+}
+
+// NewStoryRepository creates a new StoryRepository
+func NewStoryRepository(db *sql.DB) *StoryRepository {
+	return &StoryRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Create inserts a new story into the database
+func (r *StoryRepository) Create(story *models.Story) error {
+	query := `
+		INSERT INTO stories (user_id, type, content, media_url, privacy, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	err := r.db.QueryRow(query, story.UserID, story.Type, story.Content, story.MediaURL, story.Privacy,
+		story.ExpiresAt, story.CreatedAt).Scan(&story.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create story: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a story by ID, whether or not it has expired
+func (r *StoryRepository) GetByID(id int) (*models.Story, error) {
+	story := &models.Story{}
+	query := `
+		SELECT id, user_id, type, content, media_url, privacy, expires_at, created_at
+		FROM stories
+		WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(&story.ID, &story.UserID, &story.Type, &story.Content, &story.MediaURL,
+		&story.Privacy, &story.ExpiresAt, &story.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("story not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get story: %w", err)
+	}
+
+	return story, nil
+}
+
+// GetActiveStoriesByUser retrieves the stories of a user that have not expired by now and
+// that the viewer is allowed to see, oldest first, marking those the viewer has seen
+func (r *StoryRepository) GetActiveStoriesByUser(userID, viewerID int, now time.Time) ([]*models.Story, error) {
+	stories := []*models.Story{}
+	// Stories have the same user_id and privacy columns as posts
+	query := `
+		SELECT s.id, s.user_id, s.type, s.content, s.media_url, s.privacy, s.expires_at, s.created_at,
+		       EXISTS (SELECT 1 FROM story_views sv WHERE sv.story_id = s.id AND sv.viewer_id = $2)
+		FROM stories s
+		WHERE s.user_id = $1 AND s.expires_at > $3
+		AND ` + visiblePostCondition("s", "$2") + `
+		ORDER BY s.created_at, s.id`
+
+	rows, err := r.db.Query(query, userID, viewerID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stories: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		story := &models.Story{}
+		if err := rows.Scan(&story.ID, &story.UserID, &story.Type, &story.Content, &story.MediaURL, &story.Privacy,
+			&story.ExpiresAt, &story.CreatedAt, &story.Seen); err != nil {
+			return nil, fmt.Errorf("failed to scan story: %w", err)
+		}
+		stories = append(stories, story)
+	}
+
+	return stories, nil
+}
+
+// GetTray retrieves the friends of the viewer who have active stories the viewer may see.
+// Friends with stories the viewer has not seen come first, then the most recent stories.
+func (r *StoryRepository) GetTray(viewerID int, now time.Time, limit, offset int) ([]*models.StoryTrayItem, error) {
+	items := []*models.StoryTrayItem{}
+	query := `
+		SELECT u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at,
+		       COUNT(*), MAX(s.created_at),
+		       BOOL_OR(NOT EXISTS (SELECT 1 FROM story_views sv WHERE sv.story_id = s.id AND sv.viewer_id = $1)) AS has_unseen
+		FROM friends f
+		JOIN stories s ON s.user_id = f.friend_id
+		JOIN users u ON u.id = f.friend_id
+		WHERE f.user_id = $1 AND s.expires_at > $2
+		AND ` + visiblePostCondition("s", "$1") + `
+		GROUP BY u.id
+		ORDER BY has_unseen DESC, MAX(s.created_at) DESC, u.id
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(query, viewerID, now, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stories tray: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		item := &models.StoryTrayItem{User: &models.UserPublic{}}
+		if err := rows.Scan(&item.User.ID, &item.User.Name, &item.User.Username, &item.User.ProfilePictureURL,
+			&item.User.CoverPhotoURL, &item.User.CreatedAt, &item.StoryCount, &item.LatestStoryAt,
+			&item.HasUnseen); err != nil {
+			return nil, fmt.Errorf("failed to scan stories tray: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// RecordView records that a user has seen a story. Seeing a story again keeps the first view.
+func (r *StoryRepository) RecordView(storyID, viewerID int, viewedAt time.Time) error {
+	query := `
+		INSERT INTO story_views (story_id, viewer_id, viewed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (story_id, viewer_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, storyID, viewerID, viewedAt); err != nil {
+		return fmt.Errorf("failed to record story view: %w", err)
+	}
+	return nil
+}
+
+// GetViewers retrieves the users who have seen a story, most recent first
+func (r *StoryRepository) GetViewers(storyID int, limit, offset int) ([]*models.StoryView, error) {
+	views := []*models.StoryView{}
+	query := `
+		SELECT u.id, u.name, COALESCE(u.username, ''), u.profile_picture_url, u.cover_photo_url, u.created_at, sv.viewed_at
+		FROM story_views sv
+		JOIN users u ON u.id = sv.viewer_id
+		WHERE sv.story_id = $1
+		ORDER BY sv.viewed_at DESC, u.id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(query, storyID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story viewers: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		view := &models.StoryView{Viewer: &models.UserPublic{}}
+		if err := rows.Scan(&view.Viewer.ID, &view.Viewer.Name, &view.Viewer.Username, &view.Viewer.ProfilePictureURL,
+			&view.Viewer.CoverPhotoURL, &view.Viewer.CreatedAt, &view.ViewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan story viewer: %w", err)
+		}
+		views = append(views, view)
+	}
+
+	return views, nil
+}
+
+// GetViewCounts counts the viewers of each of the given stories, keyed by story ID
+func (r *StoryRepository) GetViewCounts(storyIDs []int) (map[int]int, error) {
+	counts := map[int]int{}
+	if len(storyIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT story_id, COUNT(*)
+		FROM story_views
+		WHERE story_id = ANY($1)
+		GROUP BY story_id`
+
+	rows, err := r.db.Query(query, pq.Array(storyIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count story views: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var storyID, count int
+		if err := rows.Scan(&storyID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan story view count: %w", err)
+		}
+		counts[storyID] = count
+	}
+
+	return counts, nil
+}
+
+// Delete deletes a story
+func (r *StoryRepository) Delete(id int) error {
+	if _, err := r.db.Exec(`DELETE FROM stories WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete story: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes up to limit stories that expired by now and returns the media URLs
+// they used. Rows another server is deleting at the same moment are skipped.
+func (r *StoryRepository) DeleteExpired(now time.Time, limit int) ([]string, error) {
+	mediaURLs := []string{}
+	query := `
+		DELETE FROM stories
+		WHERE id IN (
+		    SELECT id FROM stories
+		    WHERE expires_at <= $1
+		    ORDER BY expires_at
+		    LIMIT $2
+		    FOR UPDATE SKIP LOCKED
+		)
+		RETURNING media_url`
+
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired stories: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var mediaURL string
+		if err := rows.Scan(&mediaURL); err != nil {
+			return nil, fmt.Errorf("failed to scan expired story: %w", err)
+		}
+		mediaURLs = append(mediaURLs, mediaURL)
+	}
+
+	return mediaURLs, nil
+}
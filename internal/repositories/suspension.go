@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// SuspensionRepository provides methods for accessing account suspensions and restrictions
+type SuspensionRepository struct {
+	*BaseRepository
+}
+
+// NewSuspensionRepository creates a new SuspensionRepository
+func NewSuspensionRepository(db *sql.DB) *SuspensionRepository {
+	return &SuspensionRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// Create records a suspension or restriction and applies it to the user's account.
+// A user who does not exist gets sql.ErrNoRows.
+func (r *SuspensionRepository) Create(suspension *models.Suspension) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	if err := lockUser(tx, suspension.UserID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO suspensions (user_id, kind, reason, note, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	err = tx.QueryRow(query, suspension.UserID, suspension.Kind, suspension.Reason, suspension.Note,
+		suspension.CreatedBy, suspension.CreatedAt, suspension.ExpiresAt).Scan(&suspension.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create suspension: %w", err)
+	}
+
+	if err := syncUserSuspensions(tx, suspension.UserID, suspension.CreatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// LiftActive lifts a user's active suspensions or restrictions of the given kind and returns how many were lifted
+func (r *SuspensionRepository) LiftActive(userID int, kind string, liftedBy int, now time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	if err := lockUser(tx, userID); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE suspensions
+		SET lifted_at = $1, lifted_by = $2
+		WHERE user_id = $3 AND kind = $4 AND lifted_at IS NULL AND expires_at > $1`,
+		now, liftedBy, userID, kind)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lift suspensions: %w", err)
+	}
+	lifted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := syncUserSuspensions(tx, userID, now); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(lifted), nil
+}
+
+// GetByUserID retrieves a user's suspensions and restrictions, newest first
+func (r *SuspensionRepository) GetByUserID(userID int) ([]*models.Suspension, error) {
+	suspensions := []*models.Suspension{}
+	query := `
+		SELECT id, user_id, kind, reason, note, created_by, created_at, expires_at, lifted_at, lifted_by
+		FROM suspensions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suspensions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		suspension := &models.Suspension{}
+		err := rows.Scan(&suspension.ID, &suspension.UserID, &suspension.Kind, &suspension.Reason, &suspension.Note,
+			&suspension.CreatedBy, &suspension.CreatedAt, &suspension.ExpiresAt, &suspension.LiftedAt, &suspension.LiftedBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan suspension: %w", err)
+		}
+		suspensions = append(suspensions, suspension)
+	}
+
+	return suspensions, nil
+}
+
+// LiftExpired clears the suspensions and restrictions that ended by now from users' accounts
+// and returns the number of accounts updated
+func (r *SuspensionRepository) LiftExpired(now time.Time) (int, error) {
+	var lifted int64
+	for _, query := range []string{
+		`UPDATE users SET suspended_until = NULL WHERE suspended_until <= $1`,
+		`UPDATE users SET restricted_until = NULL WHERE restricted_until <= $1`,
+	} {
+		result, err := r.db.Exec(query, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to lift expired suspensions: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		lifted += rowsAffected
+	}
+	return int(lifted), nil
+}
+
+// lockUser locks a user's row until the end of the transaction so concurrent changes to their suspensions are serialized
+func lockUser(tx *sql.Tx, userID int) error {
+	var id int
+	if err := tx.QueryRow(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user not found: %w", err)
+		}
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	return nil
+}
+
+// syncUserSuspensions sets a user's suspended_until and restricted_until to the end of their latest
+// active suspension and restriction, or clears them
+func syncUserSuspensions(tx *sql.Tx, userID int, now time.Time) error {
+	query := `
+		UPDATE users SET
+		    suspended_until = (
+		        SELECT MAX(expires_at) FROM suspensions
+		        WHERE user_id = $1 AND kind = $2 AND lifted_at IS NULL AND expires_at > $4
+		    ),
+		    restricted_until = (
+		        SELECT MAX(expires_at) FROM suspensions
+		        WHERE user_id = $1 AND kind = $3 AND lifted_at IS NULL AND expires_at > $4
+		    ),
+		    updated_at = $4
+		WHERE id = $1`
+
+	_, err := tx.Exec(query, userID, models.SuspensionKindSuspension, models.SuspensionKindRestriction, now)
+	if err != nil {
+		return fmt.Errorf("failed to update user suspension: %w", err)
+	}
+	return nil
+}
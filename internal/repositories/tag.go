@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// TagRepository provides methods for accessing hashtag data
+type TagRepository struct {
+	*BaseRepository
+}
+
+// NewTagRepository creates a new TagRepository
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// SetPostTags replaces the hashtags attached to a post
+func (r *TagRepository) SetPostTags(postID int, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	_, err = tx.Exec(`DELETE FROM post_tags WHERE post_id = $1`, postID)
+	if err != nil {
+		return fmt.Errorf("failed to clear post tags: %w", err)
+	}
+
+	// The no-op update makes RETURNING yield the id of tags that already exist
+	upsertTag := `
+		INSERT INTO tags (name, created_at)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`
+
+	now := time.Now()
+	for _, tag := range tags {
+		var tagID int
+		if err := tx.QueryRow(upsertTag, tag, now).Scan(&tagID); err != nil {
+			return fmt.Errorf("failed to save tag: %w", err)
+		}
+
+		_, err = tx.Exec(`INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, postID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to attach tag: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetTagsForPost retrieves the hashtags attached to a post
+func (r *TagRepository) GetTagsForPost(postID int) ([]string, error) {
+	tags := []string{}
+	query := `
+		SELECT t.name
+		FROM post_tags pt
+		JOIN tags t ON pt.tag_id = t.id
+		WHERE pt.post_id = $1
+		ORDER BY t.name`
+
+	rows, err := r.db.Query(query, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post tags: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// GetPostsByTag retrieves the posts with a hashtag that the viewer is allowed to see
+func (r *TagRepository) GetPostsByTag(tag string, viewerID int, limit, offset int) ([]*models.PostWithUser, error) {
+	posts := []*models.PostWithUser{}
+	query := `
+		SELECT p.id, p.user_id, p.content, p.privacy, p.comment_permission, p.shared_post_id, p.edited_at, p.created_at, p.updated_at,
+		       u.name, u.profile_picture_url
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON pt.post_id = p.id
+		JOIN users u ON p.user_id = u.id
+		WHERE t.name = $1
+		AND ` + visiblePostCondition("p", "$2") + `
+		ORDER BY p.created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(query, tag, viewerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by tag: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		post := &models.PostWithUser{}
+		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.Privacy, &post.CommentPermission, &post.SharedPostID, &post.EditedAt,
+			&post.CreatedAt, &post.UpdatedAt, &post.UserName, &post.ProfilePictureURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		post.Edited = post.EditedAt != nil
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// GetTrendingTags retrieves the hashtags used by the most authors in public posts created since the given time.
+// Posts by restricted users are not counted.
+func (r *TagRepository) GetTrendingTags(since time.Time, limit int) ([]*models.TrendingTag, error) {
+	tags := []*models.TrendingTag{}
+	query := `
+		SELECT t.name, COUNT(*) AS post_count, COUNT(DISTINCT p.user_id) AS author_count
+		FROM posts p
+		JOIN post_tags pt ON pt.post_id = p.id
+		JOIN tags t ON pt.tag_id = t.id
+		WHERE p.created_at >= $1
+		AND p.privacy IN ('public', '')
+		AND ` + unrestrictedUserCondition("p.user_id") + `
+		GROUP BY t.name
+		ORDER BY author_count DESC, post_count DESC, t.name
+		LIMIT $2`
+
+	rows, err := r.db.Query(query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending tags: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		tag := &models.TrendingTag{}
+		if err := rows.Scan(&tag.Name, &tag.PostCount, &tag.AuthorCount); err != nil {
+			return nil, fmt.Errorf("failed to scan trending tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// timelineBackfillLimit is how many of a new friend's newest posts are copied to a user's timeline
+const timelineBackfillLimit = 200
+
+// TimelineRepository provides methods for maintaining users' feed timelines
+type TimelineRepository struct {
+	*BaseRepository
+}
+
+// NewTimelineRepository creates a new TimelineRepository
+func NewTimelineRepository(db *sql.DB) *TimelineRepository {
+	return &TimelineRepository{BaseRepository: NewBaseRepository(db)}
+}
+
+// FanOutPending copies up to limit pending posts to the timelines of their authors and the authors'
+// friends. Posts whose author has more than maxFanout friends are skipped and read from posts instead.
+// It returns how many posts were handled.
+func (r *TimelineRepository) FanOutPending(limit, maxFanout int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	// Other replicas skip the posts claimed here
+	query := `
+		SELECT p.id, p.user_id, p.created_at, (SELECT COUNT(*) FROM friends WHERE user_id = p.user_id)
+		FROM posts p
+		WHERE p.fanout_status = $1
+		ORDER BY p.id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(query, models.FanoutStatusPending, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending posts: %w", err)
+	}
+
+	type pendingPost struct {
+		post        models.Post
+		friendCount int
+	}
+	pending := []*pendingPost{}
+	for rows.Next() {
+		p := &pendingPost{}
+		if err := rows.Scan(&p.post.ID, &p.post.UserID, &p.post.CreatedAt, &p.friendCount); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan pending post: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("failed to get pending posts: %w", err)
+	}
+
+	for _, p := range pending {
+		status := models.FanoutStatusSkipped
+		if p.friendCount <= maxFanout {
+			status = models.FanoutStatusDone
+			_, err := tx.Exec(`
+				INSERT INTO timelines (user_id, post_id, author_id, created_at)
+				SELECT friend_id, $1, $2, $3 FROM friends WHERE user_id = $2
+				UNION
+				SELECT $2, $1, $2, $3
+				ON CONFLICT (user_id, post_id) DO NOTHING`,
+				p.post.ID, p.post.UserID, p.post.CreatedAt)
+			if err != nil {
+				return 0, fmt.Errorf("failed to fan out post %d: %w", p.post.ID, err)
+			}
+		}
+
+		if _, err := tx.Exec(`UPDATE posts SET fanout_status = $1 WHERE id = $2`, status, p.post.ID); err != nil {
+			return 0, fmt.Errorf("failed to update fan-out status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(pending), nil
+}
+
+// backfillTimelines copies the newest posts of two new friends to each other's timelines.
+// Pending posts are included so that a fan-out that started before the friendship does not miss them;
+// skipped posts are left out since they are always read from posts.
+func backfillTimelines(tx *sql.Tx, userID, friendID int) error {
+	query := `
+		INSERT INTO timelines (user_id, post_id, author_id, created_at)
+		SELECT $1, id, user_id, created_at
+		FROM (
+		    SELECT id, user_id, created_at
+		    FROM posts
+		    WHERE user_id = $2 AND fanout_status <> $3
+		    ORDER BY created_at DESC
+		    LIMIT $4
+		) recent
+		ON CONFLICT (user_id, post_id) DO NOTHING`
+
+	if _, err := tx.Exec(query, userID, friendID, models.FanoutStatusSkipped, timelineBackfillLimit); err != nil {
+		return fmt.Errorf("failed to backfill timeline: %w", err)
+	}
+	if _, err := tx.Exec(query, friendID, userID, models.FanoutStatusSkipped, timelineBackfillLimit); err != nil {
+		return fmt.Errorf("failed to backfill timeline: %w", err)
+	}
+
+	return nil
+}
+
+// removeFromTimelines removes two former friends' posts from each other's timelines
+func removeFromTimelines(tx *sql.Tx, userID, otherUserID int) error {
+	query := `
+		DELETE FROM timelines
+		WHERE (user_id = $1 AND author_id = $2) OR (user_id = $2 AND author_id = $1)`
+
+	if _, err := tx.Exec(query, userID, otherUserID); err != nil {
+		return fmt.Errorf("failed to remove posts from timelines: %w", err)
+	}
+
+	return nil
+}
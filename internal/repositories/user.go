@@ -3,11 +3,20 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/gocli/social_api/internal/models"
 )
 
+// Conflicts on the unique email and username of a user. Both wrap ErrConflict.
+var (
+	ErrEmailConflict    = fmt.Errorf("email is already registered: %w", ErrConflict)
+	ErrUsernameConflict = fmt.Errorf("username is already taken: %w", ErrConflict)
+)
+
 // UserRepository provides methods for accessing user data
 type UserRepository struct {
 	*BaseRepository
@@ -18,18 +27,34 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{BaseRepository: NewBaseRepository(db)}
 }
 
-// Create inserts a new user into the database
+// userConflict tells apart the unique violations on a user's email and username
+func userConflict(err error) error {
+	switch violatedConstraint(err) {
+	case "users_email_key":
+		return ErrEmailConflict
+	case "idx_users_username":
+		return ErrUsernameConflict
+	default:
+		return ErrConflict
+	}
+}
+
+// Create inserts a new user into the database. A taken email or username returns ErrEmailConflict
+// or ErrUsernameConflict.
 func (r *UserRepository) Create(user *models.User) error {
 	query := `
-		INSERT INTO users (name, email, password, birth_date, profile_picture_url, cover_photo_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at`
+		INSERT INTO users (name, email, username, password, birth_date, profile_picture_url, cover_photo_url, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+		RETURNING id, role, created_at, updated_at`
 
 	now := time.Now()
-	err := r.db.QueryRow(query, user.Name, user.Email, user.Password, user.BirthDate,
-		user.ProfilePictureURL, user.CoverPhotoURL, now, now).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRow(query, user.Name, user.Email, user.Username, user.Password, user.BirthDate,
+		user.ProfilePictureURL, user.CoverPhotoURL, now, now).Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to create user: %w", userConflict(err))
+		}
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -40,13 +65,17 @@ func (r *UserRepository) Create(user *models.User) error {
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	user := &models.User{}
+	var username sql.NullString
 	query := `
-		SELECT id, name, email, password, birth_date, profile_picture_url, cover_photo_url, created_at, updated_at
+		SELECT id, name, email, username, password, birth_date, profile_picture_url, cover_photo_url,
+		       bio, location, website, pronouns, role, suspended_until, restricted_until, created_at, updated_at
 		FROM users
 		WHERE email = $1`
 
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Email, &user.Password,
-		&user.BirthDate, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Email, &username, &user.Password,
+		&user.BirthDate, &user.ProfilePictureURL, &user.CoverPhotoURL,
+		&user.Bio, &user.Location, &user.Website, &user.Pronouns, &user.Role, &user.SuspendedUntil,
+		&user.RestrictedUntil, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -55,19 +84,24 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
+	user.Username = username.String
 	return user, nil
 }
 
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(id int) (*models.User, error) {
 	user := &models.User{}
+	var username sql.NullString
 	query := `
-		SELECT id, name, email, birth_date, profile_picture_url, cover_photo_url, created_at, updated_at
+		SELECT id, name, email, username, birth_date, profile_picture_url, cover_photo_url,
+		       bio, location, website, pronouns, role, suspended_until, restricted_until, created_at, updated_at
 		FROM users
 		WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email,
-		&user.BirthDate, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &username,
+		&user.BirthDate, &user.ProfilePictureURL, &user.CoverPhotoURL,
+		&user.Bio, &user.Location, &user.Website, &user.Pronouns, &user.Role, &user.SuspendedUntil,
+		&user.RestrictedUntil, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -76,19 +110,124 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
 
+	user.Username = username.String
+	return user, nil
+}
+
+// SetRole changes a user's role
+func (r *UserRepository) SetRole(userID int, role string) error {
+	query := `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`
+	result, err := r.db.Exec(query, role, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to set user role: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// IsRestricted reports whether a user's content is visible only to themselves at the given time
+func (r *UserRepository) IsRestricted(userID int, now time.Time) (bool, error) {
+	var restricted bool
+	query := `SELECT COALESCE(restricted_until > $1, FALSE) FROM users WHERE id = $2`
+	if err := r.db.QueryRow(query, now, userID).Scan(&restricted); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check user restriction: %w", err)
+	}
+	return restricted, nil
+}
+
+// unrestrictedUserCondition returns a SQL condition that matches when the user whose ID is in column
+// is not restricted. Restrictions stay in force here until SuspensionRepository.LiftExpired clears them.
+func unrestrictedUserCondition(column string) string {
+	return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM users ru WHERE ru.id = %s AND ru.restricted_until IS NOT NULL)`, column)
+}
+
+// GetByUsername retrieves a user by username, ignoring case
+func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+	user := &models.User{}
+	query := `
+		SELECT id, name, email, username, birth_date, profile_picture_url, cover_photo_url,
+		       bio, location, website, pronouns, role, suspended_until, restricted_until, created_at, updated_at
+		FROM users
+		WHERE LOWER(username) = LOWER($1)`
+
+	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Name, &user.Email, &user.Username,
+		&user.BirthDate, &user.ProfilePictureURL, &user.CoverPhotoURL,
+		&user.Bio, &user.Location, &user.Website, &user.Pronouns, &user.Role, &user.SuspendedUntil,
+		&user.RestrictedUntil, &user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get user by username: %w", err)
+	}
+
 	return user, nil
 }
 
+// GetIDsByUsernames resolves usernames to user IDs, ignoring case. The returned
+// map is keyed by lowercased username and omits usernames that do not exist.
+func (r *UserRepository) GetIDsByUsernames(usernames []string) (map[string]int, error) {
+	ids := map[string]int{}
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+
+	query := `
+		SELECT id, LOWER(username)
+		FROM users
+		WHERE LOWER(username) = ANY($1)`
+
+	rows, err := r.db.Query(query, pq.Array(lowered))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by username: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var id int
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		ids[username] = id
+	}
+
+	return ids, nil
+}
+
 // Update updates a user's information
 func (r *UserRepository) Update(user *models.User) error {
 	query := `
 		UPDATE users
-		SET name = $1, email = $2, birth_date = $3, profile_picture_url = $4, cover_photo_url = $5, updated_at = $6
-		WHERE id = $7`
+		SET name = $1, email = $2, birth_date = $3, profile_picture_url = $4, cover_photo_url = $5,
+		    bio = $6, location = $7, website = $8, pronouns = $9, updated_at = $10
+		WHERE id = $11`
 
 	now := time.Now()
 	_, err := r.db.Exec(query, user.Name, user.Email, user.BirthDate,
-		user.ProfilePictureURL, user.CoverPhotoURL, now, user.ID)
+		user.ProfilePictureURL, user.CoverPhotoURL,
+		user.Bio, user.Location, user.Website, user.Pronouns, now, user.ID)
 
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -98,17 +237,122 @@ func (r *UserRepository) Update(user *models.User) error {
 	return nil
 }
 
-// Search searches for users by name or email
-func (r *UserRepository) Search(query string, limit, offset int) ([]*models.UserPublic, error) {
+// IsUsernameAvailable reports whether userID may claim username. A username is
+// unavailable while another user holds it, and while it is reserved for the user
+// who released it after reservedSince.
+func (r *UserRepository) IsUsernameAvailable(username string, userID int, reservedSince time.Time) (bool, error) {
+	var taken bool
+	query := `
+		SELECT EXISTS (
+		    SELECT 1 FROM users WHERE LOWER(username) = LOWER($1) AND id <> $2
+		) OR EXISTS (
+		    SELECT 1 FROM username_history
+		    WHERE LOWER(old_username) = LOWER($1) AND user_id <> $2 AND created_at > $3
+		)`
+
+	if err := r.db.QueryRow(query, username, userID, reservedSince).Scan(&taken); err != nil {
+		return false, fmt.Errorf("failed to check username availability: %w", err)
+	}
+
+	return !taken, nil
+}
+
+// ChangeUsername sets a user's username and records the change in the username history
+func (r *UserRepository) ChangeUsername(userID int, username string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = rollbackErr
+		}
+	}()
+
+	var oldUsername sql.NullString
+	err = tx.QueryRow(`SELECT username FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&oldUsername)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user not found: %w", err)
+		}
+		return fmt.Errorf("failed to get current username: %w", err)
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE users SET username = $1, updated_at = $2 WHERE id = $3`, username, now, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to change username: %w", ErrUsernameConflict)
+		}
+		return fmt.Errorf("failed to change username: %w", err)
+	}
+
+	query := `
+		INSERT INTO username_history (user_id, old_username, new_username, created_at)
+		VALUES ($1, $2, $3, $4)`
+
+	_, err = tx.Exec(query, userID, oldUsername, username, now)
+	if err != nil {
+		return fmt.Errorf("failed to record username change: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsernameHistory retrieves a user's username changes, most recent first
+func (r *UserRepository) GetUsernameHistory(userID int) ([]*models.UsernameChange, error) {
+	changes := []*models.UsernameChange{}
+	query := `
+		SELECT id, user_id, COALESCE(old_username, ''), new_username, created_at
+		FROM username_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get username history: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		change := &models.UsernameChange{}
+		err := rows.Scan(&change.ID, &change.UserID, &change.OldUsername, &change.NewUsername, &change.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan username change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// Search searches users by name and username using a prefix tsquery,
+// ranking exact username matches first and then by text relevance
+func (r *UserRepository) Search(tsQuery, rawQuery string, limit, offset int) ([]*models.UserPublic, error) {
 	users := []*models.UserPublic{}
 	sqlQuery := `
-		SELECT id, name, profile_picture_url, cover_photo_url, created_at
-		FROM users
-		WHERE name ILIKE $1 OR email ILIKE $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+		SELECT id, name, COALESCE(username, ''), profile_picture_url, cover_photo_url, created_at
+		FROM users, to_tsquery('simple', $1) query
+		WHERE search_vector @@ query
+		ORDER BY LOWER(COALESCE(username, '')) = LOWER($2) DESC,
+		         ts_rank(search_vector, query) DESC,
+		         id
+		LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.Query(sqlQuery, "%"+query+"%", limit, offset)
+	rows, err := r.db.Query(sqlQuery, tsQuery, rawQuery, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
@@ -122,7 +366,7 @@ func (r *UserRepository) Search(query string, limit, offset int) ([]*models.User
 
 	for rows.Next() {
 		user := &models.UserPublic{}
-		err := rows.Scan(&user.ID, &user.Name, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt)
+		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.ProfilePictureURL, &user.CoverPhotoURL, &user.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -131,3 +375,47 @@ func (r *UserRepository) Search(query string, limit, offset int) ([]*models.User
 
 	return users, nil
 }
+
+// GetPrivacySettings retrieves a user's privacy settings, falling back to the defaults
+func (r *UserRepository) GetPrivacySettings(userID int) (*models.PrivacySettings, error) {
+	settings := &models.PrivacySettings{}
+	query := `
+		SELECT user_id, email_visibility, birth_date_visibility, friend_list_visibility, updated_at
+		FROM user_privacy_settings
+		WHERE user_id = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(&settings.UserID, &settings.EmailVisibility,
+		&settings.BirthDateVisibility, &settings.FriendListVisibility, &settings.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.DefaultPrivacySettings(userID), nil
+		}
+		return nil, fmt.Errorf("failed to get privacy settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// UpsertPrivacySettings creates or replaces a user's privacy settings
+func (r *UserRepository) UpsertPrivacySettings(settings *models.PrivacySettings) error {
+	query := `
+		INSERT INTO user_privacy_settings (user_id, email_visibility, birth_date_visibility, friend_list_visibility, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET email_visibility = EXCLUDED.email_visibility,
+		    birth_date_visibility = EXCLUDED.birth_date_visibility,
+		    friend_list_visibility = EXCLUDED.friend_list_visibility,
+		    updated_at = EXCLUDED.updated_at`
+
+	now := time.Now()
+	_, err := r.db.Exec(query, settings.UserID, settings.EmailVisibility, settings.BirthDateVisibility,
+		settings.FriendListVisibility, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to save privacy settings: %w", err)
+	}
+
+	settings.UpdatedAt = now
+	return nil
+}
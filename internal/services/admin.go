@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrInvalidRole is returned for roles other than user, moderator and admin
+var ErrInvalidRole = errors.New("invalid role")
+
+// ErrCannotChangeOwnRole is returned when an admin tries to change their own role
+var ErrCannotChangeOwnRole = errors.New("you cannot change your own role")
+
+// AdminService provides account management and content removal for admins.
+// Every change is recorded in the moderation audit trail.
+type AdminService struct {
+	BaseService
+	userRepo          *repositories.UserRepository
+	moderationService *ModerationService
+	postService       *PostService
+	commentService    *CommentService
+	visibilityService *VisibilityService
+	suspensionService *SuspensionService
+}
+
+// NewAdminService creates a new AdminService
+func NewAdminService(userRepo *repositories.UserRepository, moderationService *ModerationService,
+	postService *PostService, commentService *CommentService, visibilityService *VisibilityService,
+	suspensionService *SuspensionService) *AdminService {
+	return &AdminService{
+		userRepo:          userRepo,
+		moderationService: moderationService,
+		postService:       postService,
+		commentService:    commentService,
+		visibilityService: visibilityService,
+		suspensionService: suspensionService,
+	}
+}
+
+// GetUser retrieves any user's account by ID
+func (s *AdminService) GetUser(userID int) (*models.AdminUser, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return adminUser(user), nil
+}
+
+// FindUser retrieves any user's account by email or, if no email is given, by username
+func (s *AdminService) FindUser(email, username string) (*models.AdminUser, error) {
+	var user *models.User
+	var err error
+	if email != "" {
+		user, err = s.userRepo.GetByEmail(email)
+	} else {
+		user, err = s.userRepo.GetByUsername(username)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return adminUser(user), nil
+}
+
+// SetRole changes another user's role. The user keeps the role claimed by their current access token
+// until it is refreshed, but sensitive operations always check the new role.
+func (s *AdminService) SetRole(adminID, userID int, role string) (*models.AdminUser, error) {
+	if !models.IsValidRole(role) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+	if adminID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	if err := s.userRepo.SetRole(userID, role); err != nil {
+		return nil, err
+	}
+
+	record := &models.ModerationAction{
+		ModeratorID:  &adminID,
+		Action:       models.ModerationActionChangeRole,
+		ResourceType: models.ResourceTypeUsers,
+		ResourceID:   userID,
+		TargetUserID: &userID,
+		Note:         "role: " + role,
+	}
+	if err := s.moderationService.RecordAction(record, ""); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(userID)
+}
+
+// SuspendUser suspends (kind suspension) or restricts (kind restriction) a user for the given duration
+func (s *AdminService) SuspendUser(adminID, userID int, kind, reason, note string, duration time.Duration) (*models.Suspension, error) {
+	suspension, err := s.suspensionService.Suspend(adminID, userID, kind, reason, note, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	action := models.ModerationActionSuspendUser
+	if kind == models.SuspensionKindRestriction {
+		action = models.ModerationActionRestrictUser
+	}
+	record := &models.ModerationAction{
+		ModeratorID:  &adminID,
+		Action:       action,
+		ResourceType: models.ResourceTypeUsers,
+		ResourceID:   userID,
+		TargetUserID: &userID,
+		Note:         note,
+		ExpiresAt:    &suspension.ExpiresAt,
+	}
+	if err := s.moderationService.RecordAction(record, ""); err != nil {
+		return nil, err
+	}
+
+	return suspension, nil
+}
+
+// LiftSuspension ends a user's suspension (kind suspension) or restriction (kind restriction) early
+func (s *AdminService) LiftSuspension(adminID, userID int, kind, note string) (*models.ModerationAction, error) {
+	if err := s.suspensionService.Lift(adminID, userID, kind); err != nil {
+		return nil, err
+	}
+
+	action := models.ModerationActionLiftSuspension
+	if kind == models.SuspensionKindRestriction {
+		action = models.ModerationActionLiftRestriction
+	}
+	record := &models.ModerationAction{
+		ModeratorID:  &adminID,
+		Action:       action,
+		ResourceType: models.ResourceTypeUsers,
+		ResourceID:   userID,
+		TargetUserID: &userID,
+		Note:         note,
+	}
+	if err := s.moderationService.RecordAction(record, ""); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetSuspensionHistory retrieves every suspension and restriction of a user, newest first
+func (s *AdminService) GetSuspensionHistory(userID int) ([]*models.Suspension, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+	return s.suspensionService.GetHistory(userID)
+}
+
+// RemovePost deletes any post and resolves the open reports on it
+func (s *AdminService) RemovePost(adminID, postID int, note string) (*models.ModerationAction, error) {
+	return s.removeContent(adminID, models.ResourceTypePosts, postID, note, func() error {
+		return s.postService.RemovePost(postID)
+	})
+}
+
+// RemoveComment deletes any comment and resolves the open reports on it
+func (s *AdminService) RemoveComment(adminID, commentID int, note string) (*models.ModerationAction, error) {
+	return s.removeContent(adminID, models.ResourceTypeComments, commentID, note, func() error {
+		return s.commentService.DeleteComment(commentID, adminID)
+	})
+}
+
+// removeContent deletes a post or comment with remove and records the removal against its author
+func (s *AdminService) removeContent(adminID int, resourceType string, resourceID int, note string,
+	remove func() error) (*models.ModerationAction, error) {
+	ownerID, err := s.visibilityService.ResourceOwner(resourceType, resourceID)
+	if err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	if err := remove(); err != nil {
+		return nil, err
+	}
+
+	record := &models.ModerationAction{
+		ModeratorID:  &adminID,
+		Action:       models.ModerationActionRemoveContent,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TargetUserID: &ownerID,
+		Note:         note,
+	}
+	if err := s.moderationService.RecordAction(record, models.ReportStatusActioned); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// adminUser returns the admin view of a user, without the password hash loaded by GetByEmail
+func adminUser(user *models.User) *models.AdminUser {
+	user.Password = ""
+	return &models.AdminUser{User: user, SuspendedUntil: user.SuspendedUntil, RestrictedUntil: user.RestrictedUntil}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/gocli/social_api/internal/models"
 	"github.com/gocli/social_api/internal/repositories"
@@ -10,13 +11,18 @@ import (
 // AlbumService provides album-related functionality
 type AlbumService struct {
 	BaseService
-	albumRepo *repositories.AlbumRepository
+	albumRepo         *repositories.AlbumRepository
+	resourceRegistry  *ResourceRegistry
+	visibilityService *VisibilityService
 }
 
 // NewAlbumService creates a new AlbumService
-func NewAlbumService(albumRepo *repositories.AlbumRepository) *AlbumService {
+func NewAlbumService(albumRepo *repositories.AlbumRepository, resourceRegistry *ResourceRegistry,
+	visibilityService *VisibilityService) *AlbumService {
 	return &AlbumService{
-		albumRepo: albumRepo,
+		albumRepo:         albumRepo,
+		resourceRegistry:  resourceRegistry,
+		visibilityService: visibilityService,
 	}
 }
 
@@ -36,22 +42,51 @@ func (s *AlbumService) CreateAlbum(userID int, name, description, privacy string
 	return album, nil
 }
 
-// GetAlbumByID retrieves an album by ID
-func (s *AlbumService) GetAlbumByID(id int) (*models.Album, error) {
+// GetAlbumByID retrieves an album by ID. Albums viewerID cannot see return ErrNotVisible.
+func (s *AlbumService) GetAlbumByID(id, viewerID int) (*models.Album, error) {
 	album, err := s.albumRepo.GetAlbumByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get album: %w", err)
 	}
+
+	canView, err := s.visibilityService.CanViewContent(album.UserID, viewerID, album.Privacy)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrNotVisible
+	}
+
 	return album, nil
 }
 
-// GetAlbumsWithPhotosByUserID retrieves albums with their photos for a specific user
-func (s *AlbumService) GetAlbumsWithPhotosByUserID(userID int) ([]*models.Album, [][]*models.Photo, error) {
-	albums, err := s.albumRepo.GetAlbumsByUserID(userID)
+// GetAlbumsWithPhotosByUserID retrieves the albums of a specific user that viewerID can see, with their photos.
+// A restricted user's albums are only listed for themselves.
+func (s *AlbumService) GetAlbumsWithPhotosByUserID(userID, viewerID int) ([]*models.Album, [][]*models.Photo, error) {
+	hidden, err := s.visibilityService.IsRestrictedFor(userID, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hidden {
+		return []*models.Album{}, [][]*models.Photo{}, nil
+	}
+
+	userAlbums, err := s.albumRepo.GetAlbumsByUserID(userID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get albums: %w", err)
 	}
 
+	albums := []*models.Album{}
+	for _, album := range userAlbums {
+		canView, err := s.visibilityService.CanView(album.UserID, viewerID, album.Privacy)
+		if err != nil {
+			return nil, nil, err
+		}
+		if canView {
+			albums = append(albums, album)
+		}
+	}
+
 	// Get photos for each album
 	albumsPhotos := make([][]*models.Photo, len(albums))
 	for i, album := range albums {
@@ -103,11 +138,28 @@ func (s *AlbumService) DeleteAlbum(albumID, userID int) error {
 		return fmt.Errorf("user is not authorized to delete this album")
 	}
 
+	// Remember the photos so their reactions and comments can be removed too
+	photos, err := s.albumRepo.GetPhotosByAlbumID(albumID)
+	if err != nil {
+		return fmt.Errorf("failed to get photos: %w", err)
+	}
+
 	// Delete the album
 	if err := s.albumRepo.DeleteAlbum(albumID); err != nil {
 		return fmt.Errorf("failed to delete album: %w", err)
 	}
 
+	photoIDs := make([]int, len(photos))
+	for i, photo := range photos {
+		photoIDs[i] = photo.ID
+	}
+	if err := s.resourceRegistry.DeleteInteractions(models.ResourceTypePhotos, photoIDs...); err != nil {
+		log.Printf("WARN: Failed to delete reactions and comments for photos of album %d: %v", albumID, err)
+	}
+	if err := s.resourceRegistry.DeleteInteractions(models.ResourceTypeAlbums, albumID); err != nil {
+		log.Printf("WARN: Failed to delete reactions and comments for album %d: %v", albumID, err)
+	}
+
 	return nil
 }
 
@@ -162,5 +214,9 @@ func (s *AlbumService) DeletePhoto(photoID, userID int) error {
 		return fmt.Errorf("failed to delete photo: %w", err)
 	}
 
+	if err := s.resourceRegistry.DeleteInteractions(models.ResourceTypePhotos, photoID); err != nil {
+		log.Printf("WARN: Failed to delete reactions and comments for photo %d: %v", photoID, err)
+	}
+
 	return nil
 }
@@ -3,6 +3,7 @@ package services
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrAccountSuspended is returned when a suspended user tries to log in, refresh a token or use an access token
+var ErrAccountSuspended = errors.New("this account is suspended")
+
 // AuthService provides authentication-related functionality
 type AuthService struct {
 	BaseService
@@ -32,14 +36,25 @@ func NewAuthService(authRepo *repositories.AuthRepository, userRepo *repositorie
 	}
 }
 
-// Register creates a new user account
-func (s *AuthService) Register(name, email, password string, birthDate time.Time) (*models.User, error) {
+// Register creates a new user account. The username is optional.
+func (s *AuthService) Register(name, email, username, password string, birthDate time.Time) (*models.User, error) {
 	// Check if user already exists
 	_, err := s.userRepo.GetByEmail(email)
 	if err == nil {
 		return nil, fmt.Errorf("user with email %s already exists", email)
 	}
 
+	// Check that the username is not held or reserved by someone else
+	if username != "" {
+		available, err := s.userRepo.IsUsernameAvailable(username, 0, time.Now().Add(-UsernameReservationPeriod))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check username: %w", err)
+		}
+		if !available {
+			return nil, ErrUsernameTaken
+		}
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -50,11 +65,19 @@ func (s *AuthService) Register(name, email, password string, birthDate time.Time
 	user := &models.User{
 		Name:      name,
 		Email:     email,
+		Username:  username,
 		Password:  string(hashedPassword),
 		BirthDate: birthDate,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
+		// Another registration took the email or username after the checks above
+		if errors.Is(err, repositories.ErrUsernameConflict) {
+			return nil, ErrUsernameTaken
+		}
+		if errors.Is(err, repositories.ErrEmailConflict) {
+			return nil, fmt.Errorf("user with email %s already exists", email)
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -76,8 +99,13 @@ func (s *AuthService) Login(email, password string) (string, string, error) {
 		return "", "", fmt.Errorf("invalid credentials")
 	}
 
+	// Suspended users cannot log in until their suspension ends
+	if user.IsSuspendedAt(time.Now()) {
+		return "", "", ErrAccountSuspended
+	}
+
 	// Generate access token
-	accessToken, err := s.generateAccessToken(user.ID)
+	accessToken, err := s.generateAccessToken(user)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -104,8 +132,17 @@ func (s *AuthService) RefreshToken(tokenString string) (string, error) {
 		return "", fmt.Errorf("refresh token expired")
 	}
 
+	// Get the user, whose role may have changed since they logged in
+	user, err := s.userRepo.GetByID(refreshToken.UserID)
+	if err != nil {
+		return "", fmt.Errorf("invalid refresh token")
+	}
+	if user.IsSuspendedAt(time.Now()) {
+		return "", ErrAccountSuspended
+	}
+
 	// Generate new access token
-	accessToken, err := s.generateAccessToken(refreshToken.UserID)
+	accessToken, err := s.generateAccessToken(user)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -113,15 +150,29 @@ func (s *AuthService) RefreshToken(tokenString string) (string, error) {
 	return accessToken, nil
 }
 
+// CheckAccount verifies that the account an access token was issued to still exists and is not suspended.
+// It returns ErrAccountSuspended for suspended users.
+func (s *AuthService) CheckAccount(userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsSuspendedAt(time.Now()) {
+		return ErrAccountSuspended
+	}
+	return nil
+}
+
 // Logout revokes a refresh token
 func (s *AuthService) Logout(tokenString string) error {
 	return s.authRepo.RevokeRefreshToken(tokenString)
 }
 
-// generateAccessToken generates a JWT access token
-func (s *AuthService) generateAccessToken(userID int) (string, error) {
+// generateAccessToken generates a JWT access token carrying the user's role
+func (s *AuthService) generateAccessToken(user *models.User) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": userID,
+		"user_id": user.ID,
+		"role":    user.Role,
 		"exp":     time.Now().Add(time.Hour * 24).Unix(), // 24 hours
 		"iat":     time.Now().Unix(),
 	}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// BlockService provides user blocking functionality
+type BlockService struct {
+	BaseService
+	blockRepo *repositories.BlockRepository
+	userRepo  *repositories.UserRepository
+}
+
+// NewBlockService creates a new BlockService
+func NewBlockService(blockRepo *repositories.BlockRepository, userRepo *repositories.UserRepository) *BlockService {
+	return &BlockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// BlockUser blocks a user, ending any friendship between the two users
+func (s *BlockService) BlockUser(blockerID, blockedID int) error {
+	if blockerID == blockedID {
+		return fmt.Errorf("users cannot block themselves")
+	}
+
+	if _, err := s.userRepo.GetByID(blockedID); err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := s.blockRepo.Block(blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	return nil
+}
+
+// UnblockUser removes a block
+func (s *BlockService) UnblockUser(blockerID, blockedID int) error {
+	if err := s.blockRepo.Unblock(blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
+
+// GetBlockedUsers retrieves the users blocked by a user
+func (s *BlockService) GetBlockedUsers(userID int) ([]*models.BlockedUser, error) {
+	blocked, err := s.blockRepo.GetBlockedUsers(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked users: %w", err)
+	}
+	return blocked, nil
+}
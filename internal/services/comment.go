@@ -2,29 +2,60 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/realtime"
 	"github.com/gocli/social_api/internal/repositories"
 )
 
+// ReplyPreviewCount is the number of replies returned inline with each top-level comment
+const ReplyPreviewCount = 3
+
+// ErrNotModerator is returned when a user tries to moderate comments on content they do not own without being a site moderator
+var ErrNotModerator = errors.New("only the owner of the content or a moderator can moderate its comments")
+
 // CommentService provides comment-related functionality
 type CommentService struct {
 	BaseService
-	commentRepo *repositories.CommentRepository
-	userRepo    *repositories.UserRepository
+	commentRepo         *repositories.CommentRepository
+	userRepo            *repositories.UserRepository
+	revisionRepo        *repositories.RevisionRepository
+	resourceRegistry    *ResourceRegistry
+	visibilityService   *VisibilityService
+	mentionService      *MentionService
+	notificationService *NotificationService
+	roleService         *RoleService
+	publisher           realtime.Publisher
+	contentFilter       *ContentFilterService
 }
 
 // NewCommentService creates a new CommentService
-func NewCommentService(commentRepo *repositories.CommentRepository, userRepo *repositories.UserRepository) *CommentService {
+func NewCommentService(commentRepo *repositories.CommentRepository, userRepo *repositories.UserRepository,
+	revisionRepo *repositories.RevisionRepository, resourceRegistry *ResourceRegistry, visibilityService *VisibilityService,
+	mentionService *MentionService, notificationService *NotificationService, roleService *RoleService,
+	publisher realtime.Publisher, contentFilter *ContentFilterService) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
-		userRepo:    userRepo,
+		commentRepo:         commentRepo,
+		userRepo:            userRepo,
+		revisionRepo:        revisionRepo,
+		resourceRegistry:    resourceRegistry,
+		visibilityService:   visibilityService,
+		mentionService:      mentionService,
+		notificationService: notificationService,
+		roleService:         roleService,
+		publisher:           publisher,
+		contentFilter:       contentFilter,
 	}
 }
 
-// CreateComment creates a new comment for a resource
-func (s *CommentService) CreateComment(userID int, resourceType string, resourceID int, content string) (*models.Comment, error) {
+// CreateComment creates a new comment for a resource. A non-zero parentCommentID makes it a reply;
+// threads are one level deep, so replying to a reply adds to the same thread. The content filter may
+// reject the comment with ErrContentRejected, or hold it hidden until a moderator reviews it.
+func (s *CommentService) CreateComment(userID int, resourceType string, resourceID int, parentCommentID int, content string) (*models.Comment, error) {
 	comment := &models.Comment{
 		UserID:       userID,
 		ResourceType: resourceType,
@@ -32,6 +63,53 @@ func (s *CommentService) CreateComment(userID int, resourceType string, resource
 		Content:      content,
 	}
 
+	if err := s.resourceRegistry.Check(resourceType, resourceID, userID, ResourceCommentable); err != nil {
+		return nil, err
+	}
+
+	canComment, err := s.visibilityService.CanComment(resourceType, resourceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check comment permission: %w", err)
+	}
+	if !canComment {
+		return nil, ErrCommentsNotAllowed
+	}
+
+	var parent *models.Comment
+	if parentCommentID != 0 {
+		var err error
+		parent, err = s.commentRepo.GetCommentByID(parentCommentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent comment: %w", err)
+		}
+		if parent.ResourceType != resourceType || parent.ResourceID != resourceID {
+			return nil, fmt.Errorf("parent comment belongs to a different resource")
+		}
+		if parent.IsDeleted {
+			return nil, fmt.Errorf("cannot reply to a deleted comment")
+		}
+		if parent.IsHidden {
+			return nil, fmt.Errorf("cannot reply to a hidden comment")
+		}
+
+		threadID := parent.ID
+		if parent.ParentCommentID != nil {
+			threadID = *parent.ParentCommentID
+		}
+		comment.ParentCommentID = &threadID
+	}
+
+	verdict, err := s.contentFilter.Check(userID, models.ResourceTypeComments, content)
+	if err != nil {
+		return nil, err
+	}
+	if verdict.Outcome == ContentHold {
+		now := time.Now()
+		comment.HiddenAt = &now
+		comment.HeldAt = &now
+		comment.IsHidden = true
+	}
+
 	if err := s.commentRepo.CreateComment(comment); err != nil {
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
@@ -42,25 +120,207 @@ func (s *CommentService) CreateComment(userID int, resourceType string, resource
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	comment.User = &models.UserPublic{
-		ID:                user.ID,
-		Name:              user.Name,
-		ProfilePictureURL: user.ProfilePictureURL,
-		CoverPhotoURL:     user.CoverPhotoURL,
-		CreatedAt:         user.CreatedAt,
+	comment.User = user.Public()
+
+	// Nobody else sees a held comment or a restricted user's comment, so nobody is told about it
+	if comment.HeldAt != nil {
+		s.contentFilter.ReportHeld(models.ResourceTypeComments, comment.ID, userID, verdict)
+		return comment, nil
+	}
+	if user.IsRestrictedAt(time.Now()) {
+		return comment, nil
+	}
+
+	// Store mentions; the commented resource decides who may be notified.
+	mentions, err := s.mentionService.SaveMentions(models.ResourceTypeComments, comment.ID, userID, content,
+		resourceType, resourceID, true)
+	if err != nil {
+		log.Printf("WARN: Failed to save mentions for comment %d: %v", comment.ID, err)
+	}
+	comment.Mentions = mentions
+
+	if err := s.notificationService.NotifyResourceOwner(userID, models.NotificationTypeComment, resourceType, resourceID); err != nil {
+		log.Printf("WARN: Failed to notify owner of %s %d about comment: %v", resourceType, resourceID, err)
+	}
+	if parent != nil {
+		if err := s.notificationService.Notify(parent.UserID, userID, models.NotificationTypeReply, models.ResourceTypeComments, parent.ID); err != nil {
+			log.Printf("WARN: Failed to notify author of comment %d about reply: %v", parent.ID, err)
+		}
 	}
 
+	// Push the comment to clients watching the resource
+	publishEvent(s.publisher, realtime.ResourceTopic(resourceType, resourceID), realtime.EventCommentCreated, comment)
+
 	return comment, nil
 }
 
-// GetCommentsForResource retrieves all comments for a specific resource
-func (s *CommentService) GetCommentsForResource(resourceType string, resourceID int) ([]*models.Comment, error) {
-	comments, err := s.commentRepo.GetCommentsForResource(resourceType, resourceID)
+// GetCommentsForResource retrieves the top-level comments for a specific resource,
+// each with its reply count and first few replies. Hidden comments are only returned
+// to their author and the owner of the resource.
+func (s *CommentService) GetCommentsForResource(resourceType string, resourceID, viewerID int) ([]*models.Comment, error) {
+	if err := s.resourceRegistry.Check(resourceType, resourceID, viewerID, ResourceCommentable); err != nil {
+		return nil, err
+	}
+
+	moderator, err := s.isModerator(resourceType, resourceID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.GetCommentsForResource(resourceType, resourceID, viewerID, moderator)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
 
-	return comments, nil
+	commentIDs := make([]int, len(comments))
+	for i, comment := range comments {
+		commentIDs[i] = comment.ID
+	}
+	counts, err := s.commentRepo.GetReplyCounts(commentIDs, viewerID, moderator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count replies: %w", err)
+	}
+	previews, err := s.commentRepo.GetReplyPreviews(commentIDs, viewerID, moderator, ReplyPreviewCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replies: %w", err)
+	}
+
+	// Tombstones are only kept while they have replies
+	visible := make([]*models.Comment, 0, len(comments))
+	loaded := []*models.Comment{}
+	for _, comment := range comments {
+		comment.ReplyCount = counts[comment.ID]
+		if comment.IsDeleted && comment.ReplyCount == 0 {
+			continue
+		}
+		comment.Replies = previews[comment.ID]
+		visible = append(visible, comment)
+		loaded = append(loaded, comment)
+		loaded = append(loaded, comment.Replies...)
+	}
+
+	if err := s.attachMentions(loaded); err != nil {
+		return nil, err
+	}
+
+	return visible, nil
+}
+
+// GetReplies retrieves a page of the replies to a comment that the viewer may see, oldest first.
+// Comments on resources the viewer cannot see return ErrResourceNotFound.
+func (s *CommentService) GetReplies(commentID, viewerID, limit, offset int) (*models.CommentReplyPage, error) {
+	comment, err := s.commentRepo.GetCommentByID(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if err := s.resourceRegistry.Check(comment.ResourceType, comment.ResourceID, viewerID, ResourceCommentable); err != nil {
+		return nil, err
+	}
+
+	moderator, err := s.isModerator(comment.ResourceType, comment.ResourceID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := s.commentRepo.GetReplies(commentID, viewerID, moderator, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replies: %w", err)
+	}
+	counts, err := s.commentRepo.GetReplyCounts([]int{commentID}, viewerID, moderator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count replies: %w", err)
+	}
+
+	if err := s.attachMentions(replies); err != nil {
+		return nil, err
+	}
+
+	return &models.CommentReplyPage{Replies: replies, TotalCount: counts[commentID]}, nil
+}
+
+// UpdateComment edits the content of a comment. Only the author may edit it.
+func (s *CommentService) UpdateComment(commentID, userID int, content string) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetCommentByID(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	if comment.UserID != userID {
+		return nil, fmt.Errorf("user is not authorized to edit this comment")
+	}
+	if comment.IsDeleted {
+		return nil, fmt.Errorf("cannot edit a deleted comment")
+	}
+
+	verdict := ContentVerdict{Outcome: ContentAllow}
+	if content != comment.Content {
+		verdict, err = s.contentFilter.Check(userID, models.ResourceTypeComments, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	comment.Content = content
+	if err := s.commentRepo.UpdateComment(comment, userID); err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	if verdict.Outcome == ContentHold && comment.HeldAt == nil && comment.ModeratedAt == nil {
+		if err := s.commentRepo.Hold(comment.ID); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		comment.HeldAt = &now
+		comment.IsHidden = true
+		comment.IsPinned = false
+		s.contentFilter.ReportHeld(models.ResourceTypeComments, comment.ID, userID, verdict)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	comment.User = user.Public()
+
+	// Only users newly mentioned by the edit are notified, and nobody while the comment is held
+	// or its author is restricted
+	if comment.HeldAt != nil || user.IsRestrictedAt(time.Now()) {
+		return comment, nil
+	}
+	mentions, err := s.mentionService.SaveMentions(models.ResourceTypeComments, comment.ID, userID, content,
+		comment.ResourceType, comment.ResourceID, true)
+	if err != nil {
+		log.Printf("WARN: Failed to save mentions for comment %d: %v", comment.ID, err)
+	}
+	comment.Mentions = mentions
+
+	return comment, nil
+}
+
+// GetCommentRevisions retrieves the previous versions of a comment's content.
+// The author and the moderators of the resource's comments may see them.
+func (s *CommentService) GetCommentRevisions(commentID, userID int) ([]*models.ContentRevision, error) {
+	comment, err := s.commentRepo.GetCommentByID(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	if comment.UserID != userID {
+		moderator, err := s.isModerator(comment.ResourceType, comment.ResourceID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !moderator {
+			return nil, ErrHistoryNotAllowed
+		}
+	}
+
+	revisions, err := s.revisionRepo.GetRevisions(models.ResourceTypeComments, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment revisions: %w", err)
+	}
+
+	return revisions, nil
 }
 
 // DeleteComment deletes a comment
@@ -74,13 +334,176 @@ func (s *CommentService) DeleteComment(commentID, userID int) error {
 	// Check if user is authorized to delete this comment
 	// Either the comment author or the resource owner can delete
 	if comment.UserID != userID {
-		return fmt.Errorf("user is not authorized to delete this comment")
+		moderator, err := s.isModerator(comment.ResourceType, comment.ResourceID, userID)
+		if err != nil {
+			return err
+		}
+		if !moderator {
+			return fmt.Errorf("user is not authorized to delete this comment")
+		}
+	}
+
+	if comment.IsDeleted {
+		return fmt.Errorf("comment has already been deleted")
+	}
+
+	// A comment with replies becomes a tombstone so the thread stays readable
+	counts, err := s.commentRepo.GetReplyCounts([]int{commentID}, userID, true)
+	if err != nil {
+		return fmt.Errorf("failed to count replies: %w", err)
+	}
+	if counts[commentID] > 0 {
+		if err := s.commentRepo.TombstoneComment(commentID); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+	} else {
+		if err := s.commentRepo.DeleteComment(commentID); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+		if comment.ParentCommentID != nil {
+			s.removeEmptyTombstone(*comment.ParentCommentID)
+		}
+	}
+
+	if err := s.mentionService.DeleteMentions(models.ResourceTypeComments, commentID); err != nil {
+		log.Printf("WARN: Failed to delete mentions for comment %d: %v", commentID, err)
+	}
+	if err := s.revisionRepo.DeleteRevisions(models.ResourceTypeComments, commentID); err != nil {
+		log.Printf("WARN: Failed to delete revisions for comment %d: %v", commentID, err)
+	}
+	if err := s.resourceRegistry.DeleteInteractions(models.ResourceTypeComments, commentID); err != nil {
+		log.Printf("WARN: Failed to delete reactions for comment %d: %v", commentID, err)
+	}
+
+	return nil
+}
+
+// HideComment hides or unhides a comment. Only the owner of the commented resource may do this,
+// and a comment hidden by a site moderator or held for review stays hidden.
+func (s *CommentService) HideComment(commentID, userID int, hidden bool) error {
+	comment, err := s.moderatedComment(commentID, userID)
+	if err != nil {
+		return err
+	}
+	if !hidden && comment.ModeratedAt != nil {
+		return ErrContentModerated
+	}
+	if !hidden && comment.HeldAt != nil {
+		return ErrContentHeld
+	}
+
+	if err := s.commentRepo.SetHidden(comment.ID, hidden); err != nil {
+		return fmt.Errorf("failed to hide comment: %w", err)
+	}
+
+	return nil
+}
+
+// PinComment pins a top-level comment above the others, replacing any pinned comment.
+// Only the owner of the commented resource may do this.
+func (s *CommentService) PinComment(commentID, userID int) error {
+	comment, err := s.moderatedComment(commentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if comment.ParentCommentID != nil {
+		return fmt.Errorf("replies cannot be pinned")
+	}
+	if comment.IsDeleted || comment.IsHidden {
+		return fmt.Errorf("deleted or hidden comments cannot be pinned")
+	}
+
+	if err := s.commentRepo.PinComment(comment); err != nil {
+		return fmt.Errorf("failed to pin comment: %w", err)
+	}
+
+	return nil
+}
+
+// UnpinComment unpins a comment. Only the owner of the commented resource may do this.
+func (s *CommentService) UnpinComment(commentID, userID int) error {
+	comment, err := s.moderatedComment(commentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.commentRepo.UnpinComment(comment.ID); err != nil {
+		return fmt.Errorf("failed to unpin comment: %w", err)
+	}
+
+	return nil
+}
+
+// moderatedComment gets a comment for moderation, checking that userID owns the commented resource
+func (s *CommentService) moderatedComment(commentID, userID int) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetCommentByID(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	moderator, err := s.isModerator(comment.ResourceType, comment.ResourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !moderator {
+		return nil, ErrNotModerator
+	}
+
+	return comment, nil
+}
+
+// isModerator reports whether userID moderates the comments on a resource,
+// which the resource's owner and the site's moderators do
+func (s *CommentService) isModerator(resourceType string, resourceID, userID int) (bool, error) {
+	ownerID, err := s.visibilityService.ResourceOwner(resourceType, resourceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get resource owner: %w", err)
+	}
+	if ownerID == userID {
+		return true, nil
+	}
+
+	siteModerator, err := s.roleService.HasPermission(userID, models.PermissionModerateContent)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user role: %w", err)
+	}
+	return siteModerator, nil
+}
+
+// removeEmptyTombstone deletes a tombstoned comment once its last reply is gone
+func (s *CommentService) removeEmptyTombstone(commentID int) {
+	comment, err := s.commentRepo.GetCommentByID(commentID)
+	if err != nil || !comment.IsDeleted {
+		return
+	}
+
+	counts, err := s.commentRepo.GetReplyCounts([]int{commentID}, 0, true)
+	if err != nil {
+		log.Printf("WARN: Failed to count replies for comment %d: %v", commentID, err)
+		return
+	}
+	if counts[commentID] > 0 {
+		return
 	}
 
-	// Delete the comment
 	if err := s.commentRepo.DeleteComment(commentID); err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
+		log.Printf("WARN: Failed to remove tombstone for comment %d: %v", commentID, err)
 	}
+}
 
+// attachMentions fills in the mentions of each comment
+func (s *CommentService) attachMentions(comments []*models.Comment) error {
+	commentIDs := make([]int, len(comments))
+	for i, comment := range comments {
+		commentIDs[i] = comment.ID
+	}
+	mentions, err := s.mentionService.GetMentions(models.ResourceTypeComments, commentIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get comment mentions: %w", err)
+	}
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+	}
 	return nil
 }
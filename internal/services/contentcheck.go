@@ -0,0 +1,458 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// Content check outcomes, from least to most severe
+const (
+	ContentAllow  = "allow"
+	ContentHold   = "hold"   // Saved, but only its author sees it until a moderator reviews it
+	ContentReject = "reject" // Not saved
+)
+
+// classifierTimeout bounds a request to an external content classifier
+const classifierTimeout = 3 * time.Second
+
+// ContentVerdict is a content check's decision on a post or comment
+type ContentVerdict struct {
+	Outcome string // allow, hold or reject
+	Reason  string // Report reason filed with held content, such as spam
+	Detail  string // What the check found, for moderators
+	Checker string // Name of the check that decided
+}
+
+// ContentChecker checks posts and comments before they are saved. Checks can be combined
+// in a ContentPipeline.
+type ContentChecker interface {
+	// Name identifies the check
+	Name() string
+	// Check decides whether the submission is allowed, held for review or rejected
+	Check(submission *models.ContentSubmission) (ContentVerdict, error)
+}
+
+// contentSeverity orders the outcomes; unknown outcomes count as allow
+func contentSeverity(outcome string) int {
+	switch outcome {
+	case ContentHold:
+		return 1
+	case ContentReject:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ContentPipeline runs several checks and keeps the most severe verdict
+type ContentPipeline struct {
+	checkers []ContentChecker
+}
+
+// NewContentPipeline creates a new ContentPipeline running the given checks in order
+func NewContentPipeline(checkers ...ContentChecker) *ContentPipeline {
+	return &ContentPipeline{checkers: checkers}
+}
+
+// Name identifies the check
+func (p *ContentPipeline) Name() string {
+	return "pipeline"
+}
+
+// Check runs every check and returns the most severe verdict, stopping at the first rejection.
+// A check that fails is logged and skipped, so an unavailable classifier does not stop users posting.
+func (p *ContentPipeline) Check(submission *models.ContentSubmission) (ContentVerdict, error) {
+	verdict := ContentVerdict{Outcome: ContentAllow}
+	for _, checker := range p.checkers {
+		result, err := checker.Check(submission)
+		if err != nil {
+			log.Printf("WARN: Content check %s failed: %v", checker.Name(), err)
+			continue
+		}
+		if contentSeverity(result.Outcome) <= contentSeverity(verdict.Outcome) {
+			continue
+		}
+		if result.Checker == "" {
+			result.Checker = checker.Name()
+		}
+		verdict = result
+		if verdict.Outcome == ContentReject {
+			break
+		}
+	}
+	return verdict, nil
+}
+
+// TermRule holds or rejects content containing any of its words or matching any of its patterns
+type TermRule struct {
+	Words    []string `json:"words"`    // Matched as whole words, ignoring case
+	Patterns []string `json:"patterns"` // Regular expressions
+	Outcome  string   `json:"outcome"`  // hold or reject
+	Reason   string   `json:"reason"`   // Report reason of held content; other by default
+}
+
+// TermFilter applies a TermRule
+type TermFilter struct {
+	rule        TermRule
+	expressions []*regexp.Regexp
+}
+
+// NewTermFilter creates a new TermFilter, compiling the rule's words and patterns
+func NewTermFilter(rule TermRule) (*TermFilter, error) {
+	if rule.Outcome != ContentHold && rule.Outcome != ContentReject {
+		return nil, fmt.Errorf("term rule outcome must be hold or reject, got %q", rule.Outcome)
+	}
+	if rule.Reason == "" {
+		rule.Reason = models.ReportReasonOther
+	}
+	if !models.IsValidReportReason(rule.Reason) {
+		return nil, fmt.Errorf("invalid term rule reason %q", rule.Reason)
+	}
+
+	filter := &TermFilter{rule: rule}
+	for _, word := range rule.Words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		// Go's \b only knows ASCII, so word boundaries are spelled out to cover accented letters
+		expression := `(?i)(?:^|[^\pL\pN_])(` + regexp.QuoteMeta(word) + `)(?:$|[^\pL\pN_])`
+		filter.expressions = append(filter.expressions, regexp.MustCompile(expression))
+	}
+	for _, pattern := range rule.Patterns {
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid term rule pattern %q: %w", pattern, err)
+		}
+		filter.expressions = append(filter.expressions, expression)
+	}
+	return filter, nil
+}
+
+// Name identifies the check
+func (f *TermFilter) Name() string {
+	return "terms"
+}
+
+// Check holds or rejects the submission if its content contains one of the terms
+func (f *TermFilter) Check(submission *models.ContentSubmission) (ContentVerdict, error) {
+	for _, expression := range f.expressions {
+		match := expression.FindStringSubmatch(submission.Content)
+		if match == nil {
+			continue
+		}
+		term := match[0]
+		if len(match) > 1 {
+			term = match[1]
+		}
+		return ContentVerdict{Outcome: f.rule.Outcome, Reason: f.rule.Reason, Detail: fmt.Sprintf("contains %q", term)}, nil
+	}
+	return ContentVerdict{Outcome: ContentAllow}, nil
+}
+
+// LinkBlocklist rejects content linking to blocked domains or their subdomains
+type LinkBlocklist struct {
+	domains map[string]bool
+}
+
+// NewLinkBlocklist creates a new LinkBlocklist
+func NewLinkBlocklist(domains []string) *LinkBlocklist {
+	blocklist := &LinkBlocklist{domains: make(map[string]bool)}
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			blocklist.domains[domain] = true
+		}
+	}
+	return blocklist
+}
+
+// Name identifies the check
+func (b *LinkBlocklist) Name() string {
+	return "link_blocklist"
+}
+
+// Check rejects the submission if any of its links points to a blocked domain
+func (b *LinkBlocklist) Check(submission *models.ContentSubmission) (ContentVerdict, error) {
+	for _, link := range submission.LinkURLs {
+		host := linkHost(link)
+		for host != "" {
+			if b.domains[host] {
+				return ContentVerdict{
+					Outcome: ContentReject,
+					Reason:  models.ReportReasonSpam,
+					Detail:  fmt.Sprintf("links to blocked domain %s", host),
+				}, nil
+			}
+			_, parent, found := strings.Cut(host, ".")
+			if !found {
+				break
+			}
+			host = parent
+		}
+	}
+	return ContentVerdict{Outcome: ContentAllow}, nil
+}
+
+// linkHost returns the lowercased host of a link, which may lack its scheme
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(strings.ToLower(parsed.Hostname()), ".")
+}
+
+// SpamRules configures a SpamFilter
+type SpamRules struct {
+	DuplicateLimit         int `json:"duplicate_limit"`          // Identical posts or comments allowed within the window; 0 turns the check off
+	DuplicateWindowMinutes int `json:"duplicate_window_minutes"` // How far back identical content is counted
+	NewAccountDays         int `json:"new_account_days"`         // Accounts younger than this are new
+	NewAccountMaxLinks     int `json:"new_account_max_links"`    // New accounts' content with more links is held; 0 turns the check off
+}
+
+// DuplicateCounter counts a user's recent posts or comments with the same content
+type DuplicateCounter interface {
+	// CountRecentDuplicates counts the user's items with exactly this content created since the given time
+	CountRecentDuplicates(userID int, content string, since time.Time) (int, error)
+}
+
+// SpamFilter rejects floods of identical content and holds link-heavy content from new accounts
+type SpamFilter struct {
+	rules    SpamRules
+	counters map[string]DuplicateCounter
+}
+
+// NewSpamFilter creates a new SpamFilter. counters holds the DuplicateCounter of each resource type;
+// types without one are not checked for duplicates.
+func NewSpamFilter(rules SpamRules, counters map[string]DuplicateCounter) *SpamFilter {
+	return &SpamFilter{rules: rules, counters: counters}
+}
+
+// Name identifies the check
+func (f *SpamFilter) Name() string {
+	return "spam"
+}
+
+// Check rejects the submission if its author already posted the same content too often within the window,
+// and holds it if it comes from a new account and has too many links
+func (f *SpamFilter) Check(submission *models.ContentSubmission) (ContentVerdict, error) {
+	counter := f.counters[submission.ResourceType]
+	if f.rules.DuplicateLimit > 0 && counter != nil && strings.TrimSpace(submission.Content) != "" {
+		window := time.Duration(f.rules.DuplicateWindowMinutes) * time.Minute
+		count, err := counter.CountRecentDuplicates(submission.UserID, submission.Content, submission.SubmittedAt.Add(-window))
+		if err != nil {
+			return ContentVerdict{}, fmt.Errorf("failed to count duplicates: %w", err)
+		}
+		if count >= f.rules.DuplicateLimit {
+			return ContentVerdict{
+				Outcome: ContentReject,
+				Reason:  models.ReportReasonSpam,
+				Detail:  fmt.Sprintf("already posted %d times in the last %d minutes", count, f.rules.DuplicateWindowMinutes),
+			}, nil
+		}
+	}
+
+	accountAge := submission.SubmittedAt.Sub(submission.AuthorJoinedAt)
+	newAccount := accountAge < time.Duration(f.rules.NewAccountDays)*24*time.Hour
+	if f.rules.NewAccountMaxLinks > 0 && newAccount && len(submission.LinkURLs) > f.rules.NewAccountMaxLinks {
+		return ContentVerdict{
+			Outcome: ContentHold,
+			Reason:  models.ReportReasonSpam,
+			Detail:  fmt.Sprintf("%d links from an account created %s ago", len(submission.LinkURLs), accountAge.Round(time.Hour)),
+		}, nil
+	}
+
+	return ContentVerdict{Outcome: ContentAllow}, nil
+}
+
+// ContentClassifier scores content with an external model or service
+type ContentClassifier interface {
+	// Classify returns a score between 0 and 1 per label, such as spam or hate_speech
+	Classify(content string) (map[string]float64, error)
+}
+
+// ClassifierThresholds turns a classifier's scores into outcomes
+type ClassifierThresholds struct {
+	Hold   float64 `json:"hold"`   // Content scoring at least this on any label is held; 0 turns holding off
+	Reject float64 `json:"reject"` // Content scoring at least this on any label is rejected; 0 turns rejecting off
+}
+
+// ClassifierCheck holds or rejects content that an external classifier scores highly on any label
+type ClassifierCheck struct {
+	classifier ContentClassifier
+	thresholds ClassifierThresholds
+}
+
+// NewClassifierCheck creates a new ClassifierCheck
+func NewClassifierCheck(classifier ContentClassifier, thresholds ClassifierThresholds) *ClassifierCheck {
+	return &ClassifierCheck{classifier: classifier, thresholds: thresholds}
+}
+
+// Name identifies the check
+func (c *ClassifierCheck) Name() string {
+	return "classifier"
+}
+
+// Check classifies the submission's content and compares its highest score to the thresholds.
+// Labels that are report reasons become the reason of held content; any other label is reported as other.
+func (c *ClassifierCheck) Check(submission *models.ContentSubmission) (ContentVerdict, error) {
+	if strings.TrimSpace(submission.Content) == "" {
+		return ContentVerdict{Outcome: ContentAllow}, nil
+	}
+
+	scores, err := c.classifier.Classify(submission.Content)
+	if err != nil {
+		return ContentVerdict{}, fmt.Errorf("failed to classify content: %w", err)
+	}
+
+	labels := make([]string, 0, len(scores))
+	for label := range scores {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	topLabel, topScore := "", 0.0
+	for _, label := range labels {
+		if scores[label] > topScore {
+			topLabel, topScore = label, scores[label]
+		}
+	}
+
+	var outcome string
+	switch {
+	case c.thresholds.Reject > 0 && topScore >= c.thresholds.Reject:
+		outcome = ContentReject
+	case c.thresholds.Hold > 0 && topScore >= c.thresholds.Hold:
+		outcome = ContentHold
+	default:
+		return ContentVerdict{Outcome: ContentAllow}, nil
+	}
+
+	reason := topLabel
+	if !models.IsValidReportReason(reason) {
+		reason = models.ReportReasonOther
+	}
+	return ContentVerdict{Outcome: outcome, Reason: reason, Detail: fmt.Sprintf("classified as %s (%.2f)", topLabel, topScore)}, nil
+}
+
+// HTTPClassifier is a ContentClassifier backed by a web service. It POSTs {"content": "..."}
+// and expects {"scores": {"spam": 0.93, ...}} back.
+type HTTPClassifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPClassifier creates a new HTTPClassifier for the service at the given URL
+func NewHTTPClassifier(serviceURL string) *HTTPClassifier {
+	return &HTTPClassifier{
+		url:    serviceURL,
+		client: &http.Client{Timeout: classifierTimeout},
+	}
+}
+
+// Classify sends the content to the classification service and returns its scores
+func (c *HTTPClassifier) Classify(content string) (map[string]float64, error) {
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode classifier request: %w", err)
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call classifier: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classifier returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Scores map[string]float64 `json:"scores"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode classifier response: %w", err)
+	}
+	return result.Scores, nil
+}
+
+// ContentFilterRules configures the content checks run on posts and comments
+type ContentFilterRules struct {
+	Terms                []TermRule           `json:"terms"`
+	BlockedDomains       []string             `json:"blocked_domains"` // Links to these domains or their subdomains are rejected
+	Spam                 SpamRules            `json:"spam"`
+	ClassifierURL        string               `json:"classifier_url"` // An HTTPClassifier service; none if empty
+	ClassifierThresholds ClassifierThresholds `json:"classifier_thresholds"`
+}
+
+// DefaultContentFilterRules returns the rules used when no rules file is configured:
+// spam heuristics only, with classifier thresholds ready for a classifier URL
+func DefaultContentFilterRules() ContentFilterRules {
+	return ContentFilterRules{
+		Spam: SpamRules{
+			DuplicateLimit:         3,
+			DuplicateWindowMinutes: 10,
+			NewAccountDays:         7,
+			NewAccountMaxLinks:     2,
+		},
+		ClassifierThresholds: ClassifierThresholds{Hold: 0.7, Reject: 0.95},
+	}
+}
+
+// LoadContentFilterRules reads rules from a JSON file over the defaults. An empty path returns the defaults.
+func LoadContentFilterRules(path string) (ContentFilterRules, error) {
+	rules := DefaultContentFilterRules()
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, fmt.Errorf("failed to read content filter rules: %w", err)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("failed to parse content filter rules: %w", err)
+	}
+	return rules, nil
+}
+
+// NewContentPipelineFromRules builds the pipeline of checks described by rules:
+// term rules, then the link blocklist, the spam heuristics and the classifier
+func NewContentPipelineFromRules(rules ContentFilterRules, counters map[string]DuplicateCounter) (*ContentPipeline, error) {
+	checkers := []ContentChecker{}
+	for _, rule := range rules.Terms {
+		filter, err := NewTermFilter(rule)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, filter)
+	}
+	if len(rules.BlockedDomains) > 0 {
+		checkers = append(checkers, NewLinkBlocklist(rules.BlockedDomains))
+	}
+	checkers = append(checkers, NewSpamFilter(rules.Spam, counters))
+	if rules.ClassifierURL != "" {
+		checkers = append(checkers, NewClassifierCheck(NewHTTPClassifier(rules.ClassifierURL), rules.ClassifierThresholds))
+	}
+	return NewContentPipeline(checkers...), nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// newSubmission creates a post submission by a user who joined a year ago
+func newSubmission(content string, links ...string) *models.ContentSubmission {
+	now := time.Now()
+	return &models.ContentSubmission{
+		UserID:         1,
+		ResourceType:   models.ResourceTypePosts,
+		Content:        content,
+		LinkURLs:       links,
+		AuthorJoinedAt: now.AddDate(-1, 0, 0),
+		SubmittedAt:    now,
+	}
+}
+
+// fixedChecker always returns the same verdict or error
+type fixedChecker struct {
+	name    string
+	verdict ContentVerdict
+	err     error
+}
+
+func (c fixedChecker) Name() string {
+	return c.name
+}
+
+func (c fixedChecker) Check(*models.ContentSubmission) (ContentVerdict, error) {
+	return c.verdict, c.err
+}
+
+// duplicateCounter returns a fixed count of duplicates
+type duplicateCounter int
+
+func (c duplicateCounter) CountRecentDuplicates(int, string, time.Time) (int, error) {
+	return int(c), nil
+}
+
+// fixedClassifier returns fixed scores
+type fixedClassifier map[string]float64
+
+func (c fixedClassifier) Classify(string) (map[string]float64, error) {
+	return c, nil
+}
+
+func TestContentPipeline(t *testing.T) {
+	allow := fixedChecker{name: "allow", verdict: ContentVerdict{Outcome: ContentAllow}}
+	hold := fixedChecker{name: "hold", verdict: ContentVerdict{Outcome: ContentHold, Reason: models.ReportReasonSpam}}
+	reject := fixedChecker{name: "reject", verdict: ContentVerdict{Outcome: ContentReject, Reason: models.ReportReasonOther}}
+	broken := fixedChecker{name: "broken", err: errors.New("classifier down")}
+
+	// No checks allow everything
+	verdict, err := NewContentPipeline().Check(newSubmission("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentAllow, verdict.Outcome)
+
+	// The most severe verdict wins and names its check
+	verdict, err = NewContentPipeline(allow, hold, allow).Check(newSubmission("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentHold, verdict.Outcome)
+	assert.Equal(t, "hold", verdict.Checker)
+
+	verdict, err = NewContentPipeline(hold, reject).Check(newSubmission("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentReject, verdict.Outcome)
+	assert.Equal(t, "reject", verdict.Checker)
+
+	// A failing check is skipped
+	verdict, err = NewContentPipeline(broken, hold).Check(newSubmission("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentHold, verdict.Outcome)
+}
+
+func TestTermFilter(t *testing.T) {
+	filter, err := NewTermFilter(TermRule{Words: []string{"buy now", "pão"}, Patterns: []string{`\d{4}-\d{4}-\d{4}`}, Outcome: ContentReject})
+	require.NoError(t, err)
+
+	verdict, err := filter.Check(newSubmission("Great deals, BUY NOW!"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentReject, verdict.Outcome)
+	assert.Equal(t, models.ReportReasonOther, verdict.Reason)
+	assert.Equal(t, `contains "BUY NOW"`, verdict.Detail)
+
+	// Words only match whole words, including accented ones
+	for content, outcome := range map[string]string{
+		"Comprei pão hoje":        ContentReject,
+		"Comprei pãozinho hoje":   ContentAllow,
+		"card 1234-5678-9012 lol": ContentReject,
+		"nothing to see":          ContentAllow,
+	} {
+		verdict, err := filter.Check(newSubmission(content))
+		require.NoError(t, err)
+		assert.Equal(t, outcome, verdict.Outcome, content)
+	}
+
+	_, err = NewTermFilter(TermRule{Words: []string{"x"}, Outcome: ContentAllow})
+	assert.Error(t, err)
+	_, err = NewTermFilter(TermRule{Patterns: []string{"("}, Outcome: ContentHold})
+	assert.Error(t, err)
+	_, err = NewTermFilter(TermRule{Words: []string{"x"}, Outcome: ContentHold, Reason: "rude"})
+	assert.Error(t, err)
+}
+
+func TestLinkBlocklist(t *testing.T) {
+	blocklist := NewLinkBlocklist([]string{"Spam.example", ".bad.io"})
+
+	for link, outcome := range map[string]string{
+		"https://spam.example/offer":        ContentReject,
+		"http://cheap.SPAM.example:8080/x":  ContentReject,
+		"www.bad.io":                        ContentReject,
+		"https://notspam.example/page":      ContentAllow,
+		"https://example.com/spam.example/": ContentAllow,
+	} {
+		verdict, err := blocklist.Check(newSubmission("look", link))
+		require.NoError(t, err)
+		assert.Equal(t, outcome, verdict.Outcome, link)
+	}
+}
+
+func TestSpamFilter(t *testing.T) {
+	rules := DefaultContentFilterRules().Spam
+
+	// Too many identical posts within the window are rejected
+	filter := NewSpamFilter(rules, map[string]DuplicateCounter{models.ResourceTypePosts: duplicateCounter(3)})
+	verdict, err := filter.Check(newSubmission("same again"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentReject, verdict.Outcome)
+	assert.Equal(t, models.ReportReasonSpam, verdict.Reason)
+
+	// Comments have no counter here, and posts below the limit are allowed
+	comment := newSubmission("same again")
+	comment.ResourceType = models.ResourceTypeComments
+	verdict, err = filter.Check(comment)
+	require.NoError(t, err)
+	assert.Equal(t, ContentAllow, verdict.Outcome)
+
+	filter = NewSpamFilter(rules, map[string]DuplicateCounter{models.ResourceTypePosts: duplicateCounter(2)})
+	verdict, err = filter.Check(newSubmission("same again"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentAllow, verdict.Outcome)
+
+	// Link-heavy content is held from new accounts only
+	links := []string{"https://a.example", "https://b.example", "https://c.example"}
+	verdict, err = filter.Check(newSubmission("links", links...))
+	require.NoError(t, err)
+	assert.Equal(t, ContentAllow, verdict.Outcome)
+
+	newcomer := newSubmission("links", links...)
+	newcomer.AuthorJoinedAt = newcomer.SubmittedAt.Add(-time.Hour)
+	verdict, err = filter.Check(newcomer)
+	require.NoError(t, err)
+	assert.Equal(t, ContentHold, verdict.Outcome)
+
+	newcomer.LinkURLs = links[:2]
+	verdict, err = filter.Check(newcomer)
+	require.NoError(t, err)
+	assert.Equal(t, ContentAllow, verdict.Outcome)
+}
+
+func TestClassifierCheck(t *testing.T) {
+	thresholds := ClassifierThresholds{Hold: 0.7, Reject: 0.95}
+
+	for name, tc := range map[string]struct {
+		scores  fixedClassifier
+		outcome string
+		reason  string
+	}{
+		"low scores":       {fixedClassifier{"spam": 0.2, "hate_speech": 0.1}, ContentAllow, ""},
+		"held":             {fixedClassifier{"spam": 0.2, "hate_speech": 0.8}, ContentHold, models.ReportReasonHateSpeech},
+		"rejected":         {fixedClassifier{"spam": 0.97}, ContentReject, models.ReportReasonSpam},
+		"unknown label":    {fixedClassifier{"toxicity": 0.75}, ContentHold, models.ReportReasonOther},
+		"no scores at all": {fixedClassifier{}, ContentAllow, ""},
+	} {
+		verdict, err := NewClassifierCheck(tc.scores, thresholds).Check(newSubmission("some text"))
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.outcome, verdict.Outcome, name)
+		assert.Equal(t, tc.reason, verdict.Reason, name)
+	}
+}
+
+func TestHTTPClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["content"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"scores": map[string]float64{"spam": 0.9}})
+	}))
+	defer server.Close()
+
+	scores, err := NewHTTPClassifier(server.URL).Classify("win a prize")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"spam": 0.9}, scores)
+
+	_, err = NewHTTPClassifier(server.URL).Classify("")
+	assert.Error(t, err)
+}
+
+func TestLoadContentFilterRules(t *testing.T) {
+	rules, err := LoadContentFilterRules("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultContentFilterRules(), rules)
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	data := `{"terms": [{"words": ["scam"], "outcome": "hold", "reason": "spam"}],
+		"blocked_domains": ["bad.example"], "spam": {"duplicate_limit": 5}}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	rules, err = LoadContentFilterRules(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5, rules.Spam.DuplicateLimit)
+	assert.Equal(t, DefaultContentFilterRules().Spam.DuplicateWindowMinutes, rules.Spam.DuplicateWindowMinutes)
+
+	pipeline, err := NewContentPipelineFromRules(rules, nil)
+	require.NoError(t, err)
+	verdict, err := pipeline.Check(newSubmission("this is a scam", "https://bad.example"))
+	require.NoError(t, err)
+	assert.Equal(t, ContentReject, verdict.Outcome)
+	assert.Equal(t, "link_blocklist", verdict.Checker)
+
+	_, err = NewContentPipelineFromRules(ContentFilterRules{Terms: []TermRule{{Outcome: "maybe"}}}, nil)
+	assert.Error(t, err)
+}
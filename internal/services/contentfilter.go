@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// ErrContentRejected is returned when the content filter rejects a post or comment
+var ErrContentRejected = errors.New("content rejected by the content filter")
+
+// ErrContentHeld is returned when an author tries to publish content that is awaiting review
+var ErrContentHeld = errors.New("this content is awaiting review by a moderator")
+
+// ContentFilterService runs the content checks on posts and comments before they are saved
+// and sends held content to the moderation queue
+type ContentFilterService struct {
+	BaseService
+	checker    ContentChecker
+	userRepo   *repositories.UserRepository
+	reportRepo *repositories.ReportRepository
+}
+
+// NewContentFilterService creates a new ContentFilterService
+func NewContentFilterService(checker ContentChecker, userRepo *repositories.UserRepository,
+	reportRepo *repositories.ReportRepository) *ContentFilterService {
+	return &ContentFilterService{
+		checker:    checker,
+		userRepo:   userRepo,
+		reportRepo: reportRepo,
+	}
+}
+
+// Check runs the content checks on a new or edited post or comment by userID. Links in the content
+// are checked along with linkURLs. Rejected content returns ErrContentRejected; otherwise the verdict
+// says whether to allow or hold the content.
+func (s *ContentFilterService) Check(userID int, resourceType, content string, linkURLs ...string) (ContentVerdict, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return ContentVerdict{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	submission := &models.ContentSubmission{
+		UserID:         userID,
+		ResourceType:   resourceType,
+		Content:        content,
+		LinkURLs:       utils.ExtractLinks(content),
+		AuthorJoinedAt: user.CreatedAt,
+		SubmittedAt:    time.Now(),
+	}
+	for _, linkURL := range linkURLs {
+		if linkURL != "" {
+			submission.LinkURLs = append(submission.LinkURLs, linkURL)
+		}
+	}
+	verdict, err := s.checker.Check(submission)
+	if err != nil {
+		return ContentVerdict{}, fmt.Errorf("failed to check content: %w", err)
+	}
+	if verdict.Outcome == ContentReject {
+		return verdict, fmt.Errorf("%w: %s", ErrContentRejected, verdict.Reason)
+	}
+	return verdict, nil
+}
+
+// ReportHeld adds held content to the moderation queue and records the hold in the audit trail.
+func (s *ContentFilterService) ReportHeld(resourceType string, resourceID, userID int, verdict ContentVerdict) {
+	report := &models.Report{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Reason:       verdict.Reason,
+		Details:      verdict.Detail,
+	}
+	if err := s.reportRepo.Create(report); err != nil {
+		log.Printf("WARN: Failed to report held %s %d: %v", resourceType, resourceID, err)
+	}
+
+	record := &models.ModerationAction{
+		Action:       models.ModerationActionHoldContent,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TargetUserID: &userID,
+		Note:         verdict.Checker + ": " + verdict.Detail,
+	}
+	if err := s.reportRepo.RecordAction(record, ""); err != nil {
+		log.Printf("WARN: Failed to record hold of %s %d: %v", resourceType, resourceID, err)
+	}
+}
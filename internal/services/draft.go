@@ -0,0 +1,341 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrDraftNotFound is returned when a draft does not exist or belongs to another user
+var ErrDraftNotFound = errors.New("draft not found")
+
+// ErrDraftBusy is returned when changing a draft that is being published
+var ErrDraftBusy = errors.New("the draft is being published")
+
+// ErrInvalidSchedule is returned when scheduling a post for a time that is not in the future
+var ErrInvalidSchedule = errors.New("posts can only be scheduled for a time in the future")
+
+// Publishing of scheduled drafts
+const (
+	// draftPublishLease is how long a server may take to publish a claimed draft
+	// before another server may claim it again
+	draftPublishLease = 2 * time.Minute
+	// draftPublishBatchSize is the most drafts a server claims at once
+	draftPublishBatchSize = 20
+)
+
+// DraftService provides functionality for drafts and scheduled posts
+type DraftService struct {
+	BaseService
+	draftRepo   *repositories.DraftRepository
+	postService *PostService
+}
+
+// NewDraftService creates a new DraftService
+func NewDraftService(draftRepo *repositories.DraftRepository, postService *PostService) *DraftService {
+	return &DraftService{
+		draftRepo:   draftRepo,
+		postService: postService,
+	}
+}
+
+// CreateDraft saves a draft. With publishAt it is scheduled to be published at that time.
+func (s *DraftService) CreateDraft(userID int, content, privacy string, attachments PostAttachments, publishAt *time.Time) (*models.PostDraft, error) {
+	if privacy == "" {
+		privacy = models.PrivacyPublic
+	}
+
+	draft := &models.PostDraft{
+		UserID:    userID,
+		Content:   content,
+		Privacy:   privacy,
+		LinkURL:   attachments.LinkURL,
+		ImageURLs: attachments.ImageURLs,
+		Poll:      attachments.Poll,
+		Status:    models.DraftStatusDraft,
+	}
+	if draft.ImageURLs == nil {
+		draft.ImageURLs = []string{}
+	}
+	if publishAt != nil {
+		draft.Status = models.DraftStatusScheduled
+		draft.PublishAt = publishAt
+	}
+
+	if err := s.validateDraft(draft); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Create(draft); err != nil {
+		return nil, fmt.Errorf("failed to create draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// GetDrafts retrieves a user's drafts, optionally only those with the given status
+func (s *DraftService) GetDrafts(userID int, status string, limit, offset int) ([]*models.PostDraft, error) {
+	drafts, err := s.draftRepo.GetDraftsByUserID(userID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+// GetDraft retrieves one of the user's drafts
+func (s *DraftService) GetDraft(draftID, userID int) (*models.PostDraft, error) {
+	return s.ownedDraft(draftID, userID)
+}
+
+// UpdateDraft replaces the content, privacy, link and poll of a draft. Its images and schedule are kept.
+func (s *DraftService) UpdateDraft(draftID, userID int, content, privacy, linkURL string, poll *models.Poll) (*models.PostDraft, error) {
+	draft, err := s.editableDraft(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Content = content
+	if privacy != "" {
+		draft.Privacy = privacy
+	}
+	draft.LinkURL = linkURL
+	draft.Poll = poll
+
+	if err := s.validateDraft(draft); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Update(draft); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDraftBusy
+		}
+		return nil, fmt.Errorf("failed to update draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// ScheduleDraft schedules or reschedules a draft to be published at publishAt
+func (s *DraftService) ScheduleDraft(draftID, userID int, publishAt time.Time) (*models.PostDraft, error) {
+	draft, err := s.editableDraft(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Status = models.DraftStatusScheduled
+	draft.PublishAt = &publishAt
+	draft.LastError = ""
+
+	if err := s.validateDraft(draft); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Update(draft); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDraftBusy
+		}
+		return nil, fmt.Errorf("failed to schedule draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// UnscheduleDraft cancels the schedule of a draft, keeping it as an unscheduled draft
+func (s *DraftService) UnscheduleDraft(draftID, userID int) (*models.PostDraft, error) {
+	draft, err := s.editableDraft(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Status = models.DraftStatusDraft
+	draft.PublishAt = nil
+
+	if err := s.draftRepo.Update(draft); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDraftBusy
+		}
+		return nil, fmt.Errorf("failed to unschedule draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// PublishDraft publishes one of the user's drafts right away
+func (s *DraftService) PublishDraft(draftID, userID int) (*models.Post, error) {
+	if _, err := s.ownedDraft(draftID, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	draft, err := s.draftRepo.Claim(draftID, now, now.Add(draftPublishLease))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDraftBusy
+		}
+		return nil, err
+	}
+
+	return s.publish(draft)
+}
+
+// DeleteDraft deletes one of the user's drafts and returns it, so its images can be removed
+func (s *DraftService) DeleteDraft(draftID, userID int) (*models.PostDraft, error) {
+	draft, err := s.editableDraft(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.DeleteUnlocked(draftID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDraftBusy
+		}
+		return nil, fmt.Errorf("failed to delete draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// PublishDue publishes a batch of scheduled drafts that are due and returns how many were claimed.
+// Drafts that can no longer be published, e.g. because their poll has closed in the meantime,
+// are turned back into unscheduled drafts with the reason in LastError.
+func (s *DraftService) PublishDue() (int, error) {
+	now := time.Now()
+	drafts, err := s.draftRepo.ClaimDue(now, now.Add(draftPublishLease), draftPublishBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim scheduled drafts: %w", err)
+	}
+
+	for _, draft := range drafts {
+		if post, err := s.publish(draft); err != nil {
+			log.Printf("WARN: Failed to publish scheduled draft %d: %v", draft.ID, err)
+		} else {
+			log.Printf("Published scheduled draft %d as post %d", draft.ID, post.ID)
+		}
+	}
+
+	return len(drafts), nil
+}
+
+// PublishAllDue publishes batches of due drafts until none are left or done is closed.
+// Every server runs it periodically; drafts are claimed with row locks, so each due
+// draft is published by exactly one of them.
+func (s *DraftService) PublishAllDue(done <-chan struct{}) {
+	for {
+		claimed, err := s.PublishDue()
+		if err != nil {
+			log.Printf("WARN: Failed to publish scheduled posts: %v", err)
+			return
+		}
+		if claimed < draftPublishBatchSize {
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+// publish publishes a claimed draft and deletes it. A draft whose content cannot be published or
+// is rejected by the content filter is marked as failed; after any other error it is released to be
+// published again.
+func (s *DraftService) publish(draft *models.PostDraft) (*models.Post, error) {
+	attachments := PostAttachments{ImageURLs: draft.ImageURLs, LinkURL: draft.LinkURL, Poll: draft.Poll}
+	if err := s.postService.ValidatePostContent(draft.Content, attachments); err != nil {
+		if markErr := s.draftRepo.MarkFailed(draft.ID, err.Error()); markErr != nil {
+			log.Printf("WARN: Failed to mark draft %d as failed: %v", draft.ID, markErr)
+		}
+		return nil, err
+	}
+
+	post, err := s.postService.PublishDraft(draft)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			// An earlier attempt published the post but stopped before deleting the draft
+			s.deletePublishedDraft(draft.ID)
+			return nil, fmt.Errorf("draft %d was already published", draft.ID)
+		}
+		if errors.Is(err, ErrContentRejected) {
+			if markErr := s.draftRepo.MarkFailed(draft.ID, err.Error()); markErr != nil {
+				log.Printf("WARN: Failed to mark draft %d as failed: %v", draft.ID, markErr)
+			}
+			return nil, err
+		}
+		if releaseErr := s.draftRepo.Release(draft.ID); releaseErr != nil {
+			log.Printf("WARN: Failed to release draft %d: %v", draft.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	s.deletePublishedDraft(draft.ID)
+	return post, nil
+}
+
+// deletePublishedDraft deletes a draft that has been published. If that fails, the draft
+// is published again once its lock expires, which fails with ErrConflict and retries this.
+func (s *DraftService) deletePublishedDraft(draftID int) {
+	if err := s.draftRepo.Delete(draftID); err != nil {
+		log.Printf("WARN: Failed to delete published draft %d: %v", draftID, err)
+	}
+}
+
+// validateDraft checks a draft before it is saved. Scheduled drafts must be publishable
+// and due in the future; unscheduled drafts may still be empty.
+func (s *DraftService) validateDraft(draft *models.PostDraft) error {
+	attachments := PostAttachments{ImageURLs: draft.ImageURLs, LinkURL: draft.LinkURL, Poll: draft.Poll}
+	err := s.postService.ValidatePostContent(draft.Content, attachments)
+	if err != nil && (draft.Status == models.DraftStatusScheduled || !errors.Is(err, ErrEmptyPost)) {
+		return err
+	}
+
+	if draft.Status != models.DraftStatusScheduled {
+		return nil
+	}
+	if draft.PublishAt == nil || !draft.PublishAt.After(time.Now()) {
+		return ErrInvalidSchedule
+	}
+	if draft.Poll != nil && draft.Poll.ClosesAt != nil && !draft.Poll.ClosesAt.After(*draft.PublishAt) {
+		return fmt.Errorf("%w: the poll must close after the post is published", ErrInvalidPoll)
+	}
+
+	return nil
+}
+
+// ownedDraft retrieves a draft, hiding the drafts of other users
+func (s *DraftService) ownedDraft(draftID, userID int) (*models.PostDraft, error) {
+	draft, err := s.draftRepo.GetByID(draftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+
+	if draft.UserID != userID {
+		return nil, ErrDraftNotFound
+	}
+
+	return draft, nil
+}
+
+// editableDraft retrieves one of the user's drafts that is not being published. The draft can still
+// be claimed before it is saved, so saving it checks the lock again.
+func (s *DraftService) editableDraft(draftID, userID int) (*models.PostDraft, error) {
+	draft, err := s.ownedDraft(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.LockedUntil != nil && draft.LockedUntil.After(time.Now()) {
+		return nil, ErrDraftBusy
+	}
+
+	return draft, nil
+}
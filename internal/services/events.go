@@ -0,0 +1,19 @@
+package services
+
+import (
+	"log"
+
+	"github.com/gocli/social_api/internal/realtime"
+)
+
+// publishEvent pushes a real-time event to the subscribers of a topic. Events are
+// best effort: the change they describe is already saved, so failures are only logged.
+func publishEvent(publisher realtime.Publisher, topic, eventType string, data interface{}) {
+	event, err := realtime.NewEvent(topic, eventType, data)
+	if err == nil {
+		err = publisher.Publish(event)
+	}
+	if err != nil {
+		log.Printf("WARN: Failed to publish %s event to %s: %v", eventType, topic, err)
+	}
+}
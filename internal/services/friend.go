@@ -2,23 +2,34 @@ package services
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/realtime"
 	"github.com/gocli/social_api/internal/repositories"
 )
 
 // FriendService provides friend-related functionality
 type FriendService struct {
 	BaseService
-	friendRepo *repositories.FriendRepository
-	userRepo   *repositories.UserRepository
+	friendRepo          *repositories.FriendRepository
+	userRepo            *repositories.UserRepository
+	blockRepo           *repositories.BlockRepository
+	visibilityService   *VisibilityService
+	notificationService *NotificationService
+	publisher           realtime.Publisher
 }
 
 // NewFriendService creates a new FriendService
-func NewFriendService(friendRepo *repositories.FriendRepository, userRepo *repositories.UserRepository) *FriendService {
+func NewFriendService(friendRepo *repositories.FriendRepository, userRepo *repositories.UserRepository,
+	blockRepo *repositories.BlockRepository, visibilityService *VisibilityService, notificationService *NotificationService, publisher realtime.Publisher) *FriendService {
 	return &FriendService{
-		friendRepo: friendRepo,
-		userRepo:   userRepo,
+		friendRepo:          friendRepo,
+		userRepo:            userRepo,
+		blockRepo:           blockRepo,
+		visibilityService:   visibilityService,
+		notificationService: notificationService,
+		publisher:           publisher,
 	}
 }
 
@@ -35,6 +46,15 @@ func (s *FriendService) SendFriendRequest(fromUserID, toUserID int) (*models.Fri
 		return nil, fmt.Errorf("recipient user not found: %w", err)
 	}
 
+	// Check neither user has blocked the other
+	blocked, err := s.blockRepo.IsBlocked(fromUserID, toUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block: %w", err)
+	}
+	if blocked {
+		return nil, fmt.Errorf("cannot send a friend request to this user")
+	}
+
 	// Create friend request
 	request := &models.FriendRequest{
 		FromUserID: fromUserID,
@@ -46,6 +66,13 @@ func (s *FriendService) SendFriendRequest(fromUserID, toUserID int) (*models.Fri
 		return nil, fmt.Errorf("failed to create friend request: %w", err)
 	}
 
+	err = s.notificationService.Notify(toUserID, fromUserID, models.NotificationTypeFriendRequest,
+		models.ResourceTypeFriendRequests, request.ID)
+	if err != nil {
+		log.Printf("WARN: Failed to notify user %d about friend request: %v", toUserID, err)
+	}
+	publishEvent(s.publisher, realtime.UserTopic(toUserID), realtime.EventFriendRequestReceived, request)
+
 	return request, nil
 }
 
@@ -86,6 +113,14 @@ func (s *FriendService) AcceptFriendRequest(requestID, userID int) error {
 		return fmt.Errorf("failed to create friendship: %w", err)
 	}
 
+	err = s.notificationService.Notify(request.FromUserID, userID, models.NotificationTypeFriendAccept,
+		models.ResourceTypeFriendRequests, requestID)
+	if err != nil {
+		log.Printf("WARN: Failed to notify user %d about accepted friend request: %v", request.FromUserID, err)
+	}
+	request.Status = "accepted"
+	publishEvent(s.publisher, realtime.UserTopic(request.FromUserID), realtime.EventFriendRequestAccepted, request)
+
 	return nil
 }
 
@@ -110,8 +145,21 @@ func (s *FriendService) RejectFriendRequest(requestID, userID int) error {
 	return nil
 }
 
-// GetFriendsForUser retrieves friends for a user
-func (s *FriendService) GetFriendsForUser(userID int) ([]*models.UserPublic, error) {
+// GetFriendsForUser retrieves friends for a user if the viewer is allowed to see the friend list
+func (s *FriendService) GetFriendsForUser(userID, viewerID int) ([]*models.UserPublic, error) {
+	settings, err := s.userRepo.GetPrivacySettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get privacy settings: %w", err)
+	}
+
+	canView, err := s.visibilityService.CanView(userID, viewerID, settings.FriendListVisibility)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrNotVisible
+	}
+
 	friends, err := s.friendRepo.GetFriendsForUser(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get friends: %w", err)
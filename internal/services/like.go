@@ -3,6 +3,7 @@ package services
 
 import (
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/gocli/social_api/internal/models"
@@ -12,34 +13,72 @@ import (
 // LikeService provides like-related functionality
 type LikeService struct {
 	BaseService
-	likeRepo *repositories.LikeRepository
+	likeRepo            *repositories.LikeRepository
+	resourceRegistry    *ResourceRegistry
+	notificationService *NotificationService
 }
 
 // NewLikeService creates a new LikeService
-func NewLikeService(likeRepo *repositories.LikeRepository) *LikeService {
+func NewLikeService(likeRepo *repositories.LikeRepository, resourceRegistry *ResourceRegistry,
+	notificationService *NotificationService) *LikeService {
 	return &LikeService{
-		likeRepo: likeRepo,
+		likeRepo:            likeRepo,
+		resourceRegistry:    resourceRegistry,
+		notificationService: notificationService,
 	}
 }
 
-// LikeResource creates a like for a resource
+// LikeResource likes a resource, replacing any other reaction by the user
 func (s *LikeService) LikeResource(userID int, resourceType string, resourceID int) error {
+	return s.ReactToResource(userID, resourceType, resourceID, models.ReactionLike)
+}
+
+// ReactToResource sets the user's reaction to a resource, replacing any earlier reaction
+func (s *LikeService) ReactToResource(userID int, resourceType string, resourceID int, reactionType string) error {
+	if !models.IsValidReactionType(reactionType) {
+		return fmt.Errorf("invalid reaction type: %q", reactionType)
+	}
+
+	if err := s.resourceRegistry.Check(resourceType, resourceID, userID, ResourceLikeable); err != nil {
+		return err
+	}
+
 	like := &models.Like{
 		UserID:       userID,
 		ResourceType: resourceType,
 		ResourceID:   resourceID,
+		Type:         reactionType,
 		CreatedAt:    time.Now(),
 	}
 
-	if err := s.likeRepo.CreateLike(like); err != nil {
-		return fmt.Errorf("failed to like resource: %w", err)
+	created, err := s.likeRepo.SetReaction(like)
+	if err != nil {
+		return fmt.Errorf("failed to react to resource: %w", err)
+	}
+
+	// Changing a reaction does not notify the owner again
+	if !created {
+		return nil
+	}
+
+	notificationType := models.NotificationTypeReaction
+	if reactionType == models.ReactionLike {
+		notificationType = models.NotificationTypeLike
+	}
+
+	if err := s.notificationService.NotifyResourceOwner(userID, notificationType, resourceType, resourceID); err != nil {
+		log.Printf("WARN: Failed to notify owner of %s %d about reaction: %v", resourceType, resourceID, err)
 	}
 
 	return nil
 }
 
-// UnlikeResource removes a like for a resource
+// UnlikeResource removes the user's reaction to a resource
 func (s *LikeService) UnlikeResource(userID int, resourceType string, resourceID int) error {
+	if err := s.resourceRegistry.Check(resourceType, resourceID, userID, ResourceLikeable); err != nil {
+		return err
+	}
+
 	if err := s.likeRepo.DeleteLike(userID, resourceType, resourceID); err != nil {
 		return fmt.Errorf("failed to unlike resource: %w", err)
 	}
@@ -47,12 +86,31 @@ func (s *LikeService) UnlikeResource(userID int, resourceType string, resourceID
 	return nil
 }
 
-// GetLikesForResource retrieves all likes for a specific resource
-func (s *LikeService) GetLikesForResource(resourceType string, resourceID int) ([]*models.Like, error) {
-	likes, err := s.likeRepo.GetLikesForResource(resourceType, resourceID)
+// GetLikesForResource retrieves the per-type reaction counts of a resource and a page of the
+// users who reacted. A non-empty reactionType only lists reactions of that type.
+func (s *LikeService) GetLikesForResource(resourceType string, resourceID, viewerID int, reactionType string, limit, offset int) (*models.ReactionSummary, error) {
+	if reactionType != "" && !models.IsValidReactionType(reactionType) {
+		return nil, fmt.Errorf("invalid reaction type: %q", reactionType)
+	}
+
+	if err := s.resourceRegistry.Check(resourceType, resourceID, viewerID, ResourceLikeable); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.likeRepo.GetReactionCounts(resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reaction counts: %w", err)
+	}
+
+	likes, err := s.likeRepo.GetLikesForResource(resourceType, resourceID, reactionType, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get likes: %w", err)
 	}
 
-	return likes, nil
+	summary := &models.ReactionSummary{Counts: counts, Reactions: likes}
+	for _, count := range counts {
+		summary.Total += count
+	}
+
+	return summary, nil
 }
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// ErrInvalidLink is returned for links that are not absolute http or https URLs
+var ErrInvalidLink = errors.New("link must be an http or https URL")
+
+// ErrLinkNotAllowed is returned when a link resolves to an address the server must not connect to
+var ErrLinkNotAllowed = errors.New("link points to an address that is not allowed")
+
+// Limits on fetching a page for a link preview
+const (
+	linkPreviewTimeout      = 5 * time.Second
+	maxLinkPreviewBytes     = 1 << 20
+	maxLinkPreviewRedirects = 5
+)
+
+// carrierGradeNAT is the shared address space of RFC 6598, which net.IP does not treat as private
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// LinkPreviewService fetches pages server-side to build link previews.
+// Every connection, including those made while following redirects, is checked
+// after DNS resolution, so a link cannot reach loopback, private or link-local
+// addresses or ports other than 80 and 443.
+type LinkPreviewService struct {
+	BaseService
+	client *http.Client
+}
+
+// NewLinkPreviewService creates a new LinkPreviewService
+func NewLinkPreviewService() *LinkPreviewService {
+	return newLinkPreviewService(isPublicAddress)
+}
+
+// newLinkPreviewService creates a LinkPreviewService that only connects to addresses allowAddress accepts
+func newLinkPreviewService(allowAddress func(ip net.IP, port string) bool) *LinkPreviewService {
+	dialer := &net.Dialer{
+		Timeout: linkPreviewTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return ErrLinkNotAllowed
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowAddress(ip, port) {
+				return ErrLinkNotAllowed
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                  nil, // A proxy would make the dialer check the proxy instead of the target
+		DialContext:            dialer.DialContext,
+		TLSHandshakeTimeout:    linkPreviewTimeout,
+		ResponseHeaderTimeout:  linkPreviewTimeout,
+		MaxResponseHeaderBytes: 64 << 10,
+	}
+
+	return &LinkPreviewService{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   linkPreviewTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxLinkPreviewRedirects {
+					return errors.New("too many redirects")
+				}
+				return validateLinkURL(req.URL)
+			},
+		},
+	}
+}
+
+// Fetch downloads the page at rawURL and returns a link attachment with its preview
+func (s *LinkPreviewService) Fetch(rawURL string) (*models.PostAttachment, error) {
+	pageURL, err := ParseLinkURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "social_api-link-preview/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrLinkNotAllowed) {
+			return nil, ErrLinkNotAllowed
+		}
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			// In a real application, you would log this error
+			// For now, we'll just ignore it to keep the code simple
+			_ = closeErr
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch link: status %d", resp.StatusCode)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "text/html" {
+		return nil, fmt.Errorf("failed to fetch link: not an HTML page")
+	}
+
+	// Resolve relative image URLs against the page that was finally served
+	preview := utils.ParseLinkPreview(io.LimitReader(resp.Body, maxLinkPreviewBytes), resp.Request.URL)
+
+	return &models.PostAttachment{
+		Type:        models.AttachmentTypeLink,
+		URL:         pageURL.String(),
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageURL,
+	}, nil
+}
+
+// ParseLinkURL parses a link and checks that it is an absolute http or https URL without credentials
+func ParseLinkURL(rawURL string) (*url.URL, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+	if err := validateLinkURL(pageURL); err != nil {
+		return nil, err
+	}
+	return pageURL, nil
+}
+
+// validateLinkURL checks that a URL may be fetched for a link preview
+func validateLinkURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return ErrInvalidLink
+	}
+	return nil
+}
+
+// isPublicAddress reports whether ip is a globally routable unicast address and port is a standard web port
+func isPublicAddress(ip net.IP, port string) bool {
+	if port != "80" && port != "443" {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 0 {
+		return false // "This network", which some systems route to the local host
+	}
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !carrierGradeNAT.Contains(ip)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// maxMentionedUsers caps how many distinct users a single post or comment can mention
+const maxMentionedUsers = 50
+
+// MentionNotifier is told when a user is mentioned in content they are allowed to see
+type MentionNotifier interface {
+	NotifyMention(recipientID, actorID int, resourceType string, resourceID int) error
+}
+
+// MentionService provides @mention-related functionality
+type MentionService struct {
+	BaseService
+	mentionRepo       *repositories.MentionRepository
+	userRepo          *repositories.UserRepository
+	visibilityService *VisibilityService
+	notifier          MentionNotifier
+}
+
+// NewMentionService creates a new MentionService. notifier may be nil, in which
+// case mentions are stored but nobody is notified.
+func NewMentionService(mentionRepo *repositories.MentionRepository, userRepo *repositories.UserRepository, visibilityService *VisibilityService, notifier MentionNotifier) *MentionService {
+	return &MentionService{
+		mentionRepo:       mentionRepo,
+		userRepo:          userRepo,
+		visibilityService: visibilityService,
+		notifier:          notifier,
+	}
+}
+
+// SaveMentions extracts the @mentions in content written by authorID, resolves
+// them to users and stores them for the resource, replacing earlier ones.
+// audienceType and audienceID identify the post, photo or album whose privacy
+// decides who can see the content: newly mentioned users are only notified if
+// notify is set and they can see it, so a mention never leaks content to someone
+// outside its audience.
+func (s *MentionService) SaveMentions(resourceType string, resourceID, authorID int, content, audienceType string, audienceID int,
+	notify bool) ([]*models.Mention, error) {
+	matches := utils.ExtractMentions(content)
+
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, match := range matches {
+		key := strings.ToLower(match.Username)
+		if !seen[key] && len(usernames) < maxMentionedUsers {
+			seen[key] = true
+			usernames = append(usernames, match.Username)
+		}
+	}
+
+	userIDs, err := s.userRepo.GetIDsByUsernames(usernames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+	}
+
+	mentions := []*models.Mention{}
+	for _, match := range matches {
+		userID, ok := userIDs[strings.ToLower(match.Username)]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, &models.Mention{
+			UserID:   userID,
+			Username: match.Username,
+			Offset:   match.Offset,
+			Length:   match.Length,
+		})
+	}
+
+	// Remember who was already mentioned so edits do not notify them again
+	previous, err := s.mentionRepo.GetMentionsForResources(resourceType, []int{resourceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentions: %w", err)
+	}
+	notified := map[int]bool{authorID: true}
+	for _, mention := range previous[resourceID] {
+		notified[mention.UserID] = true
+	}
+
+	if err := s.mentionRepo.ReplaceMentions(resourceType, resourceID, mentions); err != nil {
+		return nil, fmt.Errorf("failed to save mentions: %w", err)
+	}
+	if !notify {
+		return mentions, nil
+	}
+
+	for _, mention := range mentions {
+		if notified[mention.UserID] {
+			continue
+		}
+		notified[mention.UserID] = true
+		s.notify(mention.UserID, authorID, resourceType, resourceID, audienceType, audienceID)
+	}
+
+	return mentions, nil
+}
+
+// GetMentions retrieves the mentions of several resources of the same type, keyed by resource ID
+func (s *MentionService) GetMentions(resourceType string, resourceIDs []int) (map[int][]*models.Mention, error) {
+	mentions, err := s.mentionRepo.GetMentionsForResources(resourceType, resourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentions: %w", err)
+	}
+	return mentions, nil
+}
+
+// DeleteMentions removes the mentions stored for a resource
+func (s *MentionService) DeleteMentions(resourceType string, resourceID int) error {
+	if err := s.mentionRepo.DeleteMentionsForResource(resourceType, resourceID); err != nil {
+		return fmt.Errorf("failed to delete mentions: %w", err)
+	}
+	return nil
+}
+
+// notify tells a mentioned user about the mention if they can see the content.
+func (s *MentionService) notify(recipientID, actorID int, resourceType string, resourceID int, audienceType string, audienceID int) {
+	if s.notifier == nil {
+		return
+	}
+
+	canView, err := s.visibilityService.CanViewResource(audienceType, audienceID, recipientID)
+	if err != nil {
+		log.Printf("WARN: Failed to check visibility of %s %d for mention: %v", audienceType, audienceID, err)
+		return
+	}
+	if !canView {
+		return
+	}
+
+	if err := s.notifier.NotifyMention(recipientID, actorID, resourceType, resourceID); err != nil {
+		log.Printf("WARN: Failed to notify user %d of mention: %v", recipientID, err)
+	}
+}
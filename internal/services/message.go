@@ -0,0 +1,394 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/realtime"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// MaxGroupMembers is the largest number of members a group conversation can have
+const MaxGroupMembers = 10
+
+// ErrConversationNotFound is returned when a conversation does not exist or the user is not a member of it
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ErrMessagingNotAllowed is returned when the friendship graph or a block does not allow messaging a user
+var ErrMessagingNotAllowed = errors.New("messaging this user is not allowed")
+
+// MessageService provides direct messaging functionality
+type MessageService struct {
+	BaseService
+	conversationRepo *repositories.ConversationRepository
+	friendRepo       *repositories.FriendRepository
+	blockRepo        *repositories.BlockRepository
+	publisher        realtime.Publisher
+}
+
+// NewMessageService creates a new MessageService
+func NewMessageService(conversationRepo *repositories.ConversationRepository, friendRepo *repositories.FriendRepository,
+	blockRepo *repositories.BlockRepository, publisher realtime.Publisher) *MessageService {
+	return &MessageService{
+		conversationRepo: conversationRepo,
+		friendRepo:       friendRepo,
+		blockRepo:        blockRepo,
+		publisher:        publisher,
+	}
+}
+
+// StartConversation starts a conversation between userID and memberIDs. A single
+// member without a title gives the one-to-one conversation between the two users,
+// which is reused if it already exists; anything else creates a group. Users can
+// only start conversations with friends they have not blocked and are not blocked by.
+func (s *MessageService) StartConversation(userID int, memberIDs []int, title string) (*models.Conversation, error) {
+	others := []int{}
+	seen := map[int]bool{userID: true}
+	for _, memberID := range memberIDs {
+		if !seen[memberID] {
+			seen[memberID] = true
+			others = append(others, memberID)
+		}
+	}
+
+	if len(others) == 0 {
+		return nil, fmt.Errorf("a conversation needs at least one other member")
+	}
+	if len(others)+1 > MaxGroupMembers {
+		return nil, fmt.Errorf("a conversation can have at most %d members", MaxGroupMembers)
+	}
+
+	for _, memberID := range others {
+		if err := s.checkCanMessage(userID, memberID); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(others) == 1 && title == "" {
+		return s.startDirectConversation(userID, others[0])
+	}
+
+	conversation := &models.Conversation{
+		IsGroup:   true,
+		Title:     title,
+		CreatedBy: userID,
+	}
+	if err := s.conversationRepo.CreateConversation(conversation, "", append([]int{userID}, others...)); err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	return s.GetConversation(conversation.ID, userID)
+}
+
+// startDirectConversation returns the one-to-one conversation between two users, creating it if needed
+func (s *MessageService) startDirectConversation(userID, otherUserID int) (*models.Conversation, error) {
+	directKey := directConversationKey(userID, otherUserID)
+
+	conversationID, err := s.conversationRepo.GetDirectConversationID(directKey)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	if err != nil {
+		conversation := &models.Conversation{CreatedBy: userID}
+		err = s.conversationRepo.CreateConversation(conversation, directKey, []int{userID, otherUserID})
+		if errors.Is(err, repositories.ErrConflict) {
+			// Both users started the conversation at the same time
+			return s.startDirectConversation(userID, otherUserID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create conversation: %w", err)
+		}
+		return s.GetConversation(conversation.ID, userID)
+	}
+
+	// Bring the conversation back if the user had left it
+	if err := s.conversationRepo.AddMember(conversationID, userID); err != nil {
+		return nil, fmt.Errorf("failed to join conversation: %w", err)
+	}
+
+	return s.GetConversation(conversationID, userID)
+}
+
+// GetConversations retrieves a page of the user's conversations
+func (s *MessageService) GetConversations(userID, limit, offset int) ([]*models.Conversation, error) {
+	conversations, err := s.conversationRepo.GetConversationsForUser(userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations: %w", err)
+	}
+
+	if err := s.attachMembers(conversations); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+// GetConversation retrieves a conversation the user is a member of
+func (s *MessageService) GetConversation(conversationID, userID int) (*models.Conversation, error) {
+	conversation, err := s.conversationRepo.GetConversationForMember(conversationID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	if err := s.attachMembers([]*models.Conversation{conversation}); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// SendMessage sends a message with text, an image attachment or both. Friendships
+// and blocks can change after a conversation starts, so they are checked on every
+// message: a one-to-one conversation needs the users to still be friends, and no
+// conversation can be written to while the sender and another member block each
+// other. In a one-to-one conversation the other user is brought back if they had left.
+func (s *MessageService) SendMessage(conversationID, userID int, content, attachmentURL string) (*models.Message, error) {
+	if content == "" && attachmentURL == "" {
+		return nil, fmt.Errorf("a message needs text or an attachment")
+	}
+
+	conversation, err := s.GetConversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation.IsGroup {
+		// Group members need not be friends with each other
+		for _, member := range conversation.Members {
+			if member.UserID == userID {
+				continue
+			}
+			blocked, err := s.blockRepo.IsBlocked(userID, member.UserID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check block: %w", err)
+			}
+			if blocked {
+				return nil, fmt.Errorf("%w: user %d cannot be messaged", ErrMessagingNotAllowed, member.UserID)
+			}
+		}
+	} else {
+		memberIDs, err := s.conversationRepo.GetMemberIDs(conversationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conversation members: %w", err)
+		}
+		for _, memberID := range memberIDs {
+			if memberID == userID {
+				continue
+			}
+			if err := s.checkCanMessage(userID, memberID); err != nil {
+				return nil, err
+			}
+			if err := s.conversationRepo.AddMember(conversationID, memberID); err != nil {
+				return nil, fmt.Errorf("failed to rejoin conversation member: %w", err)
+			}
+		}
+	}
+
+	message := &models.Message{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        content,
+		AttachmentURL:  attachmentURL,
+	}
+	if err := s.conversationRepo.CreateMessage(message); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	// Senders have read their own messages
+	if err := s.conversationRepo.MarkRead(conversationID, userID, message.ID); err != nil {
+		log.Printf("WARN: Failed to mark conversation %d as read: %v", conversationID, err)
+	}
+
+	members, err := s.conversationRepo.GetActiveMembers([]int{conversationID})
+	if err != nil {
+		log.Printf("WARN: Failed to get members of conversation %d: %v", conversationID, err)
+	}
+	for _, member := range members[conversationID] {
+		publishEvent(s.publisher, realtime.UserTopic(member.UserID), realtime.EventMessageCreated, message)
+	}
+
+	return message, nil
+}
+
+// GetMessages retrieves a page of messages, newest first. Pass the previous
+// page's NextCursor as before to get older messages.
+func (s *MessageService) GetMessages(conversationID, userID, before, limit int) (*models.MessagePage, error) {
+	member, err := s.activeMember(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.conversationRepo.GetMessages(conversationID, member.JoinedAt, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	page := &models.MessagePage{Messages: messages}
+	if len(messages) == limit {
+		page.NextCursor = messages[len(messages)-1].ID
+	}
+
+	return page, nil
+}
+
+// MarkRead marks a conversation as read up to a message, or up to the newest
+// message when messageID is zero, and tells the other members
+func (s *MessageService) MarkRead(conversationID, userID, messageID int) error {
+	if _, err := s.activeMember(conversationID, userID); err != nil {
+		return err
+	}
+
+	if messageID == 0 {
+		latestID, err := s.conversationRepo.GetLatestMessageID(conversationID)
+		if err != nil {
+			return fmt.Errorf("failed to get latest message: %w", err)
+		}
+		messageID = latestID
+	}
+
+	if err := s.conversationRepo.MarkRead(conversationID, userID, messageID); err != nil {
+		return fmt.Errorf("failed to mark conversation as read: %w", err)
+	}
+
+	members, err := s.conversationRepo.GetActiveMembers([]int{conversationID})
+	if err != nil {
+		log.Printf("WARN: Failed to get members of conversation %d: %v", conversationID, err)
+	}
+	receipt := map[string]int{"conversation_id": conversationID, "user_id": userID, "last_read_message_id": messageID}
+	for _, member := range members[conversationID] {
+		if member.UserID != userID {
+			publishEvent(s.publisher, realtime.UserTopic(member.UserID), realtime.EventConversationRead, receipt)
+		}
+	}
+
+	return nil
+}
+
+// AddMember adds a friend of the user to a group conversation
+func (s *MessageService) AddMember(conversationID, userID, newMemberID int) (*models.Conversation, error) {
+	conversation, err := s.GetConversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !conversation.IsGroup {
+		return nil, fmt.Errorf("members can only be added to group conversations")
+	}
+	if len(conversation.Members) >= MaxGroupMembers {
+		return nil, fmt.Errorf("a conversation can have at most %d members", MaxGroupMembers)
+	}
+
+	if err := s.checkCanMessage(userID, newMemberID); err != nil {
+		return nil, err
+	}
+
+	if err := s.conversationRepo.AddMember(conversationID, newMemberID); err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	return s.GetConversation(conversationID, userID)
+}
+
+// SetMuted mutes or unmutes a conversation for the user. Muted conversations
+// are left out of the total unread count.
+func (s *MessageService) SetMuted(conversationID, userID int, muted bool) error {
+	if _, err := s.activeMember(conversationID, userID); err != nil {
+		return err
+	}
+
+	if err := s.conversationRepo.SetMuted(conversationID, userID, muted); err != nil {
+		return fmt.Errorf("failed to mute conversation: %w", err)
+	}
+
+	return nil
+}
+
+// LeaveConversation removes the user from a conversation
+func (s *MessageService) LeaveConversation(conversationID, userID int) error {
+	if _, err := s.activeMember(conversationID, userID); err != nil {
+		return err
+	}
+
+	if err := s.conversationRepo.LeaveConversation(conversationID, userID); err != nil {
+		return fmt.Errorf("failed to leave conversation: %w", err)
+	}
+
+	return nil
+}
+
+// GetUnreadCount counts the user's unread messages in conversations they have not muted
+func (s *MessageService) GetUnreadCount(userID int) (int, error) {
+	count, err := s.conversationRepo.CountUnread(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// activeMember returns the user's membership in a conversation they have not left
+func (s *MessageService) activeMember(conversationID, userID int) (*models.ConversationMember, error) {
+	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, fmt.Errorf("failed to get conversation member: %w", err)
+	}
+	if member.LeftAt != nil {
+		return nil, ErrConversationNotFound
+	}
+	return member, nil
+}
+
+// checkCanMessage returns ErrMessagingNotAllowed unless the users are friends and neither blocks the other
+func (s *MessageService) checkCanMessage(userID, otherUserID int) error {
+	blocked, err := s.blockRepo.IsBlocked(userID, otherUserID)
+	if err != nil {
+		return fmt.Errorf("failed to check block: %w", err)
+	}
+	if blocked {
+		return fmt.Errorf("%w: user %d cannot be messaged", ErrMessagingNotAllowed, otherUserID)
+	}
+
+	areFriends, err := s.friendRepo.AreFriends(userID, otherUserID)
+	if err != nil {
+		return fmt.Errorf("failed to check friendship: %w", err)
+	}
+	if !areFriends {
+		return fmt.Errorf("%w: user %d is not your friend", ErrMessagingNotAllowed, otherUserID)
+	}
+
+	return nil
+}
+
+// attachMembers fills in the current members of conversations
+func (s *MessageService) attachMembers(conversations []*models.Conversation) error {
+	conversationIDs := make([]int, len(conversations))
+	for i, conversation := range conversations {
+		conversationIDs[i] = conversation.ID
+	}
+
+	members, err := s.conversationRepo.GetActiveMembers(conversationIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get conversation members: %w", err)
+	}
+	for _, conversation := range conversations {
+		conversation.Members = members[conversation.ID]
+	}
+
+	return nil
+}
+
+// directConversationKey identifies the one-to-one conversation between two users
+func directConversationKey(userID, otherUserID int) string {
+	if userID > otherUserID {
+		userID, otherUserID = otherUserID, userID
+	}
+	return fmt.Sprintf("%d:%d", userID, otherUserID)
+}
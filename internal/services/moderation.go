@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrCannotReportSelf is returned when a user reports themselves or their own content
+var ErrCannotReportSelf = errors.New("you cannot report yourself or your own content")
+
+// ErrInvalidModerationAction is returned for actions that cannot be applied to the reported content
+var ErrInvalidModerationAction = errors.New("invalid moderation action")
+
+// ErrContentModerated is returned when an author tries to undo a moderator's decision on their content
+var ErrContentModerated = errors.New("this content was hidden by a moderator")
+
+// ModerationService provides abuse reporting and the moderation of reported content
+type ModerationService struct {
+	BaseService
+	reportRepo        *repositories.ReportRepository
+	userRepo          *repositories.UserRepository
+	postRepo          *repositories.PostRepository
+	commentRepo       *repositories.CommentRepository
+	albumRepo         *repositories.AlbumRepository
+	visibilityService *VisibilityService
+	resourceRegistry  *ResourceRegistry
+	suspensionService *SuspensionService
+}
+
+// NewModerationService creates a new ModerationService
+func NewModerationService(reportRepo *repositories.ReportRepository, userRepo *repositories.UserRepository,
+	postRepo *repositories.PostRepository, commentRepo *repositories.CommentRepository,
+	albumRepo *repositories.AlbumRepository, visibilityService *VisibilityService,
+	resourceRegistry *ResourceRegistry, suspensionService *SuspensionService) *ModerationService {
+	return &ModerationService{
+		reportRepo:        reportRepo,
+		userRepo:          userRepo,
+		postRepo:          postRepo,
+		commentRepo:       commentRepo,
+		albumRepo:         albumRepo,
+		visibilityService: visibilityService,
+		resourceRegistry:  resourceRegistry,
+		suspensionService: suspensionService,
+	}
+}
+
+// ReportContent reports a post, comment or photo the reporter can see, or a user's profile.
+// A second open report on the same content by the same user returns repositories.ErrConflict.
+func (s *ModerationService) ReportContent(reporterID int, resourceType string, resourceID int, reason, details string) (*models.Report, error) {
+	ownerID, err := s.reportedUser(reporterID, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporterID {
+		return nil, ErrCannotReportSelf
+	}
+
+	report := &models.Report{
+		ReporterID:   &reporterID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Reason:       reason,
+		Details:      details,
+	}
+	if err := s.reportRepo.Create(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetQueue retrieves the reported content awaiting review, the most reported first
+func (s *ModerationService) GetQueue(limit, offset int) ([]*models.ReportQueueItem, error) {
+	items, err := s.reportRepo.GetQueue(limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report queue: %w", err)
+	}
+	return items, nil
+}
+
+// GetReports retrieves every report, open or resolved, on a piece of content
+func (s *ModerationService) GetReports(resourceType string, resourceID int) ([]*models.Report, error) {
+	reports, err := s.reportRepo.GetReportsForResource(resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+	return reports, nil
+}
+
+// TakeAction applies a moderator's decision to reported content, records it in the audit trail and
+// resolves the open reports on the content. Suspending a user requires a positive duration;
+// the suspension's reason defaults to other. Dismissing the reports on content held by the content
+// filter publishes it; any other action keeps it out of view.
+func (s *ModerationService) TakeAction(moderatorID int, resourceType string, resourceID int, action, reason, note string,
+	duration time.Duration) (*models.ModerationAction, error) {
+	targetUserID, err := s.contentOwner(resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.ModerationAction{
+		ModeratorID:  &moderatorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TargetUserID: &targetUserID,
+		Note:         note,
+	}
+	reportStatus := models.ReportStatusActioned
+
+	switch action {
+	case models.ModerationActionHideContent:
+		if err := s.hideContent(resourceType, resourceID); err != nil {
+			return nil, err
+		}
+	case models.ModerationActionWarnUser:
+		// A warning only goes on the user's record
+	case models.ModerationActionSuspendUser:
+		if reason == "" {
+			reason = models.ReportReasonOther
+		}
+		suspension, err := s.suspensionService.Suspend(moderatorID, targetUserID, models.SuspensionKindSuspension,
+			reason, note, duration)
+		if err != nil {
+			return nil, err
+		}
+		record.ExpiresAt = &suspension.ExpiresAt
+	case models.ModerationActionDismiss:
+		if err := s.releaseContent(resourceType, resourceID); err != nil {
+			return nil, err
+		}
+		reportStatus = models.ReportStatusDismissed
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidModerationAction, action)
+	}
+
+	if err := s.RecordAction(record, reportStatus); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// RecordAction adds an action to the audit trail and closes the open reports on its content with reportStatus.
+// An empty reportStatus leaves them open.
+func (s *ModerationService) RecordAction(record *models.ModerationAction, reportStatus string) error {
+	if err := s.reportRepo.RecordAction(record, reportStatus); err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+	return nil
+}
+
+// GetActions retrieves the moderation audit trail, optionally only the actions against one user
+func (s *ModerationService) GetActions(targetUserID *int, limit, offset int) ([]*models.ModerationAction, error) {
+	actions, err := s.reportRepo.GetActions(targetUserID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation actions: %w", err)
+	}
+	return actions, nil
+}
+
+// GetMyActions retrieves the moderation actions taken against a user, without naming the moderators
+func (s *ModerationService) GetMyActions(userID, limit, offset int) ([]*models.ModerationAction, error) {
+	actions, err := s.GetActions(&userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		action.ModeratorID = nil
+	}
+	return actions, nil
+}
+
+// reportedUser checks that the reporter can see the reported content and returns the user it belongs to
+func (s *ModerationService) reportedUser(reporterID int, resourceType string, resourceID int) (int, error) {
+	if resourceType == models.ResourceTypeUsers {
+		if _, err := s.userRepo.GetByID(resourceID); err != nil {
+			return 0, ErrResourceNotFound
+		}
+		return resourceID, nil
+	}
+
+	if err := s.resourceRegistry.Check(resourceType, resourceID, reporterID, ResourceReportable); err != nil {
+		return 0, err
+	}
+	return s.contentOwner(resourceType, resourceID)
+}
+
+// contentOwner returns the user responsible for reported content: the author of a post or comment,
+// the owner of a photo's album, or the reported user themselves
+func (s *ModerationService) contentOwner(resourceType string, resourceID int) (int, error) {
+	switch resourceType {
+	case models.ResourceTypeUsers:
+		if _, err := s.userRepo.GetByID(resourceID); err != nil {
+			return 0, ErrResourceNotFound
+		}
+		return resourceID, nil
+	case models.ResourceTypePosts, models.ResourceTypeComments, models.ResourceTypePhotos:
+		ownerID, err := s.visibilityService.ResourceOwner(resourceType, resourceID)
+		if err != nil {
+			return 0, ErrResourceNotFound
+		}
+		return ownerID, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownResourceType, resourceType)
+	}
+}
+
+// releaseContent publishes a post or comment held by the content filter. Content that is not held is left as it is.
+func (s *ModerationService) releaseContent(resourceType string, resourceID int) error {
+	switch resourceType {
+	case models.ResourceTypePosts:
+		return s.postRepo.Release(resourceID)
+	case models.ResourceTypeComments:
+		return s.commentRepo.Release(resourceID)
+	default:
+		return nil
+	}
+}
+
+// hideContent takes reported content out of view. Posts are left visible to their author only,
+// comments are hidden, and photos, which have no audience of their own, are removed.
+func (s *ModerationService) hideContent(resourceType string, resourceID int) error {
+	switch resourceType {
+	case models.ResourceTypePosts:
+		if err := s.postRepo.Moderate(resourceID); err != nil {
+			return fmt.Errorf("failed to hide post: %w", err)
+		}
+	case models.ResourceTypeComments:
+		if err := s.commentRepo.Moderate(resourceID); err != nil {
+			return fmt.Errorf("failed to hide comment: %w", err)
+		}
+	case models.ResourceTypePhotos:
+		if err := s.albumRepo.DeletePhoto(resourceID); err != nil {
+			return fmt.Errorf("failed to remove photo: %w", err)
+		}
+		if err := s.resourceRegistry.DeleteInteractions(models.ResourceTypePhotos, resourceID); err != nil {
+			log.Printf("WARN: Failed to delete reactions and comments for photo %d: %v", resourceID, err)
+		}
+	default:
+		return fmt.Errorf("%w: profiles cannot be hidden", ErrInvalidModerationAction)
+	}
+	return nil
+}
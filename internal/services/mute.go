@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// MuteService provides feed muting, snoozing and post hiding functionality
+type MuteService struct {
+	BaseService
+	muteRepo          *repositories.MuteRepository
+	userRepo          *repositories.UserRepository
+	visibilityService *VisibilityService
+}
+
+// NewMuteService creates a new MuteService
+func NewMuteService(muteRepo *repositories.MuteRepository, userRepo *repositories.UserRepository,
+	visibilityService *VisibilityService) *MuteService {
+	return &MuteService{
+		muteRepo:          muteRepo,
+		userRepo:          userRepo,
+		visibilityService: visibilityService,
+	}
+}
+
+// MuteUser leaves a user's posts out of the feed until they are unmuted
+func (s *MuteService) MuteUser(userID, mutedUserID int) (*models.FeedMute, error) {
+	return s.mute(userID, mutedUserID, nil)
+}
+
+// SnoozeUser leaves a user's posts out of the feed for SnoozeDuration
+func (s *MuteService) SnoozeUser(userID, mutedUserID int) (*models.FeedMute, error) {
+	expiresAt := time.Now().Add(models.SnoozeDuration)
+	return s.mute(userID, mutedUserID, &expiresAt)
+}
+
+// UnmuteUser ends a mute or snooze
+func (s *MuteService) UnmuteUser(userID, mutedUserID int) error {
+	if err := s.muteRepo.Unmute(userID, mutedUserID); err != nil {
+		return fmt.Errorf("failed to unmute user: %w", err)
+	}
+	return nil
+}
+
+// GetMutedUsers retrieves the users whose posts are currently left out of a user's feed
+func (s *MuteService) GetMutedUsers(userID int) ([]*models.FeedMute, error) {
+	mutes, err := s.muteRepo.GetMutes(userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get muted users: %w", err)
+	}
+	return mutes, nil
+}
+
+// HidePost leaves a post the user can see out of their feed
+func (s *MuteService) HidePost(userID, postID int) error {
+	canView, err := s.visibilityService.CanViewResource(models.ResourceTypePosts, postID, userID)
+	if err != nil {
+		return fmt.Errorf("post not found: %w", err)
+	}
+	if !canView {
+		return ErrNotVisible
+	}
+
+	if err := s.muteRepo.HidePost(userID, postID); err != nil {
+		return fmt.Errorf("failed to hide post: %w", err)
+	}
+	return nil
+}
+
+// UnhidePost shows a hidden post in the user's feed again
+func (s *MuteService) UnhidePost(userID, postID int) error {
+	if err := s.muteRepo.UnhidePost(userID, postID); err != nil {
+		return fmt.Errorf("failed to unhide post: %w", err)
+	}
+	return nil
+}
+
+// mute mutes a user until expiresAt, or for good if it is nil
+func (s *MuteService) mute(userID, mutedUserID int, expiresAt *time.Time) (*models.FeedMute, error) {
+	if userID == mutedUserID {
+		return nil, fmt.Errorf("users cannot mute themselves")
+	}
+
+	user, err := s.userRepo.GetByID(mutedUserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := s.muteRepo.Mute(userID, mutedUserID, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to mute user: %w", err)
+	}
+
+	return &models.FeedMute{
+		User:      user.Public(),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
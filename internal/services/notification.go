@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/realtime"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// NotificationService provides notification-related functionality
+type NotificationService struct {
+	BaseService
+	notificationRepo  *repositories.NotificationRepository
+	visibilityService *VisibilityService
+	publisher         realtime.Publisher
+}
+
+// NewNotificationService creates a new NotificationService
+func NewNotificationService(notificationRepo *repositories.NotificationRepository, visibilityService *VisibilityService,
+	publisher realtime.Publisher) *NotificationService {
+	return &NotificationService{
+		notificationRepo:  notificationRepo,
+		visibilityService: visibilityService,
+		publisher:         publisher,
+	}
+}
+
+// Notify notifies recipientID that actorID did something of the given type to a resource.
+// Users are never notified of their own actions, nor of types they turned off.
+func (s *NotificationService) Notify(recipientID, actorID int, notificationType, resourceType string, resourceID int) error {
+	if recipientID == actorID {
+		return nil
+	}
+
+	enabled, err := s.notificationRepo.IsEnabled(recipientID, notificationType)
+	if err != nil {
+		return fmt.Errorf("failed to get notification preference: %w", err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	if err := s.notificationRepo.Upsert(recipientID, actorID, notificationType, resourceType, resourceID); err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	publishEvent(s.publisher, realtime.UserTopic(recipientID), realtime.EventNotification, map[string]interface{}{
+		"type":          notificationType,
+		"resource_type": resourceType,
+		"resource_id":   resourceID,
+		"actor_id":      actorID,
+	})
+
+	return nil
+}
+
+// NotifyResourceOwner notifies the owner of a post, photo or album that actorID did something to it
+func (s *NotificationService) NotifyResourceOwner(actorID int, notificationType, resourceType string, resourceID int) error {
+	ownerID, err := s.visibilityService.ResourceOwner(resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to get resource owner: %w", err)
+	}
+	return s.Notify(ownerID, actorID, notificationType, resourceType, resourceID)
+}
+
+// NotifyMention notifies a user that actorID mentioned them in a post or comment
+func (s *NotificationService) NotifyMention(recipientID, actorID int, resourceType string, resourceID int) error {
+	return s.Notify(recipientID, actorID, models.NotificationTypeMention, resourceType, resourceID)
+}
+
+// GetNotifications retrieves a page of a user's notifications along with their unread count
+func (s *NotificationService) GetNotifications(userID int, unreadOnly bool, limit, offset int) (*models.NotificationList, error) {
+	notifications, err := s.notificationRepo.GetForUser(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	unreadCount, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	for _, notification := range notifications {
+		notification.Message = notificationMessage(notification)
+	}
+
+	return &models.NotificationList{Notifications: notifications, UnreadCount: unreadCount}, nil
+}
+
+// GetUnreadCount counts a user's unread notifications
+func (s *NotificationService) GetUnreadCount(userID int) (int, error) {
+	count, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read
+func (s *NotificationService) MarkRead(notificationID, userID int) error {
+	if err := s.notificationRepo.MarkRead(notificationID, userID); err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks all of a user's notifications as read
+func (s *NotificationService) MarkAllRead(userID int) error {
+	if err := s.notificationRepo.MarkAllRead(userID); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences retrieves a user's notification preferences for every type
+func (s *NotificationService) GetPreferences(userID int) (map[string]bool, error) {
+	stored, err := s.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	preferences := map[string]bool{}
+	for _, notificationType := range models.NotificationTypes {
+		enabled, ok := stored[notificationType]
+		preferences[notificationType] = !ok || enabled
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences turns notification types on or off for a user. Types not
+// included are left unchanged.
+func (s *NotificationService) UpdatePreferences(userID int, preferences map[string]bool) (map[string]bool, error) {
+	for notificationType := range preferences {
+		if !models.IsValidNotificationType(notificationType) {
+			return nil, fmt.Errorf("invalid notification type: %q", notificationType)
+		}
+	}
+
+	if err := s.notificationRepo.UpsertPreferences(userID, preferences); err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+
+	return s.GetPreferences(userID)
+}
+
+// notificationMessage renders the text shown for a notification, e.g. "Ana and 12 others liked your post"
+func notificationMessage(notification *models.Notification) string {
+	actors := "Someone"
+	if notification.LastActor != nil {
+		actors = notification.LastActor.Name
+	}
+	switch others := notification.ActorCount - 1; {
+	case others == 1:
+		actors += " and 1 other"
+	case others > 1:
+		actors += fmt.Sprintf(" and %d others", others)
+	}
+
+	resource := strings.TrimSuffix(notification.ResourceType, "s")
+
+	switch notification.Type {
+	case models.NotificationTypeLike:
+		return fmt.Sprintf("%s liked your %s", actors, resource)
+	case models.NotificationTypeReaction:
+		return fmt.Sprintf("%s reacted to your %s", actors, resource)
+	case models.NotificationTypeComment:
+		return fmt.Sprintf("%s commented on your %s", actors, resource)
+	case models.NotificationTypeReply:
+		return fmt.Sprintf("%s replied to your %s", actors, resource)
+	case models.NotificationTypeShare:
+		return fmt.Sprintf("%s shared your %s", actors, resource)
+	case models.NotificationTypeMention:
+		return fmt.Sprintf("%s mentioned you in a %s", actors, resource)
+	case models.NotificationTypeFriendRequest:
+		return fmt.Sprintf("%s sent you a friend request", actors)
+	case models.NotificationTypeFriendAccept:
+		return fmt.Sprintf("%s accepted your friend request", actors)
+	default:
+		return actors
+	}
+}
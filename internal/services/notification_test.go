@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+func TestNotificationMessage(t *testing.T) {
+	ana := &models.UserPublic{ID: 1, Name: "Ana"}
+
+	tests := []struct {
+		notification *models.Notification
+		expected     string
+	}{
+		{&models.Notification{Type: models.NotificationTypeLike, ResourceType: "posts", ActorCount: 1, LastActor: ana}, "Ana liked your post"},
+		{&models.Notification{Type: models.NotificationTypeLike, ResourceType: "posts", ActorCount: 2, LastActor: ana}, "Ana and 1 other liked your post"},
+		{&models.Notification{Type: models.NotificationTypeLike, ResourceType: "photos", ActorCount: 13, LastActor: ana}, "Ana and 12 others liked your photo"},
+		{&models.Notification{Type: models.NotificationTypeReaction, ResourceType: "comments", ActorCount: 1, LastActor: ana}, "Ana reacted to your comment"},
+		{&models.Notification{Type: models.NotificationTypeComment, ResourceType: "albums", ActorCount: 1, LastActor: ana}, "Ana commented on your album"},
+		{&models.Notification{Type: models.NotificationTypeReply, ResourceType: "comments", ActorCount: 3, LastActor: ana}, "Ana and 2 others replied to your comment"},
+		{&models.Notification{Type: models.NotificationTypeMention, ResourceType: "comments", ActorCount: 1, LastActor: ana}, "Ana mentioned you in a comment"},
+		{&models.Notification{Type: models.NotificationTypeShare, ResourceType: "posts", ActorCount: 2, LastActor: ana}, "Ana and 1 other shared your post"},
+		{&models.Notification{Type: models.NotificationTypeFriendRequest, ResourceType: "friend_requests", ActorCount: 1}, "Someone sent you a friend request"},
+		{&models.Notification{Type: models.NotificationTypeFriendAccept, ResourceType: "friend_requests", ActorCount: 1, LastActor: ana}, "Ana accepted your friend request"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, notificationMessage(tt.notification))
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// Limits on the options of a poll
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 10
+)
+
+// ErrInvalidPoll is returned when a new poll's options or settings are not valid
+var ErrInvalidPoll = errors.New("invalid poll")
+
+// ErrPollClosed is returned when voting in a poll that no longer accepts votes
+var ErrPollClosed = errors.New("the poll is closed")
+
+// ErrInvalidVote is returned when a vote names options that are not in the poll, or several options in a single-choice poll
+var ErrInvalidVote = errors.New("invalid poll options")
+
+// PollService provides poll-related functionality
+type PollService struct {
+	BaseService
+	pollRepo          *repositories.PollRepository
+	postRepo          *repositories.PostRepository
+	visibilityService *VisibilityService
+}
+
+// NewPollService creates a new PollService
+func NewPollService(pollRepo *repositories.PollRepository, postRepo *repositories.PostRepository,
+	visibilityService *VisibilityService) *PollService {
+	return &PollService{
+		pollRepo:          pollRepo,
+		postRepo:          postRepo,
+		visibilityService: visibilityService,
+	}
+}
+
+// ValidatePoll checks a new poll and normalizes its options and settings
+func (s *PollService) ValidatePoll(poll *models.Poll) error {
+	if len(poll.Options) < MinPollOptions || len(poll.Options) > MaxPollOptions {
+		return fmt.Errorf("%w: a poll needs between %d and %d options", ErrInvalidPoll, MinPollOptions, MaxPollOptions)
+	}
+
+	seen := map[string]bool{}
+	for _, option := range poll.Options {
+		option.Text = strings.TrimSpace(option.Text)
+		key := strings.ToLower(option.Text)
+		if key == "" {
+			return fmt.Errorf("%w: options cannot be empty", ErrInvalidPoll)
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: options must be different", ErrInvalidPoll)
+		}
+		seen[key] = true
+	}
+
+	switch poll.ResultsVisibility {
+	case "":
+		poll.ResultsVisibility = models.PollResultsAlways
+	case models.PollResultsAlways, models.PollResultsAfterVote, models.PollResultsAfterClose:
+	default:
+		return fmt.Errorf("%w: unknown results visibility %q", ErrInvalidPoll, poll.ResultsVisibility)
+	}
+
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now()) {
+		return fmt.Errorf("%w: the closing time must be in the future", ErrInvalidPoll)
+	}
+	if poll.ResultsVisibility == models.PollResultsAfterClose && poll.ClosesAt == nil {
+		return fmt.Errorf("%w: results shown after the poll closes need a closing time", ErrInvalidPoll)
+	}
+
+	return nil
+}
+
+// CreatePoll stores a poll validated with ValidatePoll for a post
+func (s *PollService) CreatePoll(postID int, poll *models.Poll) error {
+	poll.PostID = postID
+	if err := s.pollRepo.Create(poll); err != nil {
+		return fmt.Errorf("failed to create poll: %w", err)
+	}
+	return nil
+}
+
+// Vote replaces the user's votes in the poll of a post and returns the poll as the user sees it
+func (s *PollService) Vote(postID, userID int, optionIDs []int) (*models.Poll, error) {
+	post, poll, err := s.openPoll(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every option must belong to the poll, and single-choice polls take one option
+	pollOptions := map[int]bool{}
+	for _, option := range poll.Options {
+		pollOptions[option.ID] = true
+	}
+	chosen := []int{}
+	seen := map[int]bool{}
+	for _, optionID := range optionIDs {
+		if !pollOptions[optionID] {
+			return nil, ErrInvalidVote
+		}
+		if !seen[optionID] {
+			seen[optionID] = true
+			chosen = append(chosen, optionID)
+		}
+	}
+	if len(chosen) == 0 || (!poll.MultipleChoice && len(chosen) > 1) {
+		return nil, ErrInvalidVote
+	}
+
+	if err := s.pollRepo.ReplaceVotes(poll.ID, userID, chosen); err != nil {
+		return nil, fmt.Errorf("failed to vote: %w", err)
+	}
+
+	if err := s.fillResults(userID, []*models.Poll{poll}, map[int]int{poll.ID: post.UserID}); err != nil {
+		return nil, err
+	}
+
+	return poll, nil
+}
+
+// RetractVote removes the user's votes from the poll of a post and returns the poll as the user sees it
+func (s *PollService) RetractVote(postID, userID int) (*models.Poll, error) {
+	post, poll, err := s.openPoll(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.pollRepo.DeleteVotes(poll.ID, userID); err != nil {
+		return nil, fmt.Errorf("failed to retract vote: %w", err)
+	}
+
+	if err := s.fillResults(userID, []*models.Poll{poll}, map[int]int{poll.ID: post.UserID}); err != nil {
+		return nil, err
+	}
+
+	return poll, nil
+}
+
+// AttachPolls sets the poll of each post that has one, with the results viewerID may see
+func (s *PollService) AttachPolls(viewerID int, posts []*models.Post) error {
+	postIDs := make([]int, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	polls, err := s.pollRepo.GetPollsForPosts(postIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get polls: %w", err)
+	}
+
+	attached := []*models.Poll{}
+	ownerIDs := map[int]int{}
+	for _, post := range posts {
+		if poll, ok := polls[post.ID]; ok {
+			post.Poll = poll
+			attached = append(attached, poll)
+			ownerIDs[poll.ID] = post.UserID
+		}
+	}
+
+	return s.fillResults(viewerID, attached, ownerIDs)
+}
+
+// openPoll returns the poll of a post the user can see, as long as it still accepts votes
+func (s *PollService) openPoll(postID, userID int) (*models.Post, *models.Poll, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	canView, err := s.visibilityService.CanViewContent(post.UserID, userID, post.Privacy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canView {
+		return nil, nil, ErrNotVisible
+	}
+
+	poll, err := s.pollRepo.GetByPostID(postID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if poll.IsClosedAt(time.Now()) {
+		return nil, nil, ErrPollClosed
+	}
+
+	return post, poll, nil
+}
+
+// fillResults sets the fields of polls that depend on the viewer and the time. The author of
+// the post always sees the results; everyone else as the poll's results visibility allows.
+// ownerIDs maps poll IDs to the author of their post.
+func (s *PollService) fillResults(viewerID int, polls []*models.Poll, ownerIDs map[int]int) error {
+	if len(polls) == 0 {
+		return nil
+	}
+
+	pollIDs := make([]int, len(polls))
+	for i, poll := range polls {
+		pollIDs[i] = poll.ID
+	}
+
+	votes, err := s.pollRepo.GetUserVotes(pollIDs, viewerID)
+	if err != nil {
+		return fmt.Errorf("failed to get poll votes: %w", err)
+	}
+	voteCounts, err := s.pollRepo.GetVoteCounts(pollIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get poll results: %w", err)
+	}
+	voterCounts, err := s.pollRepo.GetVoterCounts(pollIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get poll results: %w", err)
+	}
+
+	now := time.Now()
+	for _, poll := range polls {
+		poll.Closed = poll.IsClosedAt(now)
+		poll.ViewerVotes = votes[poll.ID]
+		if poll.ViewerVotes == nil {
+			poll.ViewerVotes = []int{}
+		}
+
+		switch {
+		case ownerIDs[poll.ID] == viewerID, poll.ResultsVisibility == models.PollResultsAlways:
+			poll.ResultsVisible = true
+		case poll.ResultsVisibility == models.PollResultsAfterVote:
+			poll.ResultsVisible = poll.Closed || len(poll.ViewerVotes) > 0
+		default:
+			poll.ResultsVisible = poll.Closed
+		}
+
+		poll.VoterCount = nil
+		for _, option := range poll.Options {
+			option.VoteCount = nil
+		}
+		if !poll.ResultsVisible {
+			continue
+		}
+
+		voterCount := voterCounts[poll.ID]
+		poll.VoterCount = &voterCount
+		for _, option := range poll.Options {
+			voteCount := voteCounts[option.ID]
+			option.VoteCount = &voteCount
+		}
+	}
+
+	return nil
+}
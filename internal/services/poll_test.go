@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// newPoll creates a poll with options with the given texts
+func newPoll(texts ...string) *models.Poll {
+	poll := &models.Poll{}
+	for _, text := range texts {
+		poll.Options = append(poll.Options, &models.PollOption{Text: text})
+	}
+	return poll
+}
+
+func TestValidatePoll(t *testing.T) {
+	service := &PollService{}
+
+	poll := newPoll(" Yes ", "No")
+	assert.NoError(t, service.ValidatePoll(poll))
+	assert.Equal(t, "Yes", poll.Options[0].Text)
+	assert.Equal(t, models.PollResultsAlways, poll.ResultsVisibility)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	invalid := map[string]*models.Poll{
+		"one option":            newPoll("Yes"),
+		"too many options":      newPoll("1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"),
+		"empty option":          newPoll("Yes", "  "),
+		"duplicate options":     newPoll("Yes", "yes"),
+		"unknown visibility":    {Options: newPoll("Yes", "No").Options, ResultsVisibility: "never"},
+		"closing in the past":   {Options: newPoll("Yes", "No").Options, ClosesAt: &past},
+		"after close, no close": {Options: newPoll("Yes", "No").Options, ResultsVisibility: models.PollResultsAfterClose},
+	}
+	for name, poll := range invalid {
+		assert.ErrorIs(t, service.ValidatePoll(poll), ErrInvalidPoll, name)
+	}
+
+	closing := &models.Poll{Options: newPoll("Yes", "No").Options, ResultsVisibility: models.PollResultsAfterClose, ClosesAt: &future}
+	assert.NoError(t, service.ValidatePoll(closing))
+	assert.False(t, closing.IsClosedAt(time.Now()))
+	assert.True(t, closing.IsClosedAt(future))
+}
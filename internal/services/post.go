@@ -1,64 +1,433 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/gocli/social_api/internal/models"
 	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
 )
 
+// ErrHistoryNotAllowed is returned when a user may not see the edit history of a post or comment
+var ErrHistoryNotAllowed = errors.New("edit history is only available to the author and moderators")
+
+// ErrShareWidensAudience is returned when a share would be visible to more people than the shared post
+var ErrShareWidensAudience = errors.New("a share cannot have a wider audience than the shared post")
+
+// ErrEmptyPost is returned when a new post has neither content nor attachments
+var ErrEmptyPost = errors.New("a post needs content, an attachment or a poll")
+
+// ErrInvalidFeedMode is returned when the feed is requested in an unknown mode
+var ErrInvalidFeedMode = errors.New("feed mode must be latest or top")
+
+// ErrInvalidFeedFilter is returned when the feed is filtered by an unknown content type
+var ErrInvalidFeedFilter = errors.New("feed content type must be text, image, link, poll or share")
+
+// MaxPostImages is the most images a post can have
+const MaxPostImages = 10
+
+const (
+	// rankedFeedWindow is how far back the top feed looks for posts
+	rankedFeedWindow = 3 * 24 * time.Hour
+	// rankedFeedCandidates is the most posts the top feed ranks
+	rankedFeedCandidates = 500
+)
+
+// PostAttachments holds what a new post carries besides its content
+type PostAttachments struct {
+	ImageURLs []string     // Images already saved by the upload pipeline
+	LinkURL   string       // A page to show a preview of
+	Poll      *models.Poll // Only the options' text and the poll settings are used
+}
+
 // PostService provides post-related functionality
 type PostService struct {
 	BaseService
-	postRepo *repositories.PostRepository
+	postRepo            *repositories.PostRepository
+	tagRepo             *repositories.TagRepository
+	revisionRepo        *repositories.RevisionRepository
+	attachmentRepo      *repositories.AttachmentRepository
+	visibilityService   *VisibilityService
+	mentionService      *MentionService
+	notificationService *NotificationService
+	pollService         *PollService
+	linkPreviewService  *LinkPreviewService
+	resourceRegistry    *ResourceRegistry
+	roleService         *RoleService
+	ranker              Ranker
+	contentFilter       *ContentFilterService
 }
 
 // NewPostService creates a new PostService
-func NewPostService(postRepo *repositories.PostRepository) *PostService {
+func NewPostService(postRepo *repositories.PostRepository, tagRepo *repositories.TagRepository,
+	revisionRepo *repositories.RevisionRepository, attachmentRepo *repositories.AttachmentRepository,
+	visibilityService *VisibilityService, mentionService *MentionService, notificationService *NotificationService,
+	pollService *PollService, linkPreviewService *LinkPreviewService, resourceRegistry *ResourceRegistry,
+	roleService *RoleService, ranker Ranker, contentFilter *ContentFilterService) *PostService {
 	return &PostService{
-		postRepo: postRepo,
+		postRepo:            postRepo,
+		tagRepo:             tagRepo,
+		revisionRepo:        revisionRepo,
+		attachmentRepo:      attachmentRepo,
+		visibilityService:   visibilityService,
+		mentionService:      mentionService,
+		notificationService: notificationService,
+		pollService:         pollService,
+		linkPreviewService:  linkPreviewService,
+		resourceRegistry:    resourceRegistry,
+		roleService:         roleService,
+		ranker:              ranker,
+		contentFilter:       contentFilter,
+	}
+}
+
+// CreatePost creates a new post with optional images, a link preview and a poll.
+// A link whose page cannot be fetched is attached without a preview. The content filter may reject
+// the post with ErrContentRejected, or hold it so that only its author sees it until a moderator reviews it.
+func (s *PostService) CreatePost(userID int, content, privacy string, attachments PostAttachments) (*models.Post, error) {
+	return s.createPost(userID, content, privacy, attachments, nil)
+}
+
+// PublishDraft publishes a draft as a new post. A draft that was already published returns repositories.ErrConflict.
+func (s *PostService) PublishDraft(draft *models.PostDraft) (*models.Post, error) {
+	attachments := PostAttachments{
+		ImageURLs: draft.ImageURLs,
+		LinkURL:   draft.LinkURL,
+		Poll:      draft.Poll,
 	}
+	return s.createPost(draft.UserID, draft.Content, draft.Privacy, attachments, &draft.ID)
 }
 
-// CreatePost creates a new post
-func (s *PostService) CreatePost(userID int, content, privacy string) (*models.Post, error) {
+// ValidatePostContent checks that a post with the given content and attachments could be created.
+// The poll, if any, is normalized as by PollService.ValidatePoll.
+func (s *PostService) ValidatePostContent(content string, attachments PostAttachments) error {
+	if strings.TrimSpace(content) == "" && len(attachments.ImageURLs) == 0 && attachments.LinkURL == "" && attachments.Poll == nil {
+		return ErrEmptyPost
+	}
+	if len(attachments.ImageURLs) > MaxPostImages {
+		return fmt.Errorf("a post can have at most %d images", MaxPostImages)
+	}
+	if attachments.LinkURL != "" {
+		if _, err := ParseLinkURL(attachments.LinkURL); err != nil {
+			return err
+		}
+	}
+	if attachments.Poll != nil {
+		if err := s.pollService.ValidatePoll(attachments.Poll); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createPost creates a post, recording the draft it was published from if draftID is set
+func (s *PostService) createPost(userID int, content, privacy string, attachments PostAttachments, draftID *int) (*models.Post, error) {
+	if privacy == "" {
+		privacy = models.PrivacyPublic
+	}
+
+	if err := s.ValidatePostContent(content, attachments); err != nil {
+		return nil, err
+	}
+
+	verdict, err := s.contentFilter.Check(userID, models.ResourceTypePosts, content, attachments.LinkURL)
+	if err != nil {
+		return nil, err
+	}
+
+	postAttachments := []*models.PostAttachment{}
+	for _, imageURL := range attachments.ImageURLs {
+		postAttachments = append(postAttachments, &models.PostAttachment{Type: models.AttachmentTypeImage, URL: imageURL})
+	}
+	if attachments.LinkURL != "" {
+		postAttachments = append(postAttachments, s.fetchLink(attachments.LinkURL))
+	}
+
 	post := &models.Post{
-		UserID:  userID,
-		Content: content,
-		Privacy: privacy,
+		UserID:            userID,
+		Content:           content,
+		Privacy:           privacy,
+		CommentPermission: models.CommentPermissionEveryone,
+		DraftID:           draftID,
 	}
+	holdPost(post, verdict)
 
 	if err := s.postRepo.Create(post); err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
+	// Remove the post again if its attachments cannot be saved, so it is never published half-finished
+	if err := s.saveAttachments(post, postAttachments, attachments.Poll); err != nil {
+		if deleteErr := s.postRepo.Delete(post.ID); deleteErr != nil {
+			log.Printf("WARN: Failed to delete post %d after its attachments failed: %v", post.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	s.saveTags(post)
+	s.saveMentions(post)
+	if post.HeldAt != nil {
+		s.contentFilter.ReportHeld(models.ResourceTypePosts, post.ID, userID, verdict)
+	}
+
+	if err := s.attachPostDetails(userID, []*models.Post{post}); err != nil {
+		return nil, err
+	}
+
 	return post, nil
 }
 
-// GetPostByID retrieves a post by ID
-func (s *PostService) GetPostByID(id int) (*models.Post, error) {
+// SharePost shares a post the user can see to their own timeline with optional commentary.
+// Sharing a share shares the original post, and the share cannot reach a wider audience than the original.
+func (s *PostService) SharePost(userID, postID int, content, privacy string) (*models.Post, error) {
+	original, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if original.SharedPostID != nil {
+		original, err = s.postRepo.GetByID(*original.SharedPostID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shared post: %w", err)
+		}
+	}
+
+	canView, err := s.visibilityService.CanViewContent(original.UserID, userID, original.Privacy)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrNotVisible
+	}
+
+	if privacy == "" {
+		privacy = original.Privacy
+	}
+	if models.IsWiderPrivacy(privacy, original.Privacy) {
+		return nil, ErrShareWidensAudience
+	}
+
+	verdict, err := s.contentFilter.Check(userID, models.ResourceTypePosts, content)
+	if err != nil {
+		return nil, err
+	}
+
+	post := &models.Post{
+		UserID:            userID,
+		Content:           content,
+		Privacy:           privacy,
+		CommentPermission: models.CommentPermissionEveryone,
+		SharedPostID:      &original.ID,
+	}
+	holdPost(post, verdict)
+
+	if err := s.postRepo.Create(post); err != nil {
+		return nil, fmt.Errorf("failed to share post: %w", err)
+	}
+
+	s.saveTags(post)
+	s.saveMentions(post)
+	if post.HeldAt != nil {
+		s.contentFilter.ReportHeld(models.ResourceTypePosts, post.ID, userID, verdict)
+	}
+
+	if s.isAnnounced(post) {
+		if err := s.notificationService.NotifyResourceOwner(userID, models.NotificationTypeShare, models.ResourceTypePosts, original.ID); err != nil {
+			log.Printf("WARN: Failed to notify owner of post %d about share: %v", original.ID, err)
+		}
+	}
+
+	if err := s.attachPostDetails(userID, []*models.Post{post}); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// GetPostByID retrieves a post by ID. Posts viewerID cannot see, including posts hidden by a moderator,
+// posts held for review and posts by restricted users, return ErrNotVisible. Shared posts are embedded
+// if viewerID can see them.
+func (s *PostService) GetPostByID(id, viewerID int) (*models.Post, error) {
 	post, err := s.postRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
+
+	if post.HeldAt != nil && post.UserID != viewerID {
+		return nil, ErrNotVisible
+	}
+
+	canView, err := s.visibilityService.CanViewContent(post.UserID, viewerID, post.Privacy)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrNotVisible
+	}
+
+	post.Tags, err = s.tagRepo.GetTagsForPost(post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post tags: %w", err)
+	}
+
+	mentions, err := s.mentionService.GetMentions(models.ResourceTypePosts, []int{post.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post mentions: %w", err)
+	}
+	post.Mentions = mentions[post.ID]
+
+	if err := s.attachPostDetails(viewerID, []*models.Post{post}); err != nil {
+		return nil, err
+	}
+
 	return post, nil
 }
 
-// GetPostsByUserID retrieves posts for a specific user
-func (s *PostService) GetPostsByUserID(userID int, limit, offset int) ([]*models.Post, error) {
-	posts, err := s.postRepo.GetPostsByUserID(userID, limit, offset)
+// GetVisiblePostsByIDs retrieves the given posts that viewerID can see, keyed by post ID.
+// Posts that were deleted or that the viewer cannot see are left out.
+func (s *PostService) GetVisiblePostsByIDs(ids []int, viewerID int) (map[int]*models.PostWithUser, error) {
+	posts, err := s.postRepo.GetPostsWithUserByIDs(ids)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts: %w", err)
 	}
+
+	visible := []*models.Post{}
+	for id, post := range posts {
+		canView, err := s.visibilityService.CanViewContent(post.UserID, viewerID, post.Privacy)
+		if err != nil {
+			return nil, err
+		}
+		if !canView {
+			delete(posts, id)
+			continue
+		}
+		visible = append(visible, &post.Post)
+	}
+
+	postIDs := make([]int, len(visible))
+	for i, post := range visible {
+		postIDs[i] = post.ID
+	}
+	mentions, err := s.mentionService.GetMentions(models.ResourceTypePosts, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post mentions: %w", err)
+	}
+	for _, post := range visible {
+		post.Mentions = mentions[post.ID]
+	}
+
+	if err := s.attachPostDetails(viewerID, visible); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetPostsByUserID retrieves posts for a specific user. Shared posts are embedded if viewerID can see them.
+// A restricted user's posts are only listed for themselves.
+func (s *PostService) GetPostsByUserID(userID, viewerID int, limit, offset int) ([]*models.Post, error) {
+	hidden, err := s.visibilityService.IsRestrictedFor(userID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return []*models.Post{}, nil
+	}
+
+	posts, err := s.postRepo.GetPostsByUserID(userID, viewerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	postIDs := make([]int, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	mentions, err := s.mentionService.GetMentions(models.ResourceTypePosts, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post mentions: %w", err)
+	}
+	for _, post := range posts {
+		post.Mentions = mentions[post.ID]
+	}
+
+	if err := s.attachPostDetails(viewerID, posts); err != nil {
+		return nil, err
+	}
+
 	return posts, nil
 }
 
-// GetFeed retrieves posts for a user's feed
-func (s *PostService) GetFeed(userID int, limit, offset int) ([]*models.PostWithUser, error) {
-	posts, err := s.postRepo.GetFeed(userID, limit, offset)
+// GetFeed retrieves posts for a user's feed, newest first or ordered by the ranker
+func (s *PostService) GetFeed(userID int, mode string, filter models.FeedFilter, limit, offset int) ([]*models.PostWithUser, error) {
+	switch filter.ContentType {
+	case "", models.PostContentText, models.PostContentImage, models.PostContentLink, models.PostContentPoll, models.PostContentShare:
+	default:
+		return nil, ErrInvalidFeedFilter
+	}
+
+	var posts []*models.PostWithUser
+	var err error
+	switch mode {
+	case models.FeedModeLatest:
+		posts, err = s.postRepo.GetFeed(userID, filter, limit, offset)
+	case models.FeedModeTop:
+		posts, err = s.getRankedFeed(userID, filter, limit, offset)
+	default:
+		return nil, ErrInvalidFeedMode
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed: %w", err)
 	}
+
+	postIDs := make([]int, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	mentions, err := s.mentionService.GetMentions(models.ResourceTypePosts, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post mentions: %w", err)
+	}
+	feedPosts := make([]*models.Post, len(posts))
+	for i, post := range posts {
+		post.Mentions = mentions[post.ID]
+		feedPosts[i] = &post.Post
+	}
+
+	if err := s.attachPostDetails(userID, feedPosts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// getRankedFeed ranks the recent posts of a user's feed and returns the requested page
+func (s *PostService) getRankedFeed(userID int, filter models.FeedFilter, limit, offset int) ([]*models.PostWithUser, error) {
+	now := time.Now()
+	candidates, err := s.postRepo.GetFeedCandidates(userID, filter, now.Add(-rankedFeedWindow), rankedFeedCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ranker.Rank(userID, candidates, now)
+
+	posts := []*models.PostWithUser{}
+	if offset < 0 || offset >= len(candidates) {
+		return posts, nil
+	}
+	end := offset + limit
+	if limit < 0 || end > len(candidates) {
+		end = len(candidates)
+	}
+	for _, candidate := range candidates[offset:end] {
+		posts = append(posts, candidate.Post)
+	}
+
 	return posts, nil
 }
 
@@ -75,14 +444,74 @@ func (s *PostService) UpdatePost(postID, userID int, content, privacy string) (*
 		return nil, fmt.Errorf("user is not authorized to update this post")
 	}
 
+	// A post hidden by a moderator stays visible to its author only, as does a post awaiting review
+	if post.ModeratedAt != nil && privacy != "" && privacy != post.Privacy {
+		return nil, ErrContentModerated
+	}
+	if post.HeldAt != nil && privacy != "" && privacy != post.Privacy {
+		return nil, ErrContentHeld
+	}
+
+	// Check edited content again
+	verdict := ContentVerdict{Outcome: ContentAllow}
+	if content != post.Content {
+		verdict, err = s.contentFilter.Check(userID, models.ResourceTypePosts, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Update the post
 	post.Content = content
-	post.Privacy = privacy
+	if privacy != "" {
+		post.Privacy = privacy
+	}
 
-	if err := s.postRepo.Update(post); err != nil {
+	// A share cannot be widened beyond the post it shares, as long as that post exists
+	if post.SharedPostID != nil {
+		original, err := s.postRepo.GetByID(*post.SharedPostID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get shared post: %w", err)
+		}
+		if original != nil && models.IsWiderPrivacy(post.Privacy, original.Privacy) {
+			return nil, ErrShareWidensAudience
+		}
+	}
+
+	if err := s.postRepo.Update(post, userID); err != nil {
 		return nil, fmt.Errorf("failed to update post: %w", err)
 	}
 
+	if verdict.Outcome == ContentHold && post.HeldAt == nil && post.ModeratedAt == nil {
+		if err := s.postRepo.Hold(post.ID); err != nil {
+			return nil, err
+		}
+		holdPost(post, verdict)
+		s.contentFilter.ReportHeld(models.ResourceTypePosts, post.ID, userID, verdict)
+	}
+
+	s.saveTags(post)
+	s.saveMentions(post)
+
+	return post, nil
+}
+
+// UpdateCommentPermission sets who may comment on a post: everyone, friends or nobody
+func (s *PostService) UpdateCommentPermission(postID, userID int, permission string) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	if post.UserID != userID {
+		return nil, fmt.Errorf("user is not authorized to update this post")
+	}
+
+	if err := s.postRepo.SetCommentPermission(postID, permission); err != nil {
+		return nil, fmt.Errorf("failed to update comment permission: %w", err)
+	}
+
+	post.CommentPermission = permission
 	return post, nil
 }
 
@@ -99,10 +528,198 @@ func (s *PostService) DeletePost(postID, userID int) error {
 		return fmt.Errorf("user is not authorized to delete this post")
 	}
 
-	// Delete the post
+	return s.RemovePost(postID)
+}
+
+// RemovePost deletes a post along with its mentions, revisions, reactions and comments.
+// It does not check who is asking; admins remove posts through it.
+func (s *PostService) RemovePost(postID int) error {
 	if err := s.postRepo.Delete(postID); err != nil {
 		return fmt.Errorf("failed to delete post: %w", err)
 	}
 
+	if err := s.mentionService.DeleteMentions(models.ResourceTypePosts, postID); err != nil {
+		log.Printf("WARN: Failed to delete mentions for post %d: %v", postID, err)
+	}
+	if err := s.revisionRepo.DeleteRevisions(models.ResourceTypePosts, postID); err != nil {
+		log.Printf("WARN: Failed to delete revisions for post %d: %v", postID, err)
+	}
+	if err := s.resourceRegistry.DeleteInteractions(models.ResourceTypePosts, postID); err != nil {
+		log.Printf("WARN: Failed to delete reactions and comments for post %d: %v", postID, err)
+	}
+
+	return nil
+}
+
+// GetPostRevisions retrieves the previous versions of a post's content.
+// Only the author and the site's moderators may see them.
+func (s *PostService) GetPostRevisions(postID, userID int) ([]*models.ContentRevision, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	if post.UserID != userID {
+		siteModerator, err := s.roleService.HasPermission(userID, models.PermissionModerateContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check user role: %w", err)
+		}
+		if !siteModerator {
+			return nil, ErrHistoryNotAllowed
+		}
+	}
+
+	revisions, err := s.revisionRepo.GetRevisions(models.ResourceTypePosts, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// fetchLink builds the link attachment of a new post. A page that cannot be fetched leaves the link without a preview.
+func (s *PostService) fetchLink(linkURL string) *models.PostAttachment {
+	link, err := s.linkPreviewService.Fetch(linkURL)
+	if err != nil {
+		log.Printf("WARN: Failed to fetch link preview for %s: %v", linkURL, err)
+		return &models.PostAttachment{Type: models.AttachmentTypeLink, URL: linkURL}
+	}
+
+	return link
+}
+
+// saveAttachments stores the attachments and the poll of a new post
+func (s *PostService) saveAttachments(post *models.Post, attachments []*models.PostAttachment, poll *models.Poll) error {
+	if len(attachments) > 0 {
+		if err := s.attachmentRepo.CreateAttachments(post.ID, attachments); err != nil {
+			return fmt.Errorf("failed to save attachments: %w", err)
+		}
+	}
+
+	if poll != nil {
+		if err := s.pollService.CreatePoll(post.ID, poll); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// attachPostDetails fills in the shares, attachments and polls of posts as viewerID sees them,
+// including those of embedded shared posts
+func (s *PostService) attachPostDetails(viewerID int, posts []*models.Post) error {
+	if err := s.attachShares(viewerID, posts); err != nil {
+		return err
+	}
+
+	withShared := append([]*models.Post{}, posts...)
+	for _, post := range posts {
+		if post.SharedPost != nil {
+			withShared = append(withShared, &post.SharedPost.Post)
+		}
+	}
+
+	postIDs := make([]int, len(withShared))
+	for i, post := range withShared {
+		postIDs[i] = post.ID
+	}
+	attachments, err := s.attachmentRepo.GetAttachmentsForPosts(postIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get post attachments: %w", err)
+	}
+	for _, post := range withShared {
+		post.Attachments = attachments[post.ID]
+	}
+
+	return s.pollService.AttachPolls(viewerID, withShared)
+}
+
+// attachShares fills in the share count of each post and embeds the posts that shares point at.
+// A shared post that was deleted or that viewerID can no longer see is marked unavailable.
+func (s *PostService) attachShares(viewerID int, posts []*models.Post) error {
+	postIDs := make([]int, len(posts))
+	sharedIDs := []int{}
+	for i, post := range posts {
+		postIDs[i] = post.ID
+		if post.SharedPostID != nil {
+			sharedIDs = append(sharedIDs, *post.SharedPostID)
+		}
+	}
+
+	counts, err := s.postRepo.GetShareCounts(postIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get share counts: %w", err)
+	}
+	sharedPosts, err := s.postRepo.GetPostsWithUserByIDs(sharedIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get shared posts: %w", err)
+	}
+
+	for _, post := range posts {
+		post.ShareCount = counts[post.ID]
+		if post.SharedPostID == nil {
+			continue
+		}
+
+		shared, ok := sharedPosts[*post.SharedPostID]
+		if !ok {
+			post.SharedPostUnavailable = true
+			continue
+		}
+		canView, err := s.visibilityService.CanViewContent(shared.UserID, viewerID, shared.Privacy)
+		if err != nil {
+			return err
+		}
+		if !canView {
+			post.SharedPostUnavailable = true
+			continue
+		}
+		post.SharedPost = shared
+	}
+
+	return nil
+}
+
+// holdPost marks a post the content filter held as visible to its author only
+func holdPost(post *models.Post, verdict ContentVerdict) {
+	if verdict.Outcome != ContentHold {
+		return
+	}
+	now := time.Now()
+	post.HeldAt = &now
+	post.HeldPrivacy = post.Privacy
+	post.Privacy = models.PrivacyOnlyMe
+}
+
+// isAnnounced reports whether other users may be notified about a post. Held posts and posts by
+// restricted users are visible to their author only, so nobody is told about them.
+func (s *PostService) isAnnounced(post *models.Post) bool {
+	if post.HeldAt != nil {
+		return false
+	}
+	restricted, err := s.visibilityService.IsRestricted(post.UserID)
+	if err != nil {
+		log.Printf("WARN: Failed to check restriction of user %d: %v", post.UserID, err)
+		return false
+	}
+	return !restricted
+}
+
+// saveTags extracts the hashtags from a post's content and stores them.
+func (s *PostService) saveTags(post *models.Post) {
+	post.Tags = utils.ExtractHashtags(post.Content)
+	if err := s.tagRepo.SetPostTags(post.ID, post.Tags); err != nil {
+		log.Printf("WARN: Failed to save tags for post %d: %v", post.ID, err)
+	}
+}
+
+// saveMentions stores the @mentions in a post's content and notifies the mentioned users if the post is announced.
+func (s *PostService) saveMentions(post *models.Post) {
+	mentions, err := s.mentionService.SaveMentions(models.ResourceTypePosts, post.ID, post.UserID, post.Content,
+		models.ResourceTypePosts, post.ID, s.isAnnounced(post))
+	if err != nil {
+		log.Printf("WARN: Failed to save mentions for post %d: %v", post.ID, err)
+		return
+	}
+	post.Mentions = mentions
+}
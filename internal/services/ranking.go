@@ -0,0 +1,147 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// Ranker orders the candidate posts of a ranked feed. Strategies can be swapped, or split between
+// viewers with a SplitRanker, to compare them.
+type Ranker interface {
+	// Name identifies the strategy
+	Name() string
+	// Rank sets the score of each candidate and sorts the candidates best first
+	Rank(viewerID int, candidates []*models.FeedCandidate, now time.Time)
+}
+
+// RankingWeights configures a WeightedRanker
+type RankingWeights struct {
+	RecencyHalfLife time.Duration      // A post's score halves every time it ages this much
+	Reaction        float64            // Weight of each reaction on the post
+	Comment         float64            // Weight of each comment on the post
+	Affinity        float64            // Weight of the viewer's interactions with the author
+	ContentTypes    map[string]float64 // Multiplier per content type; types not listed count as 1
+}
+
+// DefaultRankingWeights returns the weights used by the top feed
+func DefaultRankingWeights() RankingWeights {
+	return RankingWeights{
+		RecencyHalfLife: 6 * time.Hour,
+		Reaction:        1,
+		Comment:         2,
+		Affinity:        1.5,
+		ContentTypes: map[string]float64{
+			models.PostContentImage: 1.2,
+			models.PostContentPoll:  1.1,
+			models.PostContentShare: 0.9,
+		},
+	}
+}
+
+// WeightedRanker scores posts by engagement, the viewer's affinity with the author and content type,
+// decayed by the age of the post
+type WeightedRanker struct {
+	weights RankingWeights
+}
+
+// NewWeightedRanker creates a new WeightedRanker
+func NewWeightedRanker(weights RankingWeights) *WeightedRanker {
+	return &WeightedRanker{weights: weights}
+}
+
+// Name identifies the strategy
+func (r *WeightedRanker) Name() string {
+	return "weighted"
+}
+
+// Rank sets the score of each candidate and sorts the candidates best first
+func (r *WeightedRanker) Rank(viewerID int, candidates []*models.FeedCandidate, now time.Time) {
+	for _, candidate := range candidates {
+		candidate.Score = r.score(candidate, now)
+	}
+	sortCandidates(candidates)
+}
+
+// score scores a single candidate. Engagement and affinity are dampened logarithmically so that
+// a handful of very popular posts do not crowd out everything else.
+func (r *WeightedRanker) score(candidate *models.FeedCandidate, now time.Time) float64 {
+	engagement := r.weights.Reaction*float64(candidate.ReactionCount) + r.weights.Comment*float64(candidate.CommentCount)
+	score := 1 + math.Log1p(engagement) + r.weights.Affinity*math.Log1p(float64(candidate.Affinity))
+
+	if multiplier, ok := r.weights.ContentTypes[candidate.ContentType]; ok {
+		score *= multiplier
+	}
+
+	age := now.Sub(candidate.Post.CreatedAt)
+	if age > 0 && r.weights.RecencyHalfLife > 0 {
+		score *= math.Exp2(-age.Hours() / r.weights.RecencyHalfLife.Hours())
+	}
+
+	return score
+}
+
+// ChronologicalRanker orders posts newest first, as a baseline to compare other strategies against
+type ChronologicalRanker struct{}
+
+// Name identifies the strategy
+func (ChronologicalRanker) Name() string {
+	return "chronological"
+}
+
+// Rank sets the score of each candidate and sorts the candidates best first
+func (ChronologicalRanker) Rank(viewerID int, candidates []*models.FeedCandidate, now time.Time) {
+	for _, candidate := range candidates {
+		candidate.Score = float64(candidate.Post.CreatedAt.Unix())
+	}
+	sortCandidates(candidates)
+}
+
+// SplitRanker assigns each viewer to one of several strategies, so that a viewer always
+// sees the same one
+type SplitRanker struct {
+	variants []Ranker
+}
+
+// NewSplitRanker creates a new SplitRanker over the given strategies
+func NewSplitRanker(variants ...Ranker) *SplitRanker {
+	return &SplitRanker{variants: variants}
+}
+
+// Name identifies the strategy
+func (r *SplitRanker) Name() string {
+	return "split"
+}
+
+// RankerFor returns the strategy the viewer is assigned to
+func (r *SplitRanker) RankerFor(viewerID int) Ranker {
+	if len(r.variants) == 0 {
+		return ChronologicalRanker{}
+	}
+	index := viewerID % len(r.variants)
+	if index < 0 {
+		index += len(r.variants)
+	}
+	return r.variants[index]
+}
+
+// Rank ranks the candidates with the viewer's strategy
+func (r *SplitRanker) Rank(viewerID int, candidates []*models.FeedCandidate, now time.Time) {
+	r.RankerFor(viewerID).Rank(viewerID, candidates, now)
+}
+
+// sortCandidates sorts candidates by score, breaking ties with the newest post first
+func sortCandidates(candidates []*models.FeedCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Post.CreatedAt.Equal(b.Post.CreatedAt) {
+			return a.Post.CreatedAt.After(b.Post.CreatedAt)
+		}
+		return a.Post.ID > b.Post.ID
+	})
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+// newCandidate creates a feed candidate for a text post created at the given time
+func newCandidate(id int, createdAt time.Time) *models.FeedCandidate {
+	post := &models.PostWithUser{}
+	post.ID = id
+	post.CreatedAt = createdAt
+	return &models.FeedCandidate{Post: post, ContentType: models.PostContentText}
+}
+
+// candidateIDs returns the post IDs of the candidates in order
+func candidateIDs(candidates []*models.FeedCandidate) []int {
+	ids := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.Post.ID
+	}
+	return ids
+}
+
+func TestWeightedRanker(t *testing.T) {
+	now := time.Now()
+	ranker := NewWeightedRanker(DefaultRankingWeights())
+
+	// Without other signals, newer posts rank first
+	candidates := []*models.FeedCandidate{newCandidate(1, now.Add(-3*time.Hour)), newCandidate(2, now.Add(-time.Hour))}
+	ranker.Rank(1, candidates, now)
+	assert.Equal(t, []int{2, 1}, candidateIDs(candidates))
+
+	// Engagement outweighs a small age difference
+	popular := newCandidate(3, now.Add(-2*time.Hour))
+	popular.ReactionCount = 20
+	popular.CommentCount = 5
+	quiet := newCandidate(4, now.Add(-time.Hour))
+	candidates = []*models.FeedCandidate{quiet, popular}
+	ranker.Rank(1, candidates, now)
+	assert.Equal(t, []int{3, 4}, candidateIDs(candidates))
+
+	// The viewer's affinity with the author counts
+	friend := newCandidate(5, now.Add(-time.Hour))
+	friend.Affinity = 10
+	stranger := newCandidate(6, now.Add(-time.Hour))
+	candidates = []*models.FeedCandidate{stranger, friend}
+	ranker.Rank(1, candidates, now)
+	assert.Equal(t, []int{5, 6}, candidateIDs(candidates))
+
+	// Content types are weighted
+	image := newCandidate(7, now.Add(-time.Hour))
+	image.ContentType = models.PostContentImage
+	text := newCandidate(8, now.Add(-time.Hour))
+	candidates = []*models.FeedCandidate{text, image}
+	ranker.Rank(1, candidates, now)
+	assert.Equal(t, []int{7, 8}, candidateIDs(candidates))
+
+	// Old posts decay even with engagement
+	stale := newCandidate(9, now.Add(-72*time.Hour))
+	stale.ReactionCount = 50
+	fresh := newCandidate(10, now)
+	candidates = []*models.FeedCandidate{stale, fresh}
+	ranker.Rank(1, candidates, now)
+	assert.Equal(t, []int{10, 9}, candidateIDs(candidates))
+}
+
+func TestChronologicalRanker(t *testing.T) {
+	now := time.Now()
+	popular := newCandidate(1, now.Add(-time.Hour))
+	popular.ReactionCount = 100
+	candidates := []*models.FeedCandidate{popular, newCandidate(2, now), newCandidate(3, now)}
+
+	ChronologicalRanker{}.Rank(1, candidates, now)
+	assert.Equal(t, []int{3, 2, 1}, candidateIDs(candidates))
+}
+
+func TestSplitRanker(t *testing.T) {
+	weighted := NewWeightedRanker(DefaultRankingWeights())
+	ranker := NewSplitRanker(ChronologicalRanker{}, weighted)
+
+	assert.Equal(t, "chronological", ranker.RankerFor(2).Name())
+	assert.Equal(t, "weighted", ranker.RankerFor(3).Name())
+	assert.Equal(t, ranker.RankerFor(7), ranker.RankerFor(7))
+	assert.Equal(t, "chronological", NewSplitRanker().RankerFor(1).Name())
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrUnknownResourceType is returned for resource types that cannot be liked, commented on, saved or reported
+var ErrUnknownResourceType = errors.New("unknown resource type")
+
+// ErrResourceNotFound is returned when a resource does not exist or is not visible to the user
+var ErrResourceNotFound = errors.New("resource not found")
+
+// ResourceCapability describes what users can do with a type of resource
+type ResourceCapability int
+
+// Resource capabilities
+const (
+	ResourceLikeable ResourceCapability = 1 << iota
+	ResourceCommentable
+	ResourceSaveable
+	ResourceReportable
+)
+
+// ResourceRegistry knows which resource types can be liked, commented on, saved and reported,
+// checks that a target resource exists and is visible, and cleans up after deleted resources
+type ResourceRegistry struct {
+	BaseService
+	resourceRepo      *repositories.ResourceRepository
+	visibilityService *VisibilityService
+	resourceTypes     map[string]ResourceCapability
+}
+
+// NewResourceRegistry creates a new ResourceRegistry with the built-in resource types.
+// Comments can be liked; commenting on a comment is done by replying.
+func NewResourceRegistry(resourceRepo *repositories.ResourceRepository, visibilityService *VisibilityService) *ResourceRegistry {
+	return &ResourceRegistry{
+		resourceRepo:      resourceRepo,
+		visibilityService: visibilityService,
+		resourceTypes: map[string]ResourceCapability{
+			models.ResourceTypePosts:    ResourceLikeable | ResourceCommentable | ResourceSaveable | ResourceReportable,
+			models.ResourceTypePhotos:   ResourceLikeable | ResourceCommentable | ResourceSaveable | ResourceReportable,
+			models.ResourceTypeAlbums:   ResourceLikeable | ResourceCommentable,
+			models.ResourceTypeComments: ResourceLikeable | ResourceReportable,
+		},
+	}
+}
+
+// Check verifies that resourceType supports the capability and that the resource exists and is visible to viewerID.
+// Resources the viewer cannot see are reported as not found so their existence is not revealed.
+func (r *ResourceRegistry) Check(resourceType string, resourceID, viewerID int, capability ResourceCapability) error {
+	if r.resourceTypes[resourceType]&capability == 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownResourceType, resourceType)
+	}
+
+	canView, err := r.visibilityService.CanViewResource(resourceType, resourceID, viewerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrResourceNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check resource: %w", err)
+	}
+	if !canView {
+		return ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// DeleteInteractions removes the reactions, comments and saves left on deleted resources
+func (r *ResourceRegistry) DeleteInteractions(resourceType string, resourceIDs ...int) error {
+	if err := r.resourceRepo.DeleteInteractions(resourceType, resourceIDs); err != nil {
+		return fmt.Errorf("failed to delete interactions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// RoleService looks up the site-wide roles of users and the permissions they grant
+type RoleService struct {
+	BaseService
+	userRepo *repositories.UserRepository
+}
+
+// NewRoleService creates a new RoleService
+func NewRoleService(userRepo *repositories.UserRepository) *RoleService {
+	return &RoleService{userRepo: userRepo}
+}
+
+// GetRole returns a user's current role
+func (s *RoleService) GetRole(userID int) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.Role, nil
+}
+
+// HasPermission reports whether a user's current role grants a permission
+func (s *RoleService) HasPermission(userID int, permission string) (bool, error) {
+	role, err := s.GetRole(userID)
+	if err != nil {
+		return false, err
+	}
+	return models.RoleHasPermission(role, permission), nil
+}
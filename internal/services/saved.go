@@ -0,0 +1,213 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrCollectionNotFound is returned when a collection does not exist or belongs to another user
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// ErrCollectionExists is returned when a user already has a collection with the same name
+var ErrCollectionExists = errors.New("a collection with this name already exists")
+
+// SavedService provides saved items and collections functionality
+type SavedService struct {
+	BaseService
+	savedRepo         *repositories.SavedRepository
+	albumRepo         *repositories.AlbumRepository
+	postService       *PostService
+	visibilityService *VisibilityService
+	resourceRegistry  *ResourceRegistry
+}
+
+// NewSavedService creates a new SavedService
+func NewSavedService(savedRepo *repositories.SavedRepository, albumRepo *repositories.AlbumRepository,
+	postService *PostService, visibilityService *VisibilityService, resourceRegistry *ResourceRegistry) *SavedService {
+	return &SavedService{
+		savedRepo:         savedRepo,
+		albumRepo:         albumRepo,
+		postService:       postService,
+		visibilityService: visibilityService,
+		resourceRegistry:  resourceRegistry,
+	}
+}
+
+// CreateCollection creates a new collection for a user
+func (s *SavedService) CreateCollection(userID int, name string) (*models.Collection, error) {
+	collection := &models.Collection{
+		UserID: userID,
+		Name:   strings.TrimSpace(name),
+	}
+
+	if err := s.savedRepo.CreateCollection(collection); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, ErrCollectionExists
+		}
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// GetCollections retrieves a user's collections
+func (s *SavedService) GetCollections(userID int) ([]*models.Collection, error) {
+	collections, err := s.savedRepo.GetCollectionsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+	return collections, nil
+}
+
+// RenameCollection renames one of a user's collections
+func (s *SavedService) RenameCollection(collectionID, userID int, name string) (*models.Collection, error) {
+	collection, err := s.ownedCollection(collectionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Name = strings.TrimSpace(name)
+	if err := s.savedRepo.RenameCollection(collection); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, ErrCollectionExists
+		}
+		return nil, fmt.Errorf("failed to rename collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// DeleteCollection deletes one of a user's collections. Its items stay saved.
+func (s *SavedService) DeleteCollection(collectionID, userID int) error {
+	if _, err := s.ownedCollection(collectionID, userID); err != nil {
+		return err
+	}
+
+	if err := s.savedRepo.DeleteCollection(collectionID); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// SaveItem saves a post or photo the user can see, optionally into one of their collections.
+// Saving an item again moves it to the given collection.
+func (s *SavedService) SaveItem(userID int, resourceType string, resourceID int, collectionID *int) (*models.SavedItem, error) {
+	if err := s.resourceRegistry.Check(resourceType, resourceID, userID, ResourceSaveable); err != nil {
+		return nil, err
+	}
+
+	if collectionID != nil {
+		if _, err := s.ownedCollection(*collectionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	item := &models.SavedItem{
+		UserID:       userID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		CollectionID: collectionID,
+	}
+	if err := s.savedRepo.SaveItem(item); err != nil {
+		return nil, fmt.Errorf("failed to save item: %w", err)
+	}
+
+	return item, nil
+}
+
+// UnsaveItem removes a saved post or photo
+func (s *SavedService) UnsaveItem(userID int, resourceType string, resourceID int) error {
+	if err := s.savedRepo.UnsaveItem(userID, resourceType, resourceID); err != nil {
+		return fmt.Errorf("failed to unsave item: %w", err)
+	}
+	return nil
+}
+
+// GetSavedItems retrieves a user's saved items, or those in one of their collections, with the saved
+// posts and photos. Items that were deleted or that the user can no longer see are marked unavailable.
+func (s *SavedService) GetSavedItems(userID int, collectionID *int, limit, offset int) ([]*models.SavedItem, error) {
+	if collectionID != nil {
+		if _, err := s.ownedCollection(*collectionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := s.savedRepo.GetSavedItems(userID, collectionID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved items: %w", err)
+	}
+
+	postIDs := []int{}
+	for _, item := range items {
+		if item.ResourceType == models.ResourceTypePosts {
+			postIDs = append(postIDs, item.ResourceID)
+		}
+	}
+	posts, err := s.postService.GetVisiblePostsByIDs(postIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		switch item.ResourceType {
+		case models.ResourceTypePosts:
+			item.Post = posts[item.ResourceID]
+			item.Unavailable = item.Post == nil
+		case models.ResourceTypePhotos:
+			item.Photo, err = s.visiblePhoto(item.ResourceID, userID)
+			if err != nil {
+				return nil, err
+			}
+			item.Unavailable = item.Photo == nil
+		default:
+			item.Unavailable = true
+		}
+	}
+
+	return items, nil
+}
+
+// visiblePhoto returns a photo if the viewer can see it, or nil if it was deleted or is not visible
+func (s *SavedService) visiblePhoto(photoID, viewerID int) (*models.Photo, error) {
+	photo, err := s.albumRepo.GetPhotoByID(photoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo: %w", err)
+	}
+
+	canView, err := s.visibilityService.CanViewResource(models.ResourceTypePhotos, photoID, viewerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check photo visibility: %w", err)
+	}
+	if !canView {
+		return nil, nil
+	}
+
+	return photo, nil
+}
+
+// ownedCollection returns a collection of the user, or ErrCollectionNotFound
+func (s *SavedService) ownedCollection(collectionID, userID int) (*models.Collection, error) {
+	collection, err := s.savedRepo.GetCollectionByID(collectionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	if collection.UserID != userID {
+		return nil, ErrCollectionNotFound
+	}
+
+	return collection, nil
+}
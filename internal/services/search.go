@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// SearchService provides full-text search over users and posts
+type SearchService struct {
+	BaseService
+	userRepo *repositories.UserRepository
+	postRepo *repositories.PostRepository
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(userRepo *repositories.UserRepository, postRepo *repositories.PostRepository) *SearchService {
+	return &SearchService{
+		userRepo: userRepo,
+		postRepo: postRepo,
+	}
+}
+
+// Search searches users and/or posts depending on searchType. Posts are
+// filtered by what the viewer is allowed to see.
+func (s *SearchService) Search(query, searchType string, viewerID, limit, offset int) (*models.SearchResults, error) {
+	if searchType == "" {
+		searchType = models.SearchTypeAll
+	}
+	if searchType != models.SearchTypeAll && searchType != models.SearchTypeUsers && searchType != models.SearchTypePosts {
+		return nil, fmt.Errorf("invalid search type: %q", searchType)
+	}
+
+	results := &models.SearchResults{Query: query}
+
+	tsQuery := utils.BuildPrefixTSQuery(query)
+	if tsQuery == "" {
+		return results, nil
+	}
+
+	if searchType == models.SearchTypeAll || searchType == models.SearchTypeUsers {
+		users, err := s.userRepo.Search(tsQuery, query, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search users: %w", err)
+		}
+		results.Users = users
+	}
+
+	if searchType == models.SearchTypeAll || searchType == models.SearchTypePosts {
+		posts, err := s.postRepo.Search(tsQuery, viewerID, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search posts: %w", err)
+		}
+		results.Posts = posts
+	}
+
+	return results, nil
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// ErrInvalidStory is returned when a new story is missing its image or text
+var ErrInvalidStory = errors.New("image stories need an image and text stories need text")
+
+// ErrNotStoryAuthor is returned when someone other than the author asks who has seen a story or deletes it
+var ErrNotStoryAuthor = errors.New("only the author of the story can do this")
+
+// storyPurgeBatchSize is the most expired stories deleted at once
+const storyPurgeBatchSize = 100
+
+// StoryService provides story-related functionality
+type StoryService struct {
+	BaseService
+	storyRepo         *repositories.StoryRepository
+	blockRepo         *repositories.BlockRepository
+	visibilityService *VisibilityService
+}
+
+// NewStoryService creates a new StoryService
+func NewStoryService(storyRepo *repositories.StoryRepository, blockRepo *repositories.BlockRepository,
+	visibilityService *VisibilityService) *StoryService {
+	return &StoryService{
+		storyRepo:         storyRepo,
+		blockRepo:         blockRepo,
+		visibilityService: visibilityService,
+	}
+}
+
+// CreateStory posts a story that expires after models.StoryLifetime.
+// Image stories need a media URL and may have a caption; text stories need content.
+func (s *StoryService) CreateStory(userID int, storyType, content, mediaURL, privacy string) (*models.Story, error) {
+	if privacy == "" {
+		privacy = models.PrivacyPublic
+	}
+
+	switch storyType {
+	case models.StoryTypeImage:
+		if mediaURL == "" {
+			return nil, ErrInvalidStory
+		}
+	case models.StoryTypeText:
+		if strings.TrimSpace(content) == "" || mediaURL != "" {
+			return nil, ErrInvalidStory
+		}
+	default:
+		return nil, fmt.Errorf("invalid story type: %q", storyType)
+	}
+
+	now := time.Now()
+	story := &models.Story{
+		UserID:    userID,
+		Type:      storyType,
+		Content:   content,
+		MediaURL:  mediaURL,
+		Privacy:   privacy,
+		ExpiresAt: now.Add(models.StoryLifetime),
+		CreatedAt: now,
+	}
+
+	if err := s.storyRepo.Create(story); err != nil {
+		return nil, fmt.Errorf("failed to create story: %w", err)
+	}
+
+	viewCount := 0
+	story.ViewCount = &viewCount
+	return story, nil
+}
+
+// GetUserStories retrieves the active stories of a user that the viewer may see, oldest first.
+// The author also gets the view count of each story.
+func (s *StoryService) GetUserStories(userID, viewerID int) ([]*models.Story, error) {
+	if userID != viewerID {
+		blocked, err := s.blockRepo.IsBlocked(userID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrNotVisible
+		}
+	}
+
+	stories, err := s.storyRepo.GetActiveStoriesByUser(userID, viewerID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stories: %w", err)
+	}
+
+	if userID == viewerID {
+		if err := s.attachViewCounts(stories); err != nil {
+			return nil, err
+		}
+	}
+
+	return stories, nil
+}
+
+// ViewStory retrieves an active story and records that the viewer has seen it
+func (s *StoryService) ViewStory(storyID, viewerID int) (*models.Story, error) {
+	story, err := s.storyRepo.GetByID(storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !story.ExpiresAt.After(now) {
+		return nil, ErrNotVisible
+	}
+
+	if story.UserID == viewerID {
+		if err := s.attachViewCounts([]*models.Story{story}); err != nil {
+			return nil, err
+		}
+		return story, nil
+	}
+
+	blocked, err := s.blockRepo.IsBlocked(story.UserID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	canView, err := s.visibilityService.CanViewContent(story.UserID, viewerID, story.Privacy)
+	if err != nil {
+		return nil, err
+	}
+	if blocked || !canView {
+		return nil, ErrNotVisible
+	}
+
+	if err := s.storyRepo.RecordView(story.ID, viewerID, now); err != nil {
+		return nil, fmt.Errorf("failed to view story: %w", err)
+	}
+	story.Seen = true
+
+	return story, nil
+}
+
+// GetTray retrieves the friends with active stories the viewer may see, unseen stories first
+func (s *StoryService) GetTray(viewerID int, limit, offset int) ([]*models.StoryTrayItem, error) {
+	items, err := s.storyRepo.GetTray(viewerID, time.Now(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stories tray: %w", err)
+	}
+	return items, nil
+}
+
+// GetViewers retrieves the users who have seen a story. Only the author may see them.
+func (s *StoryService) GetViewers(storyID, userID int, limit, offset int) ([]*models.StoryView, error) {
+	story, err := s.storyRepo.GetByID(storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if story.UserID != userID {
+		return nil, ErrNotStoryAuthor
+	}
+
+	views, err := s.storyRepo.GetViewers(storyID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story viewers: %w", err)
+	}
+
+	return views, nil
+}
+
+// DeleteStory deletes one of the user's stories and its media before it expires
+func (s *StoryService) DeleteStory(storyID, userID int) error {
+	story, err := s.storyRepo.GetByID(storyID)
+	if err != nil {
+		return err
+	}
+
+	if story.UserID != userID {
+		return ErrNotStoryAuthor
+	}
+
+	if err := s.storyRepo.Delete(storyID); err != nil {
+		return fmt.Errorf("failed to delete story: %w", err)
+	}
+
+	removeStoryMedia([]string{story.MediaURL})
+	return nil
+}
+
+// PurgeExpired deletes expired stories and their media in batches until none are left or done is closed.
+// Every server runs it periodically; each expired story is deleted by only one of them.
+func (s *StoryService) PurgeExpired(done <-chan struct{}) {
+	for {
+		mediaURLs, err := s.storyRepo.DeleteExpired(time.Now(), storyPurgeBatchSize)
+		if err != nil {
+			log.Printf("WARN: Failed to purge expired stories: %v", err)
+			return
+		}
+		removeStoryMedia(mediaURLs)
+
+		if len(mediaURLs) < storyPurgeBatchSize {
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+// attachViewCounts sets the view count of each story
+func (s *StoryService) attachViewCounts(stories []*models.Story) error {
+	storyIDs := make([]int, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = story.ID
+	}
+
+	counts, err := s.storyRepo.GetViewCounts(storyIDs)
+	if err != nil {
+		return fmt.Errorf("failed to count story views: %w", err)
+	}
+
+	for _, story := range stories {
+		count := counts[story.ID]
+		story.ViewCount = &count
+	}
+
+	return nil
+}
+
+// removeStoryMedia deletes the uploaded media of deleted stories
+func removeStoryMedia(mediaURLs []string) {
+	for _, mediaURL := range mediaURLs {
+		if mediaURL == "" {
+			continue
+		}
+		if err := utils.RemoveUploadedFile(mediaURL); err != nil {
+			log.Printf("WARN: Failed to remove story media %s: %v", mediaURL, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrInvalidSuspension is returned for a suspension without a known kind, a reason or a positive duration
+var ErrInvalidSuspension = errors.New("invalid suspension")
+
+// ErrRoleTooHigh is returned when suspending or restricting a user whose role is the same as or above the actor's
+var ErrRoleTooHigh = errors.New("you cannot suspend or restrict a user whose role is the same as or above yours")
+
+// ErrNotSuspended is returned when lifting a suspension or restriction the user does not have
+var ErrNotSuspended = errors.New("the user has no active suspension or restriction of this kind")
+
+// SuspensionService suspends and restricts accounts for a period and lifts them when they end
+type SuspensionService struct {
+	BaseService
+	suspensionRepo *repositories.SuspensionRepository
+	roleService    *RoleService
+}
+
+// NewSuspensionService creates a new SuspensionService
+func NewSuspensionService(suspensionRepo *repositories.SuspensionRepository, roleService *RoleService) *SuspensionService {
+	return &SuspensionService{suspensionRepo: suspensionRepo, roleService: roleService}
+}
+
+// Suspend suspends or restricts a user's account for the given duration. A suspended user cannot log in
+// or use their tokens; a restricted user's content is visible only to themselves. The actor's role must
+// rank above the user's, so moderators cannot lock out other moderators or admins.
+func (s *SuspensionService) Suspend(actorID, userID int, kind, reason, note string, duration time.Duration) (*models.Suspension, error) {
+	if kind != models.SuspensionKindSuspension && kind != models.SuspensionKindRestriction {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidSuspension, kind)
+	}
+	if !models.IsValidReportReason(reason) {
+		return nil, fmt.Errorf("%w: unknown reason %q", ErrInvalidSuspension, reason)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("%w: a %s needs a duration", ErrInvalidSuspension, kind)
+	}
+
+	actorRole, err := s.roleService.GetRole(actorID)
+	if err != nil {
+		return nil, err
+	}
+	userRole, err := s.roleService.GetRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.RoleOutranks(actorRole, userRole) {
+		return nil, ErrRoleTooHigh
+	}
+
+	now := time.Now()
+	suspension := &models.Suspension{
+		UserID:    userID,
+		Kind:      kind,
+		Reason:    reason,
+		Note:      note,
+		CreatedBy: &actorID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := s.suspensionRepo.Create(suspension); err != nil {
+		return nil, err
+	}
+
+	suspension.Status = suspension.StatusAt(now)
+	return suspension, nil
+}
+
+// Lift ends a user's active suspensions or restrictions of the given kind early
+func (s *SuspensionService) Lift(actorID, userID int, kind string) error {
+	lifted, err := s.suspensionRepo.LiftActive(userID, kind, actorID, time.Now())
+	if err != nil {
+		return err
+	}
+	if lifted == 0 {
+		return ErrNotSuspended
+	}
+	return nil
+}
+
+// GetHistory retrieves every suspension and restriction of a user, newest first
+func (s *SuspensionService) GetHistory(userID int) ([]*models.Suspension, error) {
+	suspensions, err := s.suspensionRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suspension history: %w", err)
+	}
+
+	now := time.Now()
+	for _, suspension := range suspensions {
+		suspension.Status = suspension.StatusAt(now)
+	}
+	return suspensions, nil
+}
+
+// LiftExpired clears the suspensions and restrictions that have ended from users' accounts.
+// Logins and tokens are checked against the end time, but restricted content stays hidden until this runs.
+func (s *SuspensionService) LiftExpired(done <-chan struct{}) {
+	if _, err := s.suspensionRepo.LiftExpired(time.Now()); err != nil {
+		log.Printf("WARN: Failed to lift expired suspensions: %v", err)
+	}
+}
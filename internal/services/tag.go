@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
+)
+
+// TrendingWindowMax is the longest time window trending tags can be computed over
+const TrendingWindowMax = 7 * 24 * time.Hour
+
+// TagService provides hashtag-related functionality
+type TagService struct {
+	BaseService
+	tagRepo *repositories.TagRepository
+}
+
+// NewTagService creates a new TagService
+func NewTagService(tagRepo *repositories.TagRepository) *TagService {
+	return &TagService{
+		tagRepo: tagRepo,
+	}
+}
+
+// GetPostsByTag retrieves the posts with a hashtag that the viewer is allowed to see
+func (s *TagService) GetPostsByTag(tag string, viewerID, limit, offset int) ([]*models.PostWithUser, error) {
+	tag = utils.NormalizeHashtag(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("tag is required")
+	}
+
+	posts, err := s.tagRepo.GetPostsByTag(tag, viewerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by tag: %w", err)
+	}
+	return posts, nil
+}
+
+// GetTrendingTags retrieves the most used hashtags in public posts over the last window
+func (s *TagService) GetTrendingTags(window time.Duration, limit int) ([]*models.TrendingTag, error) {
+	if window <= 0 || window > TrendingWindowMax {
+		window = TrendingWindowMax
+	}
+
+	tags, err := s.tagRepo.GetTrendingTags(time.Now().Add(-window), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import "time"
+
+// PeriodicTask runs a job in the background at a fixed interval, e.g. publishing
+// scheduled posts. The job receives a channel that is closed when the task is
+// closed, so long-running jobs can stop early.
+type PeriodicTask struct {
+	interval time.Duration
+	job      func(done <-chan struct{})
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewPeriodicTask creates a new PeriodicTask that runs job every interval
+func NewPeriodicTask(interval time.Duration, job func(done <-chan struct{})) *PeriodicTask {
+	return &PeriodicTask{
+		interval: interval,
+		job:      job,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start starts running the job in the background
+func (t *PeriodicTask) Start() {
+	go t.run()
+}
+
+// Close stops the task and waits for a running job to finish
+func (t *PeriodicTask) Close() {
+	close(t.done)
+	<-t.stopped
+}
+
+// run runs the job every interval until the task is closed
+func (t *PeriodicTask) run() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			t.job(t.done)
+		}
+	}
+}
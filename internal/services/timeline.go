@@ -0,0 +1,51 @@
+package services
+
+import (
+	"log"
+
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+const (
+	// maxTimelineFanout is the most friends an author can have for their posts to be copied to
+	// timelines; posts of authors with more friends are read from posts when a feed is loaded
+	maxTimelineFanout = 5000
+	// timelineFanoutBatchSize is how many posts are fanned out per transaction
+	timelineFanoutBatchSize = 50
+)
+
+// TimelineService maintains the precomputed feed timelines of users
+type TimelineService struct {
+	BaseService
+	timelineRepo *repositories.TimelineRepository
+}
+
+// NewTimelineService creates a new TimelineService
+func NewTimelineService(timelineRepo *repositories.TimelineRepository) *TimelineService {
+	return &TimelineService{
+		timelineRepo: timelineRepo,
+	}
+}
+
+// FanOutPending copies new posts to the timelines of their authors and the authors' friends, batch by
+// batch, until none are left or done is closed. Feeds include posts that are still pending, so a post
+// shows up before it is fanned out.
+func (s *TimelineService) FanOutPending(done <-chan struct{}) {
+	for {
+		count, err := s.timelineRepo.FanOutPending(timelineFanoutBatchSize, maxTimelineFanout)
+		if err != nil {
+			log.Printf("WARN: Failed to fan out posts: %v", err)
+			return
+		}
+
+		if count < timelineFanoutBatchSize {
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
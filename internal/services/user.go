@@ -1,22 +1,33 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gocli/social_api/internal/models"
 	"github.com/gocli/social_api/internal/repositories"
+	"github.com/gocli/social_api/internal/utils"
 )
 
+// UsernameReservationPeriod is how long a released username stays reserved for its previous owner
+const UsernameReservationPeriod = 30 * 24 * time.Hour
+
+// ErrUsernameTaken is returned when a username is held or reserved by another user
+var ErrUsernameTaken = errors.New("username is already taken")
+
 // UserService provides user-related functionality
 type UserService struct {
 	BaseService
-	userRepo *repositories.UserRepository
+	userRepo          *repositories.UserRepository
+	visibilityService *VisibilityService
 }
 
 // NewUserService creates a new UserService
-func NewUserService(userRepo *repositories.UserRepository) *UserService {
+func NewUserService(userRepo *repositories.UserRepository, visibilityService *VisibilityService) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:          userRepo,
+		visibilityService: visibilityService,
 	}
 }
 
@@ -29,6 +40,128 @@ func (s *UserService) GetUserByID(id int) (*models.User, error) {
 	return user, nil
 }
 
+// GetUserProfile retrieves a user's profile as seen by the viewer,
+// hiding the fields the owner's privacy settings do not share with them
+func (s *UserService) GetUserProfile(userID, viewerID int) (*models.UserProfile, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	settings, err := s.userRepo.GetPrivacySettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get privacy settings: %w", err)
+	}
+
+	profile := &models.UserProfile{UserPublic: *user.Public()}
+
+	// Friendship is what decides the "friends" level, so resolve it once for all fields
+	if userID != viewerID {
+		profile.IsFriend, err = s.visibilityService.CanView(userID, viewerID, models.PrivacyFriends)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	canView := func(level string) bool {
+		switch {
+		case userID == viewerID:
+			return true
+		case level == models.PrivacyPublic:
+			return true
+		case level == models.PrivacyFriends:
+			return profile.IsFriend
+		default:
+			return false
+		}
+	}
+
+	if canView(settings.EmailVisibility) {
+		profile.Email = user.Email
+	}
+	if canView(settings.BirthDateVisibility) {
+		birthDate := user.BirthDate
+		profile.BirthDate = &birthDate
+	}
+	profile.CanViewFriends = canView(settings.FriendListVisibility)
+
+	return profile, nil
+}
+
+// GetUserProfileByUsername retrieves a user's profile by username as seen by the viewer
+func (s *UserService) GetUserProfileByUsername(username string, viewerID int) (*models.UserProfile, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.GetUserProfile(user.ID, viewerID)
+}
+
+// ChangeUsername changes a user's username if it is valid and available
+func (s *UserService) ChangeUsername(userID int, username string) (*models.User, error) {
+	if !utils.IsValidUsername(username) {
+		return nil, fmt.Errorf("invalid username: %q", username)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Username == username {
+		return user, nil
+	}
+
+	available, err := s.userRepo.IsUsernameAvailable(username, userID, time.Now().Add(-UsernameReservationPeriod))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check username: %w", err)
+	}
+	if !available {
+		return nil, ErrUsernameTaken
+	}
+
+	if err := s.userRepo.ChangeUsername(userID, username); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("failed to change username: %w", err)
+	}
+
+	user.Username = username
+	return user, nil
+}
+
+// GetUsernameHistory retrieves a user's past username changes
+func (s *UserService) GetUsernameHistory(userID int) ([]*models.UsernameChange, error) {
+	changes, err := s.userRepo.GetUsernameHistory(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get username history: %w", err)
+	}
+	return changes, nil
+}
+
+// GetPrivacySettings retrieves a user's profile privacy settings
+func (s *UserService) GetPrivacySettings(userID int) (*models.PrivacySettings, error) {
+	settings, err := s.userRepo.GetPrivacySettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get privacy settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdatePrivacySettings updates a user's profile privacy settings
+func (s *UserService) UpdatePrivacySettings(settings *models.PrivacySettings) error {
+	for _, level := range []string{settings.EmailVisibility, settings.BirthDateVisibility, settings.FriendListVisibility} {
+		if !models.IsValidPrivacy(level) {
+			return fmt.Errorf("invalid privacy level: %q", level)
+		}
+	}
+
+	if err := s.userRepo.UpsertPrivacySettings(settings); err != nil {
+		return fmt.Errorf("failed to update privacy settings: %w", err)
+	}
+	return nil
+}
+
 // UpdateUser updates a user's information
 func (s *UserService) UpdateUser(user *models.User) error {
 	if err := s.userRepo.Update(user); err != nil {
@@ -56,9 +189,14 @@ func (s *UserService) UpdateProfilePictureURL(userID int, profilePictureURL stri
 	return nil
 }
 
-// SearchUsers searches for users by query
+// SearchUsers searches for users by name or username, matching words as prefixes
 func (s *UserService) SearchUsers(query string, limit, offset int) ([]*models.UserPublic, error) {
-	users, err := s.userRepo.Search(query, limit, offset)
+	tsQuery := utils.BuildPrefixTSQuery(query)
+	if tsQuery == "" {
+		return []*models.UserPublic{}, nil
+	}
+
+	users, err := s.userRepo.Search(tsQuery, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
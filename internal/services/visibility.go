@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocli/social_api/internal/models"
+	"github.com/gocli/social_api/internal/repositories"
+)
+
+// ErrNotVisible is returned when the viewer is not allowed to see the requested content
+var ErrNotVisible = errors.New("content is not visible to this user")
+
+// ErrCommentsNotAllowed is returned when a user may not comment on a resource
+var ErrCommentsNotAllowed = errors.New("you are not allowed to comment on this content")
+
+// VisibilityService decides who can see content published with a privacy level
+type VisibilityService struct {
+	BaseService
+	friendRepo  *repositories.FriendRepository
+	postRepo    *repositories.PostRepository
+	albumRepo   *repositories.AlbumRepository
+	commentRepo *repositories.CommentRepository
+	userRepo    *repositories.UserRepository
+}
+
+// NewVisibilityService creates a new VisibilityService
+func NewVisibilityService(friendRepo *repositories.FriendRepository, postRepo *repositories.PostRepository,
+	albumRepo *repositories.AlbumRepository, commentRepo *repositories.CommentRepository,
+	userRepo *repositories.UserRepository) *VisibilityService {
+	return &VisibilityService{
+		friendRepo:  friendRepo,
+		postRepo:    postRepo,
+		albumRepo:   albumRepo,
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// CanView reports whether viewerID may see content owned by ownerID with the given privacy level
+func (s *VisibilityService) CanView(ownerID, viewerID int, privacy string) (bool, error) {
+	if ownerID == viewerID {
+		return true, nil
+	}
+
+	switch privacy {
+	case models.PrivacyPublic, "":
+		// Older rows may have been stored without a privacy level; they were always public
+		return true, nil
+	case models.PrivacyFriends:
+		areFriends, err := s.friendRepo.AreFriends(ownerID, viewerID)
+		if err != nil {
+			return false, fmt.Errorf("failed to check visibility: %w", err)
+		}
+		return areFriends, nil
+	default:
+		return false, nil
+	}
+}
+
+// CanViewContent reports whether viewerID may see a post, story, photo or other content owned by ownerID
+// with the given privacy level. The content of a restricted user is visible only to themselves.
+func (s *VisibilityService) CanViewContent(ownerID, viewerID int, privacy string) (bool, error) {
+	hidden, err := s.IsRestrictedFor(ownerID, viewerID)
+	if err != nil || hidden {
+		return false, err
+	}
+	return s.CanView(ownerID, viewerID, privacy)
+}
+
+// IsRestrictedFor reports whether all of ownerID's content is hidden from viewerID because ownerID is restricted
+func (s *VisibilityService) IsRestrictedFor(ownerID, viewerID int) (bool, error) {
+	if ownerID == viewerID {
+		return false, nil
+	}
+	return s.IsRestricted(ownerID)
+}
+
+// IsRestricted reports whether userID is restricted, so their content is visible only to themselves
+func (s *VisibilityService) IsRestricted(userID int) (bool, error) {
+	restricted, err := s.userRepo.IsRestricted(userID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to check visibility: %w", err)
+	}
+	return restricted, nil
+}
+
+// CanViewResource reports whether viewerID may see a post, photo, album or comment.
+// Photos inherit the privacy level of their album, and comments that of the commented resource.
+func (s *VisibilityService) CanViewResource(resourceType string, resourceID, viewerID int) (bool, error) {
+	if resourceType == models.ResourceTypeComments {
+		return s.canViewComment(resourceID, viewerID)
+	}
+
+	ownerID, privacy, err := s.resourceAudience(resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+	return s.CanViewContent(ownerID, viewerID, privacy)
+}
+
+// CanComment reports whether userID may comment on a post, photo or album. They must be able to see it,
+// and the author of a post can restrict comments to friends or turn them off for everyone else.
+func (s *VisibilityService) CanComment(resourceType string, resourceID, userID int) (bool, error) {
+	if resourceType != models.ResourceTypePosts {
+		return s.CanViewResource(resourceType, resourceID, userID)
+	}
+
+	post, err := s.postRepo.GetByID(resourceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	canView, err := s.CanViewContent(post.UserID, userID, post.Privacy)
+	if err != nil || !canView {
+		return false, err
+	}
+
+	areFriends := false
+	if post.CommentPermission == models.CommentPermissionFriends && post.UserID != userID {
+		areFriends, err = s.friendRepo.AreFriends(post.UserID, userID)
+		if err != nil {
+			return false, fmt.Errorf("failed to check friendship: %w", err)
+		}
+	}
+	return commentAllowed(post, userID, canView, areFriends), nil
+}
+
+// commentAllowed applies a post's comment permission to a user who can or cannot see the post
+// and is or is not a friend of its author
+func commentAllowed(post *models.Post, userID int, canView, areFriends bool) bool {
+	if !canView {
+		return false
+	}
+
+	switch post.CommentPermission {
+	case models.CommentPermissionNobody:
+		return post.UserID == userID
+	case models.CommentPermissionFriends:
+		return post.UserID == userID || areFriends
+	default:
+		return true
+	}
+}
+
+// canViewComment reports whether viewerID may see a comment. Deleted comments are not visible,
+// hidden comments are only visible to their author and the owner of the commented resource,
+// and comments by restricted users only to their author.
+func (s *VisibilityService) canViewComment(commentID, viewerID int) (bool, error) {
+	comment, err := s.commentRepo.GetCommentByID(commentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	if comment.IsDeleted {
+		return false, nil
+	}
+	hidden, err := s.IsRestrictedFor(comment.UserID, viewerID)
+	if err != nil || hidden {
+		return false, err
+	}
+	if comment.IsHidden && comment.UserID != viewerID {
+		ownerID, err := s.ResourceOwner(comment.ResourceType, comment.ResourceID)
+		if err != nil {
+			return false, err
+		}
+		if ownerID != viewerID {
+			return false, nil
+		}
+	}
+
+	return s.CanViewResource(comment.ResourceType, comment.ResourceID, viewerID)
+}
+
+// resourceAudience returns the owner and privacy level that govern who can see a resource
+func (s *VisibilityService) resourceAudience(resourceType string, resourceID int) (int, string, error) {
+	switch resourceType {
+	case models.ResourceTypePosts:
+		post, err := s.postRepo.GetByID(resourceID)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get post: %w", err)
+		}
+		return post.UserID, post.Privacy, nil
+	case models.ResourceTypeAlbums:
+		album, err := s.albumRepo.GetAlbumByID(resourceID)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get album: %w", err)
+		}
+		return album.UserID, album.Privacy, nil
+	case models.ResourceTypePhotos:
+		photo, err := s.albumRepo.GetPhotoByID(resourceID)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get photo: %w", err)
+		}
+		return s.resourceAudience(models.ResourceTypeAlbums, photo.AlbumID)
+	default:
+		return 0, "", fmt.Errorf("unsupported resource type: %q", resourceType)
+	}
+}
+
+// ResourceOwner returns the ID of the user who owns a post, photo, album or comment.
+// A photo is owned by the owner of its album, and a comment by its author.
+func (s *VisibilityService) ResourceOwner(resourceType string, resourceID int) (int, error) {
+	if resourceType == models.ResourceTypeComments {
+		comment, err := s.commentRepo.GetCommentByID(resourceID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get comment: %w", err)
+		}
+		return comment.UserID, nil
+	}
+
+	ownerID, _, err := s.resourceAudience(resourceType, resourceID)
+	return ownerID, err
+}
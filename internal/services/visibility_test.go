@@ -0,0 +1,49 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocli/social_api/internal/models"
+)
+
+func TestCommentAllowed(t *testing.T) {
+	const authorID, friendID, strangerID = 1, 2, 3
+
+	tests := []struct {
+		permission string
+		privacy    string
+		userID     int
+		canView    bool
+		expected   bool
+	}{
+		// Nobody but the author can see an only_me, hidden or held post, whatever its comment permission
+		{models.CommentPermissionEveryone, models.PrivacyOnlyMe, friendID, false, false},
+		{models.CommentPermissionFriends, models.PrivacyOnlyMe, friendID, false, false},
+		{models.CommentPermissionNobody, models.PrivacyOnlyMe, friendID, false, false},
+		{models.CommentPermissionEveryone, models.PrivacyOnlyMe, authorID, true, true},
+		{models.CommentPermissionFriends, models.PrivacyOnlyMe, authorID, true, true},
+		{models.CommentPermissionNobody, models.PrivacyOnlyMe, authorID, true, true},
+
+		// Friends-only posts
+		{models.CommentPermissionEveryone, models.PrivacyFriends, friendID, true, true},
+		{models.CommentPermissionFriends, models.PrivacyFriends, friendID, true, true},
+		{models.CommentPermissionNobody, models.PrivacyFriends, friendID, true, false},
+		{models.CommentPermissionEveryone, models.PrivacyFriends, strangerID, false, false},
+		{models.CommentPermissionFriends, models.PrivacyFriends, strangerID, false, false},
+		{models.CommentPermissionNobody, models.PrivacyFriends, strangerID, false, false},
+
+		// Public posts can restrict comments to friends
+		{models.CommentPermissionFriends, models.PrivacyPublic, strangerID, true, false},
+		{models.CommentPermissionEveryone, models.PrivacyPublic, strangerID, true, true},
+	}
+
+	for _, tt := range tests {
+		post := &models.Post{UserID: authorID, Privacy: tt.privacy, CommentPermission: tt.permission}
+		areFriends := tt.userID == friendID
+		name := fmt.Sprintf("%s/%s/user %d", tt.permission, tt.privacy, tt.userID)
+		assert.Equal(t, tt.expected, commentAllowed(post, tt.userID, tt.canView, areFriends), name)
+	}
+}
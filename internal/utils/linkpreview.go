@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Limits on the text taken from a page for a link preview, in runes
+const (
+	maxPreviewTitleLength       = 300
+	maxPreviewDescriptionLength = 1000
+)
+
+// LinkPreview is the title, description and image a page declares about itself
+type LinkPreview struct {
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+// ParseLinkPreview reads the head of an HTML page and returns its preview.
+// Open Graph and Twitter card tags win over the <title> and description meta
+// tags. A relative image URL is resolved against pageURL, and only http and
+// https image URLs are kept.
+func ParseLinkPreview(r io.Reader, pageURL *url.URL) LinkPreview {
+	meta := map[string]string{}
+	title := ""
+
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		if tokenType == html.EndTagToken && token.Data == "head" || tokenType == html.StartTagToken && token.Data == "body" {
+			break
+		}
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.Data {
+			case "title":
+				inTitle = tokenType == html.StartTagToken
+			case "meta":
+				key, content := "", ""
+				for _, attr := range token.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "property", "name":
+						key = strings.ToLower(strings.TrimSpace(attr.Val))
+					case "content":
+						content = strings.TrimSpace(attr.Val)
+					}
+				}
+				if _, seen := meta[key]; key != "" && content != "" && !seen {
+					meta[key] = content
+				}
+			}
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = strings.TrimSpace(token.Data)
+			}
+		case html.EndTagToken:
+			if token.Data == "title" {
+				inTitle = false
+			}
+		}
+	}
+
+	preview := LinkPreview{
+		Title:       firstNonEmpty(meta["og:title"], meta["twitter:title"], title),
+		Description: firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"]),
+	}
+	preview.Title = truncateRunes(collapseSpaces(preview.Title), maxPreviewTitleLength)
+	preview.Description = truncateRunes(collapseSpaces(preview.Description), maxPreviewDescriptionLength)
+
+	if image := firstNonEmpty(meta["og:image"], meta["twitter:image"]); image != "" {
+		if imageURL, err := pageURL.Parse(image); err == nil && (imageURL.Scheme == "http" || imageURL.Scheme == "https") {
+			preview.ImageURL = imageURL.String()
+		}
+	}
+
+	return preview
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// collapseSpaces replaces runs of whitespace with a single space
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncateRunes shortens s to at most max runes
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinkPreview(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/articles/go")
+
+	page := `<!doctype html><html><head>
+		<title>  Plain   title </title>
+		<meta name="description" content="Plain description">
+		<meta property="og:title" content="Open Graph title">
+		<meta property="og:image" content="/img/cover.png">
+		</head><body><meta property="og:description" content="ignored after the head"></body></html>`
+
+	assert.Equal(t, LinkPreview{
+		Title:       "Open Graph title",
+		Description: "Plain description",
+		ImageURL:    "https://example.com/img/cover.png",
+	}, ParseLinkPreview(strings.NewReader(page), pageURL))
+}
+
+func TestParseLinkPreviewFallbacks(t *testing.T) {
+	pageURL, _ := url.Parse("http://example.com/")
+
+	preview := ParseLinkPreview(strings.NewReader(`<title>Only a
+		title</title><meta property="og:image" content="javascript:alert(1)">`), pageURL)
+	assert.Equal(t, LinkPreview{Title: "Only a title"}, preview)
+
+	preview = ParseLinkPreview(strings.NewReader(`<meta name="twitter:title" content="`+strings.Repeat("é", 400)+`">`), pageURL)
+	assert.Equal(t, 300, len([]rune(preview.Title)))
+
+	assert.Equal(t, LinkPreview{}, ParseLinkPreview(strings.NewReader("not html at all"), pageURL))
+}
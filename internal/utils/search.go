@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSearchTerms caps how many words of a search query are used
+const maxSearchTerms = 8
+
+// BuildPrefixTSQuery turns free text into a PostgreSQL tsquery that matches
+// every word as a prefix, e.g. "ana sou" becomes "ana:* & sou:*". Only letters
+// and digits are kept, so the result is always safe to pass to to_tsquery.
+// It returns an empty string when the text contains no searchable words.
+func BuildPrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
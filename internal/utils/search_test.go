@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPrefixTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"ana":                     "ana:*",
+		"Ana Sou":                 "ana:* & sou:*",
+		"  ana_souza  ":           "ana:* & souza:*",
+		"São Paulo":               "são:* & paulo:*",
+		"it's & | ! ( ) :* <->":   "it:* & s:*",
+		"":                        "",
+		"!!!":                     "",
+		"a b c d e f g h i j k l": "a:* & b:* & c:* & d:* & e:* & f:* & g:* & h:*",
+	}
+
+	for input, expected := range tests {
+		assert.Equal(t, expected, BuildPrefixTSQuery(input), input)
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxHashtagLength is the longest hashtag that will be extracted, in runes
+const maxHashtagLength = 100
+
+// ExtractHashtags returns the distinct hashtags in content, lowercased and
+// without the leading '#', in order of first appearance. A hashtag starts with
+// '#' at the beginning of the text or after a character that cannot be part of
+// a word, and consists of letters, digits and underscores with at least one letter.
+func ExtractHashtags(content string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' || (i > 0 && (isWordRune(runes[i-1]) || runes[i-1] == '&')) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		tag := strings.ToLower(string(runes[i+1 : end]))
+		if end-i-1 <= maxHashtagLength && strings.IndexFunc(tag, unicode.IsLetter) >= 0 && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+
+		i = end - 1
+	}
+
+	return tags
+}
+
+// MentionMatch is an @username mention found in a text. Offset and Length are
+// measured in runes and cover the whole mention, including the '@'.
+type MentionMatch struct {
+	Username string
+	Offset   int
+	Length   int
+}
+
+// ExtractMentions returns every @username mention in content in order of
+// appearance. A mention starts with '@' at the beginning of the text or after a
+// character that cannot be part of a word, so email addresses are not matched,
+// and must be followed by a syntactically valid username.
+func ExtractMentions(content string) []MentionMatch {
+	mentions := []MentionMatch{}
+
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isWordRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		username := string(runes[i+1 : end])
+		if IsValidUsername(username) {
+			mentions = append(mentions, MentionMatch{Username: username, Offset: i, Length: end - i})
+		}
+
+		i = end - 1
+	}
+
+	return mentions
+}
+
+// ExtractLinks returns every http, https or www. link in content in order of appearance.
+// Punctuation at the end of a link, such as the full stop ending a sentence, is not part of it.
+func ExtractLinks(content string) []string {
+	links := []string{}
+
+	for _, field := range strings.Fields(content) {
+		lower := strings.ToLower(field)
+		start := -1
+		for _, prefix := range []string{"http://", "https://", "www."} {
+			if index := strings.Index(lower, prefix); index >= 0 && (start < 0 || index < start) {
+				start = index
+			}
+		}
+		if start < 0 {
+			continue
+		}
+
+		link := strings.TrimRight(field[start:], ".,;:!?'\"()[]<>")
+		if len(link) > len("www.") && !strings.HasSuffix(strings.ToLower(link), "://") {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// NormalizeHashtag lowercases a hashtag and strips its leading '#'
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// isWordRune reports whether r can be part of a hashtag or username
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
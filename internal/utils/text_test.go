@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := map[string][]string{
+		"no tags here":                           {},
+		"#golang is fun":                         {"golang"},
+		"Loving #GoLang and #golang, #Postgres!": {"golang", "postgres"},
+		"Férias em #SãoPaulo #verão2025":         {"sãopaulo", "verão2025"},
+		"issue#42 and email@x#y are not tags":    {},
+		"#1 and #2024 need a letter":             {},
+		"(#wrapped) #snake_case #end.":           {"wrapped", "snake_case", "end"},
+		"&#39; html entities are ignored":        {},
+		"## and # alone":                         {},
+	}
+
+	for input, expected := range tests {
+		assert.Equal(t, expected, ExtractHashtags(input), input)
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	assert.Equal(t, "golang", NormalizeHashtag("#GoLang"))
+	assert.Equal(t, "golang", NormalizeHashtag(" golang "))
+}
+
+func TestExtractLinks(t *testing.T) {
+	links := ExtractLinks("See https://example.com/a?b=1, (http://x.io) and www.Site.org. Not ftp://nope or https:// alone")
+
+	assert.Equal(t, []string{"https://example.com/a?b=1", "http://x.io", "www.Site.org"}, links)
+	assert.Empty(t, ExtractLinks("no links here, just example.com"))
+}
+
+func TestExtractMentions(t *testing.T) {
+	mentions := ExtractMentions("Olá @ana_b and @Carlos99! mail me@example.com or @x, @josé @ana_b")
+
+	assert.Equal(t, []MentionMatch{
+		{Username: "ana_b", Offset: 4, Length: 6},
+		{Username: "Carlos99", Offset: 15, Length: 9},
+		{Username: "ana_b", Offset: 59, Length: 6},
+	}, mentions)
+	assert.Empty(t, ExtractMentions("no mentions, @admin and @12345 are not valid"))
+}
@@ -0,0 +1,11 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// RemoveUploadedFile deletes a file stored by the upload pipeline, given its public /uploads/ URL
+func RemoveUploadedFile(url string) error {
+	return os.Remove(strings.TrimPrefix(url, "/"))
+}
@@ -3,6 +3,8 @@ package utils
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -26,6 +28,34 @@ func (ve ValidationErrors) Error() string {
 	return strings.Join(errs, "; ")
 }
 
+// usernamePattern matches URL-safe usernames made of letters, digits and underscores
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
+
+// reservedUsernames cannot be claimed because they clash with routes or impersonate staff
+var reservedUsernames = map[string]bool{
+	"admin":     true,
+	"api":       true,
+	"help":      true,
+	"me":        true,
+	"moderator": true,
+	"root":      true,
+	"search":    true,
+	"settings":  true,
+	"support":   true,
+	"system":    true,
+}
+
+// IsValidUsername reports whether username is URL-safe, not purely numeric and not reserved
+func IsValidUsername(username string) bool {
+	if !usernamePattern.MatchString(username) {
+		return false
+	}
+	if strings.Trim(username, "0123456789") == "" {
+		return false
+	}
+	return !reservedUsernames[strings.ToLower(username)]
+}
+
 // Validator wraps the go-playground/validator to provide validation functionality
 type Validator struct {
 	validate *validator.Validate
@@ -37,8 +67,13 @@ func NewValidator() *Validator {
 		validate: validator.New(validator.WithRequiredStructEnabled()),
 	}
 
-	// Register custom validation rules if needed
-	// v.validate.RegisterValidation("custom_rule", customRuleFunc)
+	// Register custom validation rules
+	if err := v.validate.RegisterValidation("username", validateUsername); err != nil {
+		panic(fmt.Sprintf("failed to register username validation: %v", err))
+	}
+	if err := v.validate.RegisterValidation("website", validateWebsite); err != nil {
+		panic(fmt.Sprintf("failed to register website validation: %v", err))
+	}
 
 	return v
 }
@@ -71,6 +106,12 @@ func (v *Validator) getErrorMessage(err validator.FieldError) string {
 		return fmt.Sprintf("This field must be at least %s characters long", err.Param())
 	case "max":
 		return fmt.Sprintf("This field must be at most %s characters long", err.Param())
+	case "oneof":
+		return fmt.Sprintf("This field must be one of: %s", err.Param())
+	case "username":
+		return "Usernames must be 3-30 letters, digits or underscores, include a letter and not be reserved"
+	case "website":
+		return "Website must be an http or https URL"
 	default:
 		return fmt.Sprintf("Validation failed on '%s' tag", err.Tag())
 	}
@@ -80,3 +121,23 @@ func (v *Validator) getErrorMessage(err validator.FieldError) string {
 func (v *Validator) RegisterTagNameFunc(fn validator.TagNameFunc) {
 	v.validate.RegisterTagNameFunc(fn)
 }
+
+// validateUsername validates a field holding a username
+func validateUsername(fl validator.FieldLevel) bool {
+	return IsValidUsername(fl.Field().String())
+}
+
+// validateWebsite validates a field holding an absolute http or https URL
+func validateWebsite(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if strings.ContainsAny(value, " \t\r\n") {
+		return false
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
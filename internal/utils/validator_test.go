@@ -64,3 +64,40 @@ func TestValidator_Validate_Failure(t *testing.T) {
 	assert.Contains(t, errorMap, "Password")
 	assert.Contains(t, errorMap, "BirthDate")
 }
+
+func TestValidator_Username(t *testing.T) {
+	validator := NewValidator()
+
+	type TestStruct struct {
+		Username string `validate:"required,username"`
+	}
+
+	valid := []string{"ana", "Ana_Souza", "user_42", "abc123"}
+	for _, username := range valid {
+		assert.NoError(t, validator.Validate(TestStruct{Username: username}), username)
+	}
+
+	invalid := []string{"ab", "ana souza", "ana-souza", "ana.souza", "12345", "Admin", "me", "açaí",
+		"a_username_that_is_way_too_long_to_fit"}
+	for _, username := range invalid {
+		assert.Error(t, validator.Validate(TestStruct{Username: username}), username)
+	}
+}
+
+func TestValidator_Website(t *testing.T) {
+	validator := NewValidator()
+
+	type TestStruct struct {
+		Website string `validate:"omitempty,website"`
+	}
+
+	valid := []string{"", "https://example.com", "http://example.com/about?x=1"}
+	for _, website := range valid {
+		assert.NoError(t, validator.Validate(TestStruct{Website: website}), website)
+	}
+
+	invalid := []string{"example.com", "javascript:alert(1)", "ftp://example.com", "https://", "https://exa mple.com"}
+	for _, website := range invalid {
+		assert.Error(t, validator.Validate(TestStruct{Website: website}), website)
+	}
+}
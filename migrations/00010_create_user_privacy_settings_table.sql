@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE user_privacy_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    email_visibility VARCHAR(20) NOT NULL DEFAULT 'only_me', -- public, friends, only_me
+    birth_date_visibility VARCHAR(20) NOT NULL DEFAULT 'friends', -- public, friends, only_me
+    friend_list_visibility VARCHAR(20) NOT NULL DEFAULT 'public', -- public, friends, only_me
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE user_privacy_settings;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE users
+    ADD COLUMN username VARCHAR(30),
+    ADD COLUMN bio TEXT NOT NULL DEFAULT '',
+    ADD COLUMN location VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN website VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN pronouns VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Usernames are unique regardless of case; users created before usernames existed have none
+CREATE UNIQUE INDEX idx_users_username ON users(LOWER(username));
+
+CREATE TABLE username_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_username VARCHAR(30),
+    new_username VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_username_history_user_id ON username_history(user_id);
+CREATE INDEX idx_username_history_old_username ON username_history(LOWER(old_username));
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE username_history;
+
+ALTER TABLE users
+    DROP COLUMN username,
+    DROP COLUMN bio,
+    DROP COLUMN location,
+    DROP COLUMN website,
+    DROP COLUMN pronouns;
\ No newline at end of file
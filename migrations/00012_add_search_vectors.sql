@@ -0,0 +1,20 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- The 'simple' configuration skips stemming so prefix queries behave the same in every language
+ALTER TABLE users ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(username, ''))) STORED;
+
+CREATE INDEX idx_users_search_vector ON users USING GIN (search_vector);
+
+ALTER TABLE posts ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;
+
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_posts_search_vector;
+ALTER TABLE posts DROP COLUMN search_vector;
+
+DROP INDEX idx_users_search_vector;
+ALTER TABLE users DROP COLUMN search_vector;
\ No newline at end of file
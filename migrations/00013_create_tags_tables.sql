@@ -0,0 +1,35 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL, -- lowercased, without the leading '#'
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE post_tags (
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX idx_post_tags_tag_id ON post_tags(tag_id);
+
+-- Backfill tags for posts created before hashtags were extracted
+INSERT INTO tags (name)
+SELECT DISTINCT LOWER(m[1])
+FROM posts
+CROSS JOIN LATERAL regexp_matches(content, '(?:^|[^[:alnum:]_&])#([[:alnum:]_]{1,100})(?![[:alnum:]_])', 'g') AS m
+WHERE m[1] ~ '[[:alpha:]]'
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO post_tags (post_id, tag_id)
+SELECT DISTINCT p.id, t.id
+FROM posts p
+CROSS JOIN LATERAL regexp_matches(p.content, '(?:^|[^[:alnum:]_&])#([[:alnum:]_]{1,100})(?![[:alnum:]_])', 'g') AS m
+JOIN tags t ON t.name = LOWER(m[1])
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE post_tags;
+DROP TABLE tags;
\ No newline at end of file
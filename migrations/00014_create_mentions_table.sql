@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE mentions (
+    id SERIAL PRIMARY KEY,
+    resource_type VARCHAR(50) NOT NULL, -- 'posts', 'comments'
+    resource_id INTEGER NOT NULL,
+    mentioned_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(30) NOT NULL, -- as written in the content
+    start_offset INTEGER NOT NULL, -- in characters, pointing at the '@'
+    length INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (resource_type, resource_id, start_offset)
+);
+
+CREATE INDEX idx_mentions_mentioned_user_id ON mentions(mentioned_user_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE mentions;
\ No newline at end of file
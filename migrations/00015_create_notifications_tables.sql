@@ -0,0 +1,39 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- recipient
+    type VARCHAR(50) NOT NULL, -- like, comment, mention, friend_request, friend_accept
+    resource_type VARCHAR(50) NOT NULL, -- 'posts', 'photos', 'comments', 'friend_requests', etc.
+    resource_id INTEGER NOT NULL,
+    last_actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    actor_count INTEGER NOT NULL DEFAULT 1,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unread notifications about the same thing are aggregated into a single row
+CREATE UNIQUE INDEX idx_notifications_unread_group ON notifications(user_id, type, resource_type, resource_id) WHERE read_at IS NULL;
+CREATE INDEX idx_notifications_user_id_updated_at ON notifications(user_id, updated_at DESC);
+
+CREATE TABLE notification_actors (
+    notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (notification_id, actor_id)
+);
+
+CREATE TABLE notification_preferences (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type)
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE notification_preferences;
+DROP TABLE notification_actors;
+DROP TABLE notifications;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Replies always point at a top-level comment, so threads are one level deep
+ALTER TABLE comments ADD COLUMN parent_comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+-- A deleted comment that still has replies is kept as a tombstone
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_comments_parent_comment_id ON comments(parent_comment_id, created_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_comments_parent_comment_id;
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_comment_id;
\ No newline at end of file
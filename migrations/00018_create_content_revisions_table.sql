@@ -0,0 +1,22 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP;
+
+-- Each row keeps the content a post or comment had before an edit
+CREATE TABLE content_revisions (
+    id SERIAL PRIMARY KEY,
+    resource_type VARCHAR(50) NOT NULL, -- 'posts', 'comments'
+    resource_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    edited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_content_revisions_resource ON content_revisions(resource_type, resource_id, created_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE content_revisions;
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS edited_at;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE posts ADD COLUMN comment_permission VARCHAR(20) NOT NULL DEFAULT 'everyone'; -- everyone, friends, nobody
+
+-- Hidden comments are only shown to their author and the owner of the commented content
+ALTER TABLE comments ADD COLUMN hidden_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN pinned_at TIMESTAMP;
+
+-- At most one pinned comment per resource
+CREATE UNIQUE INDEX idx_comments_pinned ON comments(resource_type, resource_id) WHERE pinned_at IS NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_comments_pinned;
+ALTER TABLE comments DROP COLUMN IF EXISTS pinned_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS comment_permission;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Existing likes become "like" reactions; a user keeps one reaction per resource
+ALTER TABLE likes RENAME TO reactions;
+ALTER TABLE reactions ADD COLUMN type VARCHAR(20) NOT NULL DEFAULT 'like'; -- like, love, haha, wow, sad, angry
+ALTER TABLE reactions ALTER COLUMN type DROP DEFAULT;
+ALTER INDEX likes_pkey RENAME TO reactions_pkey;
+ALTER INDEX idx_likes_resource RENAME TO idx_reactions_resource;
+ALTER INDEX idx_likes_user_id RENAME TO idx_reactions_user_id;
+
+CREATE INDEX idx_reactions_resource_type ON reactions(resource_type, resource_id, type);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+-- Every reaction is kept as a plain like
+DROP INDEX IF EXISTS idx_reactions_resource_type;
+ALTER INDEX idx_reactions_user_id RENAME TO idx_likes_user_id;
+ALTER INDEX idx_reactions_resource RENAME TO idx_likes_resource;
+ALTER INDEX reactions_pkey RENAME TO likes_pkey;
+ALTER TABLE reactions DROP COLUMN type;
+ALTER TABLE reactions RENAME TO likes;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- A share is a post that points at the post it shares. There is no foreign key
+-- so that a share outlives the original and can show it as unavailable.
+ALTER TABLE posts ADD COLUMN shared_post_id INTEGER;
+
+CREATE INDEX idx_posts_shared_post_id ON posts(shared_post_id) WHERE shared_post_id IS NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_posts_shared_post_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS shared_post_id;
\ No newline at end of file
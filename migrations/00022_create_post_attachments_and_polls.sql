@@ -0,0 +1,50 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE post_attachments (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL, -- image, link
+    position INTEGER NOT NULL DEFAULT 0,
+    url VARCHAR(2048) NOT NULL, -- the uploaded image or the linked page
+    title VARCHAR(300) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url VARCHAR(2048) NOT NULL DEFAULT '', -- preview image of a link
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_attachments_post_id ON post_attachments(post_id, position);
+
+CREATE TABLE polls (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL UNIQUE REFERENCES posts(id) ON DELETE CASCADE,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    results_visibility VARCHAR(20) NOT NULL DEFAULT 'always', -- always, after_vote, after_close
+    closes_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE poll_options (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text VARCHAR(200) NOT NULL
+);
+
+CREATE INDEX idx_poll_options_poll_id ON poll_options(poll_id, position);
+
+CREATE TABLE poll_votes (
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    option_id INTEGER NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (option_id, user_id)
+);
+
+CREATE INDEX idx_poll_votes_poll_id_user_id ON poll_votes(poll_id, user_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
+DROP TABLE post_attachments;
\ No newline at end of file
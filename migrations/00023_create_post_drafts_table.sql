@@ -0,0 +1,33 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Drafts and scheduled posts are kept apart from posts until they are published,
+-- so no query over posts can show them early
+CREATE TABLE post_drafts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL DEFAULT '',
+    privacy VARCHAR(20) NOT NULL DEFAULT 'public',
+    link_url VARCHAR(2048) NOT NULL DEFAULT '',
+    image_urls TEXT[] NOT NULL DEFAULT '{}',
+    poll JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft', -- draft, scheduled
+    publish_at TIMESTAMP,
+    locked_until TIMESTAMP, -- set while a server publishes the draft
+    last_error TEXT NOT NULL DEFAULT '', -- why publishing the draft failed
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_drafts_user_id ON post_drafts(user_id, updated_at DESC);
+CREATE INDEX idx_post_drafts_due ON post_drafts(publish_at) WHERE status = 'scheduled';
+
+-- The draft a post was published from; a draft can only be published once
+ALTER TABLE posts ADD COLUMN draft_id INTEGER;
+
+CREATE UNIQUE INDEX idx_posts_draft_id ON posts(draft_id) WHERE draft_id IS NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_posts_draft_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS draft_id;
+DROP TABLE post_drafts;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE stories (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL, -- image, text
+    content TEXT NOT NULL DEFAULT '', -- the text of a text story or the caption of an image
+    media_url VARCHAR(500) NOT NULL DEFAULT '',
+    privacy VARCHAR(20) NOT NULL DEFAULT 'public',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stories_user_id_expires_at ON stories(user_id, expires_at);
+CREATE INDEX idx_stories_expires_at ON stories(expires_at);
+
+CREATE TABLE story_views (
+    story_id INTEGER NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    viewer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (story_id, viewer_id)
+);
+
+CREATE INDEX idx_story_views_viewer_id ON story_views(viewer_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE story_views;
+DROP TABLE stories;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Each user's feed is materialized as the IDs of the posts in it. New posts start as
+-- 'pending' until a background job fans them out to the author's and friends' timelines;
+-- posts of authors with too many friends are 'skipped' and read from posts directly.
+ALTER TABLE posts ADD COLUMN fanout_status VARCHAR(20) NOT NULL DEFAULT 'pending'; -- pending, done, skipped
+
+CREATE INDEX idx_posts_fanout_status ON posts(user_id, created_at) WHERE fanout_status <> 'done';
+
+CREATE TABLE timelines (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL, -- when the post was created
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX idx_timelines_user_id_created_at ON timelines(user_id, created_at DESC);
+CREATE INDEX idx_timelines_user_id_author_id ON timelines(user_id, author_id);
+CREATE INDEX idx_timelines_post_id ON timelines(post_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE timelines;
+DROP INDEX IF EXISTS idx_posts_fanout_status;
+ALTER TABLE posts DROP COLUMN IF EXISTS fanout_status;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- A muted user's posts are left out of the muter's feed; a snooze is a mute that expires
+CREATE TABLE feed_mutes (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP, -- set for snoozes
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, muted_user_id)
+);
+
+CREATE TABLE hidden_posts (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX idx_hidden_posts_post_id ON hidden_posts(post_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE hidden_posts;
+DROP TABLE feed_mutes;
\ No newline at end of file
@@ -0,0 +1,31 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE collections (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_collections_user_id_name ON collections(user_id, LOWER(name));
+
+-- A saved post or photo is in at most one collection; deleting a collection keeps its items saved
+CREATE TABLE saved_items (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL, -- 'posts', 'photos'
+    resource_id INTEGER NOT NULL,
+    collection_id INTEGER REFERENCES collections(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, resource_type, resource_id)
+);
+
+CREATE INDEX idx_saved_items_user_id_created_at ON saved_items(user_id, created_at DESC);
+CREATE INDEX idx_saved_items_collection_id ON saved_items(collection_id, created_at DESC);
+CREATE INDEX idx_saved_items_resource ON saved_items(resource_type, resource_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE saved_items;
+DROP TABLE collections;
\ No newline at end of file
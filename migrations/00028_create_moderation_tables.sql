@@ -0,0 +1,51 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'; -- user, moderator, admin
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP;
+
+-- Content hidden by a site moderator; its author cannot make it visible again
+ALTER TABLE posts ADD COLUMN moderated_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN moderated_at TIMESTAMP;
+
+CREATE TABLE reports (
+    id SERIAL PRIMARY KEY,
+    reporter_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL, -- 'posts', 'comments', 'photos', 'users'
+    resource_id INTEGER NOT NULL,
+    reason VARCHAR(30) NOT NULL, -- spam, harassment, hate_speech, violence, nudity, misinformation, other
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- open, actioned, dismissed
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user has at most one open report on the same content
+CREATE UNIQUE INDEX idx_reports_open_reporter ON reports(reporter_id, resource_type, resource_id) WHERE status = 'open';
+CREATE INDEX idx_reports_open_resource ON reports(resource_type, resource_id) WHERE status = 'open';
+
+-- Audit trail of every moderation decision
+CREATE TABLE moderation_actions (
+    id SERIAL PRIMARY KEY,
+    moderator_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(30) NOT NULL, -- hide_content, warn_user, suspend_user, dismiss
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INTEGER NOT NULL,
+    target_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE, -- the author of the content
+    note TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP, -- end of a suspension
+    reports_resolved INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_moderation_actions_created_at ON moderation_actions(created_at DESC);
+CREATE INDEX idx_moderation_actions_target_user_id ON moderation_actions(target_user_id, created_at DESC);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_until;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Content of a restricted user is visible only to themselves; cleared once the restriction ends
+ALTER TABLE users ADD COLUMN restricted_until TIMESTAMP;
+
+-- History of account suspensions and restrictions
+CREATE TABLE suspensions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL, -- suspension, restriction
+    reason VARCHAR(30) NOT NULL, -- spam, harassment, hate_speech, violence, nudity, misinformation, other
+    note TEXT NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    lifted_at TIMESTAMP, -- set when lifted early
+    lifted_by INTEGER REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_suspensions_user_id ON suspensions(user_id, created_at DESC);
+CREATE INDEX idx_users_suspended_until ON users(suspended_until) WHERE suspended_until IS NOT NULL;
+CREATE INDEX idx_users_restricted_until ON users(restricted_until) WHERE restricted_until IS NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS idx_users_restricted_until;
+DROP INDEX IF EXISTS idx_users_suspended_until;
+DROP TABLE suspensions;
+ALTER TABLE users DROP COLUMN IF EXISTS restricted_until;
\ No newline at end of file
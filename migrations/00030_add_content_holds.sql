@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Content held for review by the content filter. A held post is only visible to its author
+-- until a moderator releases it with the privacy it was posted with.
+ALTER TABLE posts ADD COLUMN held_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN held_privacy VARCHAR(20);
+ALTER TABLE comments ADD COLUMN held_at TIMESTAMP;
+
+-- Reports filed by the content filter have no reporter
+ALTER TABLE reports ALTER COLUMN reporter_id DROP NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM reports WHERE reporter_id IS NULL;
+ALTER TABLE reports ALTER COLUMN reporter_id SET NOT NULL;
+ALTER TABLE comments DROP COLUMN IF EXISTS held_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS held_privacy;
+ALTER TABLE posts DROP COLUMN IF EXISTS held_at;
\ No newline at end of file